@@ -24,6 +24,12 @@ type ScriptArg struct {
 	LenConstraint      *ArgLenConstraint
 	RequiresConstraint []string
 	ExcludesConstraint []string
+	// Validator names the script function set by the arg's @validate_<name>
+	// macro, if any. It's called with the parsed value before the script runs.
+	Validator *string
+	// validatePrompt checks an interactive answer against Validator. Set by
+	// the runner, which owns the interpreter the function lives in.
+	validatePrompt func(string) error
 	// first check the Type and HasDefaultValue, then get the value
 	DefaultString     *string
 	DefaultStringList *[]string
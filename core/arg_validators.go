@@ -0,0 +1,230 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/amterp/rad/rts/rl"
+	"github.com/samber/lo"
+)
+
+// applyValidatorMacros reads a script header's validator macros. Each
+// @validate_<arg> sets the Validator of every arg so named - the script's own
+// or any command's - and the @validate function, if set, is returned as the
+// validator for the args as a whole.
+//
+// A macro naming no declared arg is an error rather than a validator that
+// silently never runs.
+func applyValidatorMacros(macros map[string]string, args []*ScriptArg, commands []*ScriptCommand) *string {
+	byName := make(map[string][]*ScriptArg)
+	for _, arg := range args {
+		byName[arg.Name] = append(byName[arg.Name], arg)
+	}
+	var collect func(cmds []*ScriptCommand)
+	collect = func(cmds []*ScriptCommand) {
+		for _, cmd := range cmds {
+			for _, arg := range cmd.Args {
+				byName[arg.Name] = append(byName[arg.Name], arg)
+			}
+			collect(cmd.SubCmds)
+		}
+	}
+	collect(commands)
+
+	macroNames := make([]string, 0, len(macros))
+	for macro := range macros {
+		macroNames = append(macroNames, macro)
+	}
+	sort.Strings(macroNames)
+
+	for _, macro := range macroNames {
+		argName, ok := strings.CutPrefix(macro, MACRO_VALIDATE_ARG_PREFIX)
+		if !ok {
+			continue
+		}
+		named, ok := byName[argName]
+		if !ok {
+			RP.RadErrorExit(fmt.Sprintf("Macro '@%s' names an arg that isn't declared: '%s'\n", macro, argName))
+		}
+		fnName := macros[macro]
+		for _, arg := range named {
+			arg.Validator = &fnName
+		}
+	}
+
+	if fnName, ok := macros[MACRO_VALIDATE]; ok {
+		return &fnName
+	}
+	return nil
+}
+
+// argValidators calls validator functions, each in an interpreter over the
+// script that declared it. That's the running script's own interpreter, except
+// for a bundle's shared args: their validators live in the shared script, which
+// otherwise never runs.
+type argValidators struct {
+	interps map[*ScriptData]*Interpreter
+}
+
+func newArgValidators() *argValidators {
+	return &argValidators{interps: make(map[*ScriptData]*Interpreter)}
+}
+
+// seed makes interp the one validators declared in sd run in: the interpreter
+// about to run sd, so validators share its args rather than a copy.
+func (v *argValidators) seed(sd *ScriptData, interp *Interpreter) {
+	interp.defineFunctions(interp.astRoot())
+	v.interps[sd] = interp
+}
+
+// interpreterFor returns the interpreter validators declared in sd run in,
+// creating one with sd's functions defined and args set if there isn't one.
+// Nothing else of sd runs: validators see args and functions, not variables
+// the script body would go on to assign.
+func (v *argValidators) interpreterFor(sd *ScriptData, args []RadArg) *Interpreter {
+	if interp, ok := v.interps[sd]; ok {
+		return interp
+	}
+	interp := NewInterpreter(InterpreterInput{
+		Src:        sd.Src,
+		Tree:       sd.Tree,
+		ScriptName: sd.ScriptName,
+	})
+	interp.InitBuiltIns()
+	interp.InitArgs(args)
+	interp.defineFunctions(interp.astRoot())
+	v.interps[sd] = interp
+	return interp
+}
+
+// checkArgs runs the validators of args declared in sd, in declaration order,
+// and returns the first rejection as a usage error message. Args that are
+// unset run no validator - there's no value to check.
+func (v *argValidators) checkArgs(sd *ScriptData, args []RadArg) string {
+	if !lo.SomeBy(args, func(arg RadArg) bool { return arg.GetValidator() != nil }) {
+		return ""
+	}
+	interp := v.interpreterFor(sd, args)
+	for _, arg := range args {
+		fnName := arg.GetValidator()
+		if fnName == nil || !arg.IsDefined() {
+			continue
+		}
+		val, ok := interp.env.GetVar(arg.GetIdentifier())
+		if !ok || val.IsNull() {
+			continue
+		}
+		fn := interp.lookupValidator(*fnName, MACRO_VALIDATE_ARG_PREFIX+arg.GetIdentifier())
+		if msg, rejected := interp.callValidator(fn, NewPosArg(nil, val)); rejected {
+			return fmt.Sprintf("Invalid '%s' value: %s (%s)",
+				arg.GetExternalName(), ToPrintableQuoteStr(val, false), msg)
+		}
+	}
+	return ""
+}
+
+// checkAll runs sd's @validate function, if it has one, over args already set.
+func (v *argValidators) checkAll(sd *ScriptData, args []RadArg) string {
+	if sd.Validator == nil {
+		return ""
+	}
+	interp := v.interpreterFor(sd, args)
+	fn := interp.lookupValidator(*sd.Validator, MACRO_VALIDATE)
+	if msg, rejected := interp.callValidator(fn); rejected {
+		return "Invalid args: " + msg
+	}
+	return ""
+}
+
+// lookupValidator resolves the function a validator macro names, erroring the
+// same way a command's unknown callback does.
+func (i *Interpreter) lookupValidator(fnName, macro string) RadFn {
+	val, exist := i.env.GetVar(fnName)
+	if !exist {
+		i.emitErrorf(rl.ErrUnknownFunction, nil, "Cannot invoke unknown function '%s' for macro '@%s'", fnName, macro)
+	}
+	fn, ok := val.TryGetFn()
+	if !ok {
+		i.emitErrorf(rl.ErrTypeMismatch, nil, "Cannot invoke '%s' as a function for macro '@%s': it is a %s",
+			fnName, macro, val.Type().AsString())
+	}
+	return fn
+}
+
+// callValidator calls a validator function. Returning an error rejects, with
+// the error's message as the reason; any other result accepts. An error the
+// function *raises* rather than returns is a bug in the validator, and fails
+// the run like any other.
+func (i *Interpreter) callValidator(fn RadFn, args ...PosArg) (reason string, rejected bool) {
+	defer func() {
+		i.handlePanicRecovery(recover(), nil, fn.Name())
+	}()
+
+	out := fn.Execute(FuncInvocation{
+		i:         i,
+		args:      args,
+		namedArgs: make(map[string]namedArg),
+	})
+	if err, ok := out.TryGetError(); ok {
+		return err.Msg().Plain(), true
+	}
+	return "", false
+}
+
+// attachPromptValidators gives each of args with a validator a check -i can
+// run on an answer, so a value the validator would reject is re-asked inline
+// like any other invalid answer. sd is the script that declared args.
+//
+// Only scalar str, int and float answers are checked this way; the rest are
+// still validated, just after the prompts, when the script would run.
+func (v *argValidators) attachPromptValidators(sd *ScriptData, args []*ScriptArg) {
+	for _, arg := range args {
+		if arg.Validator == nil || isListScriptArg(arg) {
+			continue
+		}
+		toValue := promptValueConverter(arg.Type)
+		if toValue == nil {
+			continue
+		}
+		// Resolve now: a missing function should fail before any prompt
+		// draws, not from inside one.
+		interp := v.interpreterFor(sd, nil)
+		fn := interp.lookupValidator(*arg.Validator, MACRO_VALIDATE_ARG_PREFIX+arg.Name)
+		arg.validatePrompt = func(s string) error {
+			val, ok := toValue(s)
+			if !ok {
+				return nil
+			}
+			if msg, rejected := interp.callValidator(fn, NewPosArg(nil, val)); rejected {
+				return errors.New(msg)
+			}
+			return nil
+		}
+	}
+}
+
+// promptValueConverter turns an answer into the value the arg would parse to,
+// or returns nil for types -i doesn't validate inline. Answers reaching it have
+// passed the type's own check, so conversion failing just means "not ours to
+// judge".
+func promptValueConverter(t RadArgTypeT) func(string) (RadValue, bool) {
+	switch t {
+	case ArgStringT:
+		return func(s string) (RadValue, bool) { return newRadValueStr(s), true }
+	case ArgIntT:
+		return func(s string) (RadValue, bool) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			return newRadValueInt64(n), err == nil
+		}
+	case ArgFloatT:
+		return func(s string) (RadValue, bool) {
+			f, err := strconv.ParseFloat(s, 64)
+			return newRadValueFloat64(f), err == nil
+		}
+	default:
+		return nil
+	}
+}
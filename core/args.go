@@ -85,7 +85,8 @@ type RadArg interface {
 	SetValue(value string)
 	IsOptional() bool
 	IsNullable() bool
-	GetSpan() *rl.Span // nil if not a script arg
+	GetSpan() *rl.Span     // nil if not a script arg
+	GetValidator() *string // nil if not a script arg or it has no validator
 	Hidden(bool)
 	IsHidden() bool
 	Excludes(otherArg RadArg) bool
@@ -171,6 +172,13 @@ func (f *BaseRadArg) GetSpan() *rl.Span {
 	return &span
 }
 
+func (f *BaseRadArg) GetValidator() *string {
+	if f.scriptArg == nil {
+		return nil
+	}
+	return f.scriptArg.Validator
+}
+
 // argErrorCtx creates an ErrorCtx from the arg declaration's span for error reporting.
 func (f *BaseRadArg) argErrorCtx(msg string) ErrorCtx {
 	if f.scriptArg == nil {
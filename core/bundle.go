@@ -0,0 +1,185 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	com "github.com/amterp/rad/core/common"
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/rl"
+	"github.com/samber/lo"
+)
+
+// BundleManifest is the file that turns a directory of scripts into a bundle:
+// one tool whose commands are the scripts beside it.
+const BundleManifest = "bundle.toml"
+
+type bundleManifest struct {
+	// Name is what the tool is called in usage; defaults to the directory name.
+	Name        string `toml:"name"`
+	Description string `toml:"description"`
+	// Shared is a script, relative to the bundle, whose args block every
+	// command shares. It's not a command itself.
+	Shared string `toml:"shared"`
+	// Default is the command to run when none is named.
+	Default string `toml:"default"`
+}
+
+// isBundleDir reports whether path is a directory containing a bundle manifest.
+func isBundleDir(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	return com.IsRegularFile(filepath.Join(path, BundleManifest))
+}
+
+// LoadBundle reads a bundle directory as one script: the manifest names and
+// describes it, the shared script supplies its args, and every other script
+// in the directory becomes a command. Each command keeps its file's source and
+// runs as that script when invoked; a file that declares commands of its own
+// becomes a namespace over them.
+//
+// Like ExtractMetadata, a command file with errors is reported and exits -
+// a bundle only runs if all of it checks.
+func LoadBundle(dir string) (*ScriptData, error) {
+	manifestPath := filepath.Join(dir, BundleManifest)
+	var manifest bundleManifest
+	meta, err := toml.DecodeFile(manifestPath, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %v", manifestPath, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("Unknown key %q in %s", undecoded[0].String(), manifestPath)
+	}
+
+	name := manifest.Name
+	if name == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot resolve bundle path %s: %v", dir, err)
+		}
+		name = filepath.Base(abs)
+	}
+
+	parser, err := rts.NewRadParser()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create Rad tree sitter: %v", err)
+	}
+
+	// The shared script stands in as the bundle's own: its args are the
+	// tool's, and its source is what the inspection flags show when no
+	// command is named.
+	var root *ScriptData
+	var sharedPath string
+	if manifest.Shared != "" {
+		sharedPath = filepath.Join(dir, manifest.Shared)
+		if !com.IsRegularFile(sharedPath) {
+			return nil, fmt.Errorf("Shared script %q not found in bundle %s", manifest.Shared, dir)
+		}
+		root, err = loadBundleScript(sharedPath, parser, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(root.Commands) > 0 {
+			return nil, fmt.Errorf("Shared script %s can't declare commands; give them a file of their own", sharedPath)
+		}
+	} else {
+		root = &ScriptData{Tree: parser.Parse("")}
+	}
+	root.ScriptName = name
+	root.Description = nil
+	if manifest.Description != "" {
+		root.Description = &manifest.Description
+	}
+
+	var sharedDecls []rl.ArgDecl
+	if root.Ast != nil && root.Ast.Args != nil {
+		sharedDecls = root.Ast.Args.Decls
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read bundle %s: %v", dir, err)
+	}
+
+	byName := make(map[string]string)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !isBundleCommandFile(entry, path) || path == sharedPath {
+			continue
+		}
+
+		script, err := loadBundleScript(path, parser, sharedDecls)
+		if err != nil {
+			return nil, err
+		}
+
+		cmdName := scriptNameFromPath(path)
+		cmd := &ScriptCommand{
+			Name:         cmdName,
+			ExternalName: rts.ToExternalName(cmdName),
+			Description:  script.Description,
+			Args:         script.Args,
+			HasCallback:  len(script.Commands) == 0,
+			SubCmds:      script.Commands,
+			Script:       script,
+		}
+		for _, sub := range cmd.SubCmds {
+			sub.Parent = cmd
+		}
+
+		if other, ok := byName[cmd.ExternalName]; ok {
+			return nil, fmt.Errorf("Bundle %s has two scripts for command '%s': %s and %s",
+				dir, cmd.ExternalName, other, entry.Name())
+		}
+		byName[cmd.ExternalName] = entry.Name()
+		root.Commands = append(root.Commands, cmd)
+	}
+
+	if len(root.Commands) == 0 {
+		return nil, fmt.Errorf("Bundle %s has no scripts to run", dir)
+	}
+
+	if manifest.Default != "" {
+		cmd, ok := lo.Find(root.Commands, func(c *ScriptCommand) bool {
+			return c.ExternalName == rts.ToExternalName(manifest.Default)
+		})
+		if !ok {
+			return nil, fmt.Errorf("Default command '%s' in %s is not a script in the bundle", manifest.Default, manifestPath)
+		}
+		cmd.IsDefault = true
+	}
+
+	return root, nil
+}
+
+// isBundleCommandFile reports whether a directory entry is one of the bundle's
+// command scripts: a .rad file, or any file with a rad shebang. Hidden files
+// are skipped, as are subdirectories - a bundle is one level of files.
+func isBundleCommandFile(entry os.DirEntry, path string) bool {
+	if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || entry.Name() == BundleManifest {
+		return false
+	}
+	if !com.IsRegularFile(path) {
+		return false
+	}
+	return strings.HasSuffix(entry.Name(), ".rad") || isRadScript(path)
+}
+
+// loadBundleScript reads and validates one file of a bundle. sharedArgs are
+// the bundle's shared declarations, which the file may use as if its own.
+func loadBundleScript(path string, parser *rts.RadParser, sharedArgs []rl.ArgDecl) (*ScriptData, error) {
+	src, err := readSource(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read script: %v", err)
+	}
+
+	file := filepath.Base(path)
+	tree := parser.Parse(src)
+	ast, resolved := validateSyntaxOf(file, src, tree, parser, sharedArgs)
+	return newScriptData(file, src, tree, ast, resolved), nil
+}
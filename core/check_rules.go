@@ -0,0 +1,16 @@
+package core
+
+import (
+	"github.com/amterp/rad/rts/check"
+)
+
+// newCheckRuleLoader resolves the rule levels `rad check` applies to each
+// script, from the [check] section of the rad config and any .radcheck.toml
+// above the script. radls resolves them the same way.
+func newCheckRuleLoader() *check.RuleLoader {
+	var user map[string]string
+	if RConfig != nil && RConfig.Check != nil {
+		user = RConfig.Check.Rules
+	}
+	return check.NewRuleLoader(user, radConfigPath(), warnf)
+}
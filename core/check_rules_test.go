@@ -0,0 +1,32 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/amterp/rad/rts/check"
+	"github.com/amterp/rad/rts/rl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRuleLoader_ProjectOverridesUserConfig(t *testing.T) {
+	dir := t.TempDir()
+	project := "[rules]\nRAD40017 = \"error\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, check.ProjectRulesFile), []byte(project), 0o644))
+	script := filepath.Join(dir, "scripts", "deploy.rad")
+	require.NoError(t, os.MkdirAll(filepath.Dir(script), 0o755))
+
+	prevConfig, prevHome := RConfig, RadHomeInst
+	defer func() { RConfig, RadHomeInst = prevConfig, prevHome }()
+	RadHomeInst = NewRadHome(t.TempDir())
+	RConfig = DefaultRadConfig()
+	RConfig.Check.Rules = map[string]string{"RAD40017": "off", "30011": "warning"}
+
+	rules := newCheckRuleLoader().RulesFor(script)
+	assert.Equal(t, check.Rules{
+		rl.ErrConstantInterpolation: check.RuleError,
+		rl.ErrUnhandledFallibleCall: check.RuleWarning,
+	}, rules)
+}
@@ -20,6 +20,8 @@ const (
 	EmbCmdCheck   = "check"
 	EmbCmdFmt     = "fmt"
 	EmbCmdExplain = "explain"
+	EmbCmdGenRef  = "gen-ref"
+	EmbCmdTest    = "test"
 )
 
 type EmbeddedCmd struct {
@@ -45,8 +47,10 @@ func init() {
 		createEmbeddedCmd(EmbCmdDocs),
 		createEmbeddedCmd(EmbCmdCheck),
 		createEmbeddedCmd(EmbCmdFmt),
+		createEmbeddedCmd(EmbCmdTest),
 		createEmbeddedCmd(EmbCmdHome),
 		createEmbeddedCmd(EmbCmdGenId),
+		createEmbeddedCmd(EmbCmdGenRef),
 		createEmbeddedCmd(EmbCmdStash),
 		createEmbeddedCmd(EmbCmdExplain),
 	}
@@ -21,6 +21,9 @@ type ScriptCommand struct {
 	Parent           *ScriptCommand // nil at the top level
 	// IsDefault marks the command its level runs when none is named.
 	IsDefault bool
+	// Script is set when the command is a whole file of a bundle rather than a
+	// block: invoking it runs that script, and SubCmds are its own commands.
+	Script *ScriptData
 }
 
 // IsNamespace reports whether this command routes to sub-commands rather than
@@ -48,6 +51,22 @@ func (c *ScriptCommand) argChain() []*ScriptArg {
 	return args
 }
 
+// scriptFor returns the script that runs when this command is invoked, and the
+// command to dispatch within it - nil when the script itself is what was
+// invoked. A nil script means the command belongs to the script being run.
+func (c *ScriptCommand) scriptFor() (*ScriptData, *ScriptCommand) {
+	for cur := c; cur != nil; cur = cur.Parent {
+		if cur.Script == nil {
+			continue
+		}
+		if cur == c {
+			return cur.Script, nil
+		}
+		return cur.Script, c
+	}
+	return nil, c
+}
+
 func FromCmdBlock(cmdBlock *rl.CmdBlock, src string) (*ScriptCommand, error) {
 	commandName := cmdBlock.Name
 	externalName := rts.ToExternalName(commandName)
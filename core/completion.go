@@ -19,6 +19,7 @@ Add to your shell startup file (e.g. ~/.bashrc or ~/.zshrc):
   eval "$(rad completion bash)"                          # rad CLI
   eval "$(rad completion bash ~/bin/myscript)"           # one script
   eval "$(rad completion bash ~/.rad/bin/* ~/scripts/*)" # all scripts in dirs
+  eval "$(rad completion bash ~/tools/dev)"              # a bundle directory
   eval "$(rad completion zsh)"                           # zsh variant
 
 When scripts are specified, only script completions are generated.
@@ -30,8 +31,9 @@ Re-source your shell config after adding new scripts.`
 // handleCompletionCommand handles `rad completion <shell> [scripts...]`.
 // Generates shell completion scripts for the rad CLI and optionally for custom scripts.
 //
-// Note: This runs before the printer (RP) is initialized, so it uses direct
-// stderr/stdout writes and os.Exit rather than the RP/RExit abstractions.
+// Note: This runs before the runner's own setup, so it uses direct stderr/stdout
+// writes and os.Exit rather than the RP/RExit abstractions; RP is wired up only
+// because checking a bundle's scripts reports through it.
 // Arg parsing and help output are delegated to Ra for consistent formatting.
 func (r *RadRunner) handleCompletionCommand(args []string) error {
 	// Use os.Exit directly since the Rad exit handler (RExit) depends on RP,
@@ -39,6 +41,9 @@ func (r *RadRunner) handleCompletionCommand(args []string) error {
 	ra.SetExitFunc(os.Exit)
 	defer ra.SetExitFunc(RExit.Exit)
 
+	// Loading a bundle checks its scripts, which reports through the printer.
+	RP = NewPrinter(r, false, false, false, false)
+
 	cmd := ra.NewCmd("rad completion")
 	cmd.SetDescription(completionLongDescription)
 	cmd.SetHelpEnabled(true)
@@ -53,7 +58,7 @@ func (r *RadRunner) handleCompletionCommand(args []string) error {
 		SetOptional(true).
 		SetVariadic(true).
 		SetPositionalOnly(true).
-		SetUsage("Paths to Rad scripts to generate completions for.").
+		SetUsage("Paths to Rad scripts or bundle directories to generate completions for.").
 		Register(cmd)
 
 	cmd.ParseOrExit(args) // handles -h/--help, validation errors, etc.
@@ -113,18 +118,26 @@ func generateScriptCompletion(
 	if err != nil {
 		return fmt.Errorf("cannot stat: %w", err)
 	}
+	// Derive command name from filename (what the user types). A bundle goes
+	// by its manifest name, so it has to be loaded to know what to complete.
+	var cmdName string
 	if info.IsDir() {
-		return fmt.Errorf("is a directory")
-	}
-
-	// Silently skip non-Rad files. This is expected when users pass glob
-	// patterns (e.g., ~/bin/*) that include a mix of Rad and non-Rad files.
-	if !isRadScript(absPath) {
-		return nil
+		if !isBundleDir(absPath) {
+			return fmt.Errorf("is a directory")
+		}
+		bundle, err := LoadBundle(absPath)
+		if err != nil {
+			return err
+		}
+		cmdName = bundle.ScriptName
+	} else {
+		// Silently skip non-Rad files. This is expected when users pass glob
+		// patterns (e.g., ~/bin/*) that include a mix of Rad and non-Rad files.
+		if !isRadScript(absPath) {
+			return nil
+		}
+		cmdName = scriptNameFromPath(absPath)
 	}
-
-	// Derive command name from filename (what the user types)
-	cmdName := scriptNameFromPath(absPath)
 	if cmdName == "" {
 		return fmt.Errorf("cannot derive command name from path")
 	}
@@ -205,6 +218,7 @@ func (r *RadRunner) registerEmbeddedCommandsForCompletion() {
 	// Register the Go-implemented commands (not embedded scripts)
 	goCmds := []struct{ name, desc string }{
 		{"completion", completionDescription},
+		{"debug", debugDescription},
 		{"repl", replDescription},
 	}
 	for _, c := range goCmds {
@@ -5,10 +5,13 @@ import (
 	"path/filepath"
 
 	"github.com/BurntSushi/toml"
+	"github.com/amterp/rad/rts/check"
 )
 
 type RadConfig struct {
 	InvocationLogging *InvocationLoggingConfig `toml:"invocation_logging"`
+	Repl              *ReplConfig              `toml:"repl"`
+	Check             *CheckConfig             `toml:"check"`
 }
 
 type InvocationLoggingConfig struct {
@@ -18,16 +21,25 @@ type InvocationLoggingConfig struct {
 	KeepRolledLogs int  `toml:"keep_rolled_logs"`
 }
 
+type ReplConfig struct {
+	HistorySize int `toml:"history_size"` // 0 keeps history to the session
+}
+
+// CheckConfig is the [check] section, shared with radls (see check.RuleLoader).
+type CheckConfig = check.RulesConfig
+
 func DefaultRadConfig() *RadConfig {
 	return &RadConfig{
 		InvocationLogging: defaultInvocationLoggingConfig(),
+		Repl:              defaultReplConfig(),
+		Check:             &CheckConfig{},
 	}
 }
 
 // LoadRadConfig loads the invocation logging configuration from ~/.rad/config.toml (configurable)
 // Returns defaults if file doesn't exist or on parse errors (with warnings)
 func LoadRadConfig() *RadConfig {
-	configPath := filepath.Join(RadHomeInst.HomeDir, "config.toml")
+	configPath := radConfigPath()
 
 	// Start from default, so when we load, unspecified fields remain defaults (instead of nil)
 	config := DefaultRadConfig()
@@ -61,9 +73,19 @@ func LoadRadConfig() *RadConfig {
 		config.InvocationLogging.KeepRolledLogs = invocationLoggingDefaults.KeepRolledLogs
 	}
 
+	if config.Repl.HistorySize < 0 {
+		warnf(configPath, "Invalid config: history_size must be >= 0, got %d. Using default: %d\n",
+			config.Repl.HistorySize, defaultReplConfig().HistorySize)
+		config.Repl.HistorySize = defaultReplConfig().HistorySize
+	}
+
 	return config
 }
 
+func radConfigPath() string {
+	return filepath.Join(RadHomeInst.HomeDir, "config.toml")
+}
+
 func defaultInvocationLoggingConfig() *InvocationLoggingConfig {
 	return &InvocationLoggingConfig{
 		Enabled:        true,  // on by default; powers 'rad check --from-logs'
@@ -79,3 +101,9 @@ func warnf(configPath string, format string, args ...interface{}) {
 	RP.RadStderrf("Warning! "+format, args...)
 	RP.RadStderrf("Please fix the invalid config file: %s\n", configPath)
 }
+
+func defaultReplConfig() *ReplConfig {
+	return &ReplConfig{
+		HistorySize: 1000,
+	}
+}
@@ -13,4 +13,7 @@ const (
 	MACRO_STASH_ID              = "stash_id"
 	MACRO_ENABLE_GLOBAL_OPTIONS = "enable_global_options"
 	MACRO_ENABLE_ARGS_BLOCK     = "enable_args_block"
+	MACRO_ENABLE_OUTPUT_FLAG    = "enable_output_flag"
+	MACRO_VALIDATE              = "validate"
+	MACRO_VALIDATE_ARG_PREFIX   = "validate_"
 )
@@ -0,0 +1,547 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/amterp/ra"
+	com "github.com/amterp/rad/core/common"
+)
+
+// debugDescription describes the `rad debug` command in usage/completion output.
+const debugDescription = "Serves the Debug Adapter Protocol, for debugging scripts from an editor."
+
+// dapThreadId is the only thread a Rad script has.
+const dapThreadId = 1
+
+// handleDebugCommand handles `rad debug --dap`, serving the Debug Adapter
+// Protocol on stdin/stdout. The editor launches the script through the
+// protocol rather than on this command line, so everything the script prints
+// reaches the editor as output events - stdout belongs to the protocol.
+func (r *RadRunner) handleDebugCommand(args []string) error {
+	RP = NewPrinter(r, false, false, false, false)
+
+	cmd := ra.NewCmd("rad debug")
+	cmd.SetDescription(debugDescription)
+	cmd.SetHelpEnabled(true)
+
+	dapPtr, _ := ra.NewBool("dap").
+		SetUsage("Speak the Debug Adapter Protocol on stdin and stdout. Editors start rad this way.").
+		SetFlagOnly(true).
+		Register(cmd)
+
+	cmd.ParseOrExit(args)
+
+	if !*dapPtr {
+		RP.ErrorExit("rad debug only speaks the Debug Adapter Protocol for now; run it as 'rad debug --dap'.")
+	}
+
+	adapter := newDapAdapter(os.Stdin, os.Stdout)
+	return adapter.serve()
+}
+
+type dapRequest struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+type dapResponse struct {
+	Seq        int    `json:"seq"`
+	Type       string `json:"type"`
+	RequestSeq int    `json:"request_seq"`
+	Success    bool   `json:"success"`
+	Command    string `json:"command"`
+	Message    string `json:"message,omitempty"`
+	Body       any    `json:"body,omitempty"`
+}
+
+type dapEvent struct {
+	Seq   int    `json:"seq"`
+	Type  string `json:"type"`
+	Event string `json:"event"`
+	Body  any    `json:"body,omitempty"`
+}
+
+type dapLaunchArgs struct {
+	Program     string   `json:"program"`
+	Args        []string `json:"args"`
+	Cwd         string   `json:"cwd"`
+	StopOnEntry bool     `json:"stopOnEntry"`
+}
+
+type dapSource struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+type dapVariable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	Type               string `json:"type,omitempty"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+// dapAdapter is the DAP frontend to a Debugger. Requests are read on the
+// goroutine that calls serve; the script runs on a goroutine of its own, and
+// while it is paused, anything that touches its state is handed over to run
+// there, so the interpreter is only ever used by one goroutine at a time.
+type dapAdapter struct {
+	in *bufio.Reader
+
+	mu  sync.Mutex // guards out and seq; output and stops are sent from the script's goroutine
+	out *bufio.Writer
+	seq int
+
+	debugger *Debugger
+	launch   *dapLaunchArgs
+	started  bool
+	exitOnce sync.Once
+
+	paused atomic.Bool
+	work   chan func(*dapStop)
+	resume chan DebugStep
+}
+
+// dapStop is a pause as the adapter sees it: the stop, plus the variable
+// references handed out during it, which lapse when the script resumes.
+type dapStop struct {
+	stop *DebugStop
+	refs [][]DebugVar
+}
+
+func newDapAdapter(in io.Reader, out io.Writer) *dapAdapter {
+	a := &dapAdapter{
+		in:     bufio.NewReader(in),
+		out:    bufio.NewWriter(out),
+		work:   make(chan func(*dapStop)),
+		resume: make(chan DebugStep),
+	}
+	a.debugger = NewDebugger(a)
+	return a
+}
+
+// serve answers requests until the client disconnects.
+func (a *dapAdapter) serve() error {
+	for {
+		req, err := a.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		body, then, err := a.handle(req)
+		a.respond(req, body, err)
+		if then != nil && err == nil {
+			then()
+		}
+
+		switch req.Command {
+		case "disconnect", "terminate":
+			return nil
+		}
+	}
+}
+
+// handle answers a request. The returned func, if any, runs once the
+// response is on the wire: anything that can make the script send events -
+// resuming it, above all - has to wait until then, or a breakpoint hit
+// straight away could report `stopped` before the client has seen its
+// `continue` answered, and it would mark the thread running while it's
+// actually paused.
+func (a *dapAdapter) handle(req dapRequest) (any, func(), error) {
+	switch req.Command {
+	case "initialize":
+		return map[string]any{
+			"supportsConfigurationDoneRequest": true,
+			"supportsEvaluateForHovers":        true,
+			"supportsTerminateRequest":         true,
+		}, func() { a.sendEvent("initialized", nil) }, nil
+	case "launch":
+		return nil, nil, a.handleLaunch(req.Arguments)
+	case "attach":
+		return nil, nil, errors.New("Rad can't attach to a running script; use a launch configuration")
+	case "setBreakpoints":
+		body, err := a.handleSetBreakpoints(req.Arguments)
+		return body, nil, err
+	case "setExceptionBreakpoints":
+		return map[string]any{"breakpoints": []any{}}, nil, nil
+	case "configurationDone":
+		start, err := a.prepareStart()
+		return nil, start, err
+	case "threads":
+		return map[string]any{"threads": []any{map[string]any{"id": dapThreadId, "name": "main"}}}, nil, nil
+	case "stackTrace":
+		body, err := a.whilePaused(a.stackTrace)
+		return body, nil, err
+	case "scopes":
+		body, err := a.withArgs(req.Arguments, a.scopes)
+		return body, nil, err
+	case "variables":
+		body, err := a.withArgs(req.Arguments, a.variables)
+		return body, nil, err
+	case "evaluate":
+		body, err := a.withArgs(req.Arguments, a.evaluate)
+		return body, nil, err
+	case "continue":
+		var then func()
+		if a.paused.Load() {
+			then = func() { a.resumeWith(DebugContinue) }
+		}
+		return map[string]any{"allThreadsContinued": true}, then, nil
+	case "next":
+		then, err := a.step(DebugStepOver)
+		return nil, then, err
+	case "stepIn":
+		then, err := a.step(DebugStepIn)
+		return nil, then, err
+	case "stepOut":
+		then, err := a.step(DebugStepOut)
+		return nil, then, err
+	case "pause":
+		a.debugger.RequestPause()
+		return nil, nil, nil
+	case "disconnect", "terminate":
+		return nil, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("Unsupported request: %s", req.Command)
+	}
+}
+
+func (a *dapAdapter) handleLaunch(raw json.RawMessage) error {
+	var args dapLaunchArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return err
+	}
+	if args.Program == "" {
+		return errors.New("The launch configuration needs a 'program': the script to debug")
+	}
+	if args.Cwd != "" && !filepath.IsAbs(args.Program) {
+		args.Program = filepath.Join(args.Cwd, args.Program)
+	}
+	if !com.IsRegularFile(args.Program) {
+		return fmt.Errorf("Cannot find script %s", args.Program)
+	}
+	if args.StopOnEntry {
+		a.debugger.StopOnEntry()
+	}
+	a.launch = &args
+	return nil
+}
+
+func (a *dapAdapter) handleSetBreakpoints(raw json.RawMessage) (any, error) {
+	var args struct {
+		Source      dapSource `json:"source"`
+		Breakpoints []struct {
+			Line int `json:"line"`
+		} `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+
+	lines := make([]int, 0, len(args.Breakpoints))
+	for _, bp := range args.Breakpoints {
+		lines = append(lines, bp.Line)
+	}
+	verified := a.debugger.SetBreakpoints(args.Source.Path, lines)
+
+	breakpoints := make([]map[string]any, 0, len(lines))
+	for idx, line := range lines {
+		bp := map[string]any{"verified": verified[idx], "line": line}
+		if !verified[idx] {
+			bp["message"] = "No statement starts on this line"
+		}
+		breakpoints = append(breakpoints, bp)
+	}
+	return map[string]any{"breakpoints": breakpoints}, nil
+}
+
+// prepareStart validates the launch and returns the func that runs the
+// script, the way `rad <program> <args>` would. Its output becomes output
+// events, and its exit ends the debug session. The script only starts after
+// configurationDone is answered, so a stop on entry can't overtake it.
+func (a *dapAdapter) prepareStart() (func(), error) {
+	if a.launch == nil {
+		return nil, errors.New("Nothing to run: the client sent no launch request")
+	}
+	if a.started {
+		return nil, nil
+	}
+	a.started = true
+
+	launch := *a.launch
+	if launch.Cwd != "" {
+		if err := os.Chdir(launch.Cwd); err != nil {
+			return nil, fmt.Errorf("Cannot change to %s: %v", launch.Cwd, err)
+		}
+	}
+
+	return func() { go a.run(launch) }, nil
+}
+
+// run executes the launched script on the calling goroutine.
+func (a *dapAdapter) run(launch dapLaunchArgs) {
+	RIo = RadIo{
+		StdIn:  NewBufferReader(&bytes.Buffer{}),
+		StdOut: &dapOutput{a: a, category: "stdout"},
+		StdErr: &dapOutput{a: a, category: "stderr"},
+	}
+	// The script's exit is the end of this goroutine, not of the process:
+	// the editor still has to be told, and then disconnects.
+	RExit = NewExitHandler(func(code int) {
+		a.exited(code)
+		runtime.Goexit()
+	})
+	ra.SetStdoutWriter(RIo.StdOut)
+	ra.SetStderrWriter(RIo.StdErr)
+	ra.SetExitFunc(RExit.Exit)
+	RDebugger = a.debugger
+
+	os.Args = append([]string{os.Args[0], launch.Program}, launch.Args...)
+	if err := (&RadRunner{}).Run(); err != nil {
+		fmt.Fprintln(RIo.StdErr, err.Error())
+		a.exited(1)
+		return
+	}
+	a.exited(0)
+}
+
+func (a *dapAdapter) exited(code int) {
+	a.exitOnce.Do(func() {
+		a.sendEvent("exited", map[string]any{"exitCode": code})
+		a.sendEvent("terminated", nil)
+	})
+}
+
+// Stopped parks the script's goroutine until the client resumes it, meanwhile
+// running the requests that need it paused.
+func (a *dapAdapter) Stopped(stop *DebugStop) DebugStep {
+	state := &dapStop{stop: stop}
+	a.paused.Store(true)
+	a.sendEvent("stopped", map[string]any{
+		"reason":            stop.Reason,
+		"threadId":          dapThreadId,
+		"allThreadsStopped": true,
+	})
+	for {
+		select {
+		case fn := <-a.work:
+			fn(state)
+		case step := <-a.resume:
+			return step
+		}
+	}
+}
+
+func (a *dapAdapter) step(step DebugStep) (func(), error) {
+	if !a.paused.Load() {
+		return nil, errors.New("The script isn't paused")
+	}
+	return func() { a.resumeWith(step) }, nil
+}
+
+func (a *dapAdapter) resumeWith(step DebugStep) {
+	a.paused.Store(false)
+	a.resume <- step
+}
+
+// whilePaused runs fn on the paused script's goroutine and waits for it.
+func (a *dapAdapter) whilePaused(fn func(*dapStop) (any, error)) (any, error) {
+	if !a.paused.Load() {
+		return nil, errors.New("The script isn't paused")
+	}
+	type result struct {
+		body any
+		err  error
+	}
+	done := make(chan result, 1)
+	a.work <- func(s *dapStop) {
+		body, err := fn(s)
+		done <- result{body, err}
+	}
+	r := <-done
+	return r.body, r.err
+}
+
+// withArgs decodes a request's arguments and handles it while paused.
+func (a *dapAdapter) withArgs(raw json.RawMessage, fn func(*dapStop, map[string]json.RawMessage) (any, error)) (any, error) {
+	var args map[string]json.RawMessage
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+	}
+	return a.whilePaused(func(s *dapStop) (any, error) {
+		return fn(s, args)
+	})
+}
+
+func (a *dapAdapter) stackTrace(s *dapStop) (any, error) {
+	frames := make([]map[string]any, 0, len(s.stop.Frames))
+	for idx, frame := range s.stop.Frames {
+		path := absPath(frame.Span.File)
+		frames = append(frames, map[string]any{
+			"id":     idx + 1,
+			"name":   frame.Name,
+			"source": dapSource{Name: filepath.Base(path), Path: path},
+			"line":   frame.Span.StartLine(),
+			"column": frame.Span.StartColumn(),
+		})
+	}
+	return map[string]any{"stackFrames": frames, "totalFrames": len(frames)}, nil
+}
+
+func (a *dapAdapter) scopes(s *dapStop, args map[string]json.RawMessage) (any, error) {
+	frame, err := s.frame(args)
+	if err != nil {
+		return nil, err
+	}
+	scopes := make([]map[string]any, 0, 2)
+	for _, scope := range s.stop.Frames[frame].Scopes() {
+		scopes = append(scopes, map[string]any{
+			"name":               scope.Name,
+			"variablesReference": s.ref(scope.Vars),
+			"expensive":          false,
+		})
+	}
+	return map[string]any{"scopes": scopes}, nil
+}
+
+func (a *dapAdapter) variables(s *dapStop, args map[string]json.RawMessage) (any, error) {
+	var ref int
+	if err := json.Unmarshal(args["variablesReference"], &ref); err != nil {
+		return nil, err
+	}
+	if ref < 1 || ref > len(s.refs) {
+		return nil, fmt.Errorf("Unknown variables reference %d", ref)
+	}
+	vars := make([]dapVariable, 0, len(s.refs[ref-1]))
+	for _, v := range s.refs[ref-1] {
+		vars = append(vars, s.variable(v.Name, v.Value))
+	}
+	return map[string]any{"variables": vars}, nil
+}
+
+func (a *dapAdapter) evaluate(s *dapStop, args map[string]json.RawMessage) (any, error) {
+	var expr string
+	if err := json.Unmarshal(args["expression"], &expr); err != nil {
+		return nil, err
+	}
+	frame, err := s.frame(args)
+	if err != nil {
+		return nil, err
+	}
+	val, err := s.stop.Evaluate(frame, expr)
+	if err != nil {
+		return nil, err
+	}
+	if val == VOID_SENTINEL {
+		return map[string]any{"result": "", "variablesReference": 0}, nil
+	}
+	v := s.variable(expr, val)
+	return map[string]any{"result": v.Value, "type": v.Type, "variablesReference": v.VariablesReference}, nil
+}
+
+// frame is the frame a request names, innermost when it names none.
+func (s *dapStop) frame(args map[string]json.RawMessage) (int, error) {
+	raw, ok := args["frameId"]
+	if !ok {
+		return 0, nil
+	}
+	var id int
+	if err := json.Unmarshal(raw, &id); err != nil {
+		return 0, err
+	}
+	if id < 1 || id > len(s.stop.Frames) {
+		return 0, fmt.Errorf("Unknown frame %d", id)
+	}
+	return id - 1, nil
+}
+
+func (s *dapStop) ref(vars []DebugVar) int {
+	s.refs = append(s.refs, vars)
+	return len(s.refs)
+}
+
+func (s *dapStop) variable(name string, val RadValue) dapVariable {
+	v := dapVariable{Name: name, Value: ToPrintable(val), Type: TypeAsString(val)}
+	if children := DebugChildren(val); len(children) > 0 {
+		v.VariablesReference = s.ref(children)
+	}
+	return v
+}
+
+// dapOutput is a script's stdout or stderr, forwarded as output events.
+type dapOutput struct {
+	a        *dapAdapter
+	category string
+}
+
+func (o *dapOutput) Write(p []byte) (int, error) {
+	o.a.sendEvent("output", map[string]any{"category": o.category, "output": string(p)})
+	return len(p), nil
+}
+
+func (a *dapAdapter) read() (dapRequest, error) {
+	var req dapRequest
+	header, err := textproto.NewReader(a.in).ReadMIMEHeader()
+	if err != nil {
+		return req, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return req, fmt.Errorf("Invalid Content-Length header: %v", err)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(a.in, content); err != nil {
+		return req, err
+	}
+	err = json.Unmarshal(content, &req)
+	return req, err
+}
+
+func (a *dapAdapter) respond(req dapRequest, body any, err error) {
+	resp := dapResponse{Type: "response", RequestSeq: req.Seq, Success: err == nil, Command: req.Command, Body: body}
+	if err != nil {
+		resp.Message = err.Error()
+	}
+	a.send(func(seq int) any {
+		resp.Seq = seq
+		return resp
+	})
+}
+
+func (a *dapAdapter) sendEvent(event string, body any) {
+	a.send(func(seq int) any {
+		return dapEvent{Seq: seq, Type: "event", Event: event, Body: body}
+	})
+}
+
+func (a *dapAdapter) send(msg func(seq int) any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.seq++
+	content, err := json.Marshal(msg(a.seq))
+	if err != nil {
+		RP.RadDebugf("Cannot encode DAP message: %v", err)
+		return
+	}
+	fmt.Fprintf(a.out, "Content-Length: %d\r\n\r\n", len(content))
+	a.out.Write(content)
+	a.out.Flush()
+}
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dapClient is the editor's side of a DAP session, over in-memory pipes.
+type dapClient struct {
+	t    *testing.T
+	w    io.Writer
+	seq  int
+	msgs chan map[string]any
+}
+
+func newDapClient(t *testing.T, r io.Reader, w io.Writer) *dapClient {
+	c := &dapClient{t: t, w: w, msgs: make(chan map[string]any, 64)}
+	go func() {
+		defer close(c.msgs)
+		in := bufio.NewReader(r)
+		for {
+			header, err := textproto.NewReader(in).ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+			length, err := strconv.Atoi(header.Get("Content-Length"))
+			if err != nil {
+				return
+			}
+			content := make([]byte, length)
+			if _, err := io.ReadFull(in, content); err != nil {
+				return
+			}
+			var msg map[string]any
+			if err := json.Unmarshal(content, &msg); err != nil {
+				return
+			}
+			c.msgs <- msg
+		}
+	}()
+	return c
+}
+
+func (c *dapClient) send(command string, args any) {
+	c.t.Helper()
+	c.seq++
+	content, err := json.Marshal(map[string]any{"seq": c.seq, "type": "request", "command": command, "arguments": args})
+	require.NoError(c.t, err)
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(content), content)
+	require.NoError(c.t, err)
+}
+
+func (c *dapClient) next() map[string]any {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.msgs:
+		require.True(c.t, ok, "the adapter closed the stream")
+		return msg
+	case <-time.After(10 * time.Second):
+		c.t.Fatal("timed out waiting for the adapter")
+		return nil
+	}
+}
+
+// response expects the very next message to be command's successful
+// response - not an event that overtook it - and returns its body.
+func (c *dapClient) response(command string) map[string]any {
+	c.t.Helper()
+	msg := c.next()
+	require.Equal(c.t, "response", msg["type"], "expected the %s response, got %v", command, msg)
+	require.Equal(c.t, command, msg["command"])
+	require.Equal(c.t, true, msg["success"], "%s failed: %v", command, msg["message"])
+	body, _ := msg["body"].(map[string]any)
+	return body
+}
+
+func (c *dapClient) event(event string) map[string]any {
+	c.t.Helper()
+	msg := c.next()
+	require.Equal(c.t, "event", msg["type"], "expected the %s event, got %v", event, msg)
+	require.Equal(c.t, event, msg["event"])
+	body, _ := msg["body"].(map[string]any)
+	return body
+}
+
+func TestDapSession(t *testing.T) {
+	program := filepath.Join(t.TempDir(), "main.rad")
+	require.NoError(t, os.WriteFile(program, []byte("x = 1\ny = x + 1\nprint(y)\n"), 0o644))
+
+	home := t.TempDir()
+	setGlobals(RunnerInput{
+		RIo:     &RadIo{StdIn: NewBufferReader(&bytes.Buffer{}), StdOut: io.Discard, StdErr: io.Discard},
+		RadHome: &home,
+	})
+	t.Cleanup(ResetGlobals)
+	RP = NewPrinter(nil, false, false, false, false)
+	savedArgs := os.Args
+	t.Cleanup(func() { os.Args = savedArgs })
+
+	toAdapter, clientOut := io.Pipe()
+	clientIn, fromAdapter := io.Pipe()
+	adapter := newDapAdapter(toAdapter, fromAdapter)
+	served := make(chan error, 1)
+	go func() {
+		served <- adapter.serve()
+		fromAdapter.Close()
+	}()
+	c := newDapClient(t, clientIn, clientOut)
+
+	c.send("initialize", map[string]any{"adapterID": "rad"})
+	assert.Equal(t, true, c.response("initialize")["supportsConfigurationDoneRequest"])
+	c.event("initialized")
+
+	c.send("launch", map[string]any{"program": program})
+	c.response("launch")
+
+	c.send("setBreakpoints", map[string]any{
+		"source":      map[string]any{"path": program},
+		"breakpoints": []any{map[string]any{"line": 2}, map[string]any{"line": 4}},
+	})
+	bps := c.response("setBreakpoints")["breakpoints"].([]any)
+	require.Len(t, bps, 2)
+	assert.Equal(t, true, bps[0].(map[string]any)["verified"])
+	assert.Equal(t, false, bps[1].(map[string]any)["verified"])
+
+	c.send("configurationDone", nil)
+	c.response("configurationDone")
+	assert.Equal(t, "breakpoint", c.event("stopped")["reason"])
+
+	c.send("stackTrace", map[string]any{"threadId": dapThreadId})
+	frames := c.response("stackTrace")["stackFrames"].([]any)
+	require.Len(t, frames, 1)
+	top := frames[0].(map[string]any)
+	assert.Equal(t, debugMainFrame, top["name"])
+	assert.Equal(t, float64(2), top["line"])
+
+	c.send("scopes", map[string]any{"frameId": 1})
+	scopes := c.response("scopes")["scopes"].([]any)
+	require.Len(t, scopes, 1)
+	globals := scopes[0].(map[string]any)
+	assert.Equal(t, "Globals", globals["name"])
+
+	c.send("variables", map[string]any{"variablesReference": globals["variablesReference"]})
+	vars := map[string]string{}
+	for _, v := range c.response("variables")["variables"].([]any) {
+		v := v.(map[string]any)
+		vars[v["name"].(string)] = v["value"].(string)
+	}
+	assert.Equal(t, "1", vars["x"])
+	assert.NotContains(t, vars, "y", "the paused line hasn't run yet")
+
+	c.send("evaluate", map[string]any{"expression": "x + 41", "frameId": 1})
+	assert.Equal(t, "42", c.response("evaluate")["result"])
+
+	c.send("continue", map[string]any{"threadId": dapThreadId})
+	c.response("continue")
+
+	var output string
+	for {
+		msg := c.next()
+		require.Equal(t, "event", msg["type"], "unexpected message after continue: %v", msg)
+		body, _ := msg["body"].(map[string]any)
+		if msg["event"] == "output" {
+			output += body["output"].(string)
+			continue
+		}
+		require.Equal(t, "exited", msg["event"])
+		assert.Equal(t, float64(0), body["exitCode"])
+		break
+	}
+	assert.Equal(t, "2\n", output)
+	c.event("terminated")
+
+	c.send("disconnect", nil)
+	c.response("disconnect")
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the adapter didn't stop serving after disconnect")
+	}
+}
+
+func TestDapRequestsNeedAPausedScript(t *testing.T) {
+	toAdapter, clientOut := io.Pipe()
+	clientIn, fromAdapter := io.Pipe()
+	adapter := newDapAdapter(toAdapter, fromAdapter)
+	go func() {
+		adapter.serve()
+		fromAdapter.Close()
+	}()
+	c := newDapClient(t, clientIn, clientOut)
+	defer clientOut.Close()
+
+	for _, command := range []string{"next", "stackTrace"} {
+		c.send(command, map[string]any{"threadId": dapThreadId})
+		msg := c.next()
+		assert.Equal(t, "response", msg["type"])
+		assert.Equal(t, false, msg["success"], command)
+		assert.Equal(t, "The script isn't paused", msg["message"])
+	}
+}
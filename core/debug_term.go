@@ -0,0 +1,114 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+// terminalDebugger is the frontend behind breakpoint() and --break: each stop
+// is a REPL session inside the paused script, for when there's no editor to
+// attach one from - a script run over SSH, say.
+type terminalDebugger struct {
+	session  *ReplSession
+	detached bool // input ran out; the script runs on without stopping
+}
+
+func (t *terminalDebugger) Stopped(stop *DebugStop) DebugStep {
+	if t.detached {
+		return DebugContinue
+	}
+	if t.session == nil {
+		session, err := newDebugSession(stop.i)
+		if err != nil {
+			RP.RadStderrf("Cannot start the debugger: %v\n", err)
+			t.detached = true
+			return DebugContinue
+		}
+		t.session = session
+	}
+
+	step, ok := t.session.runStop(stop)
+	if !ok {
+		t.detached = true
+	}
+	return step
+}
+
+// applyBreakFlag sets the breakpoints --break asks for, starting the terminal
+// debugger for them unless an editor's is already attached. A line is in the
+// script being run; file:line is in a module it imports.
+func applyBreakFlag(script *ScriptData) {
+	if RDebugger == nil {
+		RDebugger = NewDebugger(&terminalDebugger{})
+	}
+	RDebugger.AddFile(script.ScriptName, ScriptPath)
+
+	byFile := make(map[string][]int)
+	var files []string
+	for _, spec := range FlagBreak.Value {
+		file, line, ok := parseBreakSpec(spec)
+		if !ok {
+			RP.UsageErrorExit(fmt.Sprintf("Invalid --break '%s': expected a line number, or file:line for a module", spec))
+		}
+		if _, seen := byFile[file]; !seen {
+			files = append(files, file)
+		}
+		byFile[file] = append(byFile[file], line)
+	}
+
+	for _, file := range files {
+		lines := byFile[file]
+		var verified []bool
+		name := file
+		if file == "" {
+			name = script.ScriptName
+			verified = RDebugger.SetBreakpointsIn(script.ScriptName, script.Src, lines)
+		} else {
+			verified = RDebugger.SetBreakpoints(file, lines)
+		}
+		for idx, ok := range verified {
+			if !ok {
+				RP.RadStderrf("Warning! No statement starts on %s:%d, so execution will never stop there.\n",
+					name, lines[idx])
+			}
+		}
+	}
+}
+
+// parseBreakSpec reads a --break value: "12" or "lib/util.rad:12".
+func parseBreakSpec(spec string) (file string, line int, ok bool) {
+	lineStr := spec
+	if idx := strings.LastIndex(spec, ":"); idx >= 0 {
+		file, lineStr = spec[:idx], spec[idx+1:]
+		if file == "" {
+			return "", 0, false
+		}
+	}
+	line, err := strconv.Atoi(lineStr)
+	if err != nil || line < 1 {
+		return "", 0, false
+	}
+	return file, line, true
+}
+
+// debugSourceLine is the text of the line span starts on, for showing where a
+// stop is. The script's source is at hand; a module's is read again.
+func debugSourceLine(i *Interpreter, span rl.Span) (string, bool) {
+	src := i.sd.Src
+	if span.File != i.sd.ScriptName {
+		read, err := readSource(span.File)
+		if err != nil {
+			return "", false
+		}
+		src = read
+	}
+	lines := strings.Split(src, "\n")
+	n := span.StartLine()
+	if n < 1 || n > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[n-1], " \t"), true
+}
@@ -0,0 +1,458 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/rl"
+)
+
+const (
+	// debugEvalName is the file name diagnostics from a debugger evaluation carry.
+	debugEvalName = "<eval>"
+	// debugMainFrame names the outermost frame: the script's top level.
+	debugMainFrame = "<main>"
+)
+
+// DebugStep is how execution resumes after a pause.
+type DebugStep int
+
+const (
+	// DebugContinue runs until a breakpoint.
+	DebugContinue DebugStep = iota
+	// DebugStepIn stops at the very next statement, inside a call if need be.
+	DebugStepIn
+	// DebugStepOver stops at the next statement in this frame or a caller.
+	DebugStepOver
+	// DebugStepOut stops at the next statement in a caller.
+	DebugStepOut
+)
+
+// DebugFrontend is what a person drives a Debugger through: an editor over
+// DAP, or a prompt in the terminal.
+type DebugFrontend interface {
+	// Stopped is called on the script's goroutine each time execution pauses,
+	// and returns how to resume. The script is parked until it returns, so the
+	// stop can be inspected and evaluated in freely meanwhile.
+	Stopped(stop *DebugStop) DebugStep
+}
+
+// Debugger pauses a running script at breakpoints and steps, and hands each
+// pause to its frontend. It hooks statements rather than expressions: a
+// statement is what a line of a script is, and what a person steps over.
+type Debugger struct {
+	frontend DebugFrontend
+
+	mu          sync.Mutex
+	breakpoints map[string]map[int]bool // absolute path -> 1-based lines
+	absPaths    map[string]string       // span file -> absolute path
+
+	pauseRequested atomic.Bool
+	stopOnEntry    bool
+	step           DebugStep
+	stepDepth      int
+	evaluating     bool
+
+	// Per call depth, the env and statement last seen there. A caller's entry
+	// is the statement making the call, which is what its frame shows.
+	envs  []*Env
+	stmts []rl.Node
+}
+
+func NewDebugger(frontend DebugFrontend) *Debugger {
+	return &Debugger{
+		frontend:    frontend,
+		breakpoints: make(map[string]map[int]bool),
+		absPaths:    make(map[string]string),
+	}
+}
+
+// StopOnEntry makes the first statement a stop.
+func (d *Debugger) StopOnEntry() {
+	d.stopOnEntry = true
+}
+
+// RequestPause stops at the next statement, from whatever goroutine asks.
+func (d *Debugger) RequestPause() {
+	d.pauseRequested.Store(true)
+}
+
+// AddFile says where a file whose spans call it name lives. The main script's
+// spans carry its base name rather than a path, so without this a breakpoint
+// set on it by path is never hit.
+func (d *Debugger) AddFile(name, path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.absPaths[name] = absPath(path)
+}
+
+// SetBreakpoints replaces the breakpoints in the file at path, reporting for
+// each line whether it can stop there - that is, whether a statement starts on
+// it. Only those are kept.
+func (d *Debugger) SetBreakpoints(path string, lines []int) []bool {
+	src, err := readSource(path)
+	if err != nil {
+		return make([]bool, len(lines))
+	}
+	return d.SetBreakpointsIn(path, src, lines)
+}
+
+// SetBreakpointsIn is SetBreakpoints for a file already read: src is its text,
+// and name is what its spans call it.
+func (d *Debugger) SetBreakpointsIn(name, src string, lines []int) []bool {
+	stmtLines := statementLines(name, src)
+	verified := make([]bool, len(lines))
+	set := make(map[int]bool, len(lines))
+	for idx, line := range lines {
+		if stmtLines[line] {
+			verified[idx] = true
+			set[line] = true
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	abs := d.fileKey(name)
+	if len(set) == 0 {
+		delete(d.breakpoints, abs)
+	} else {
+		d.breakpoints[abs] = set
+	}
+	return verified
+}
+
+// statementLines is the set of lines a statement in src starts on. Source that
+// can't be parsed has none.
+func statementLines(name, src string) map[int]bool {
+	lines := make(map[int]bool)
+	parser, err := rts.NewRadParser()
+	if err != nil {
+		return lines
+	}
+	defer parser.Close()
+	tree := parser.Parse(src)
+	defer tree.Close()
+
+	ast := tryConvertAST(tree, src, name)
+	if ast == nil {
+		return lines
+	}
+	rl.Walk(ast, func(n rl.Node) {
+		switch n.(type) {
+		case *rl.Assign, *rl.ExprStmt, *rl.Pass, *rl.Return, *rl.Yield, *rl.Break, *rl.Continue,
+			*rl.ForLoop, *rl.WhileLoop, *rl.If, *rl.Switch, *rl.FnDef, *rl.Defer, *rl.Shell,
+			*rl.Del, *rl.RadBlock:
+			lines[n.Span().StartLine()] = true
+		}
+	})
+	return lines
+}
+
+func (d *Debugger) hasBreakpoint(span rl.Span) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.breakpoints) == 0 {
+		return false
+	}
+	return d.breakpoints[d.fileKey(span.File)][span.StartLine()]
+}
+
+// fileKey is the absolute path of the file spans call name, memoized. The
+// caller holds mu.
+func (d *Debugger) fileKey(name string) string {
+	abs, ok := d.absPaths[name]
+	if !ok {
+		abs = absPath(name)
+		d.absPaths[name] = abs
+	}
+	return abs
+}
+
+// debugPoint is the statement hook: a no-op without a debugger, and a possible
+// stop with one.
+func (i *Interpreter) debugPoint(stmt rl.Node) {
+	if i.debugger != nil {
+		i.debugger.beforeStmt(i, stmt)
+	}
+}
+
+func (d *Debugger) beforeStmt(i *Interpreter, stmt rl.Node) {
+	if d.evaluating {
+		// an expression typed at a stop doesn't stop itself
+		return
+	}
+
+	depth := len(i.callStack)
+	d.record(depth, i.env, stmt)
+
+	reason, ok := d.stopReason(stmt, depth)
+	if !ok {
+		return
+	}
+	d.stop(i, reason, stmt.Span())
+}
+
+// Break stops where a breakpoint() call is, as if a breakpoint were set on the
+// statement making it.
+func (d *Debugger) Break(i *Interpreter, at rl.Span) {
+	if d.evaluating {
+		return
+	}
+	d.stop(i, "breakpoint", at)
+}
+
+func (d *Debugger) stop(i *Interpreter, reason string, at rl.Span) {
+	stop := &DebugStop{d: d, i: i, Reason: reason, Frames: d.frames(i, at)}
+	d.step = d.frontend.Stopped(stop)
+	d.stepDepth = len(i.callStack)
+}
+
+func (d *Debugger) stopReason(stmt rl.Node, depth int) (string, bool) {
+	if d.stopOnEntry {
+		d.stopOnEntry = false
+		return "entry", true
+	}
+	if d.pauseRequested.Swap(false) {
+		return "pause", true
+	}
+	switch d.step {
+	case DebugStepIn:
+		return "step", true
+	case DebugStepOver:
+		if depth <= d.stepDepth {
+			return "step", true
+		}
+	case DebugStepOut:
+		if depth < d.stepDepth {
+			return "step", true
+		}
+	}
+	if d.hasBreakpoint(stmt.Span()) {
+		return "breakpoint", true
+	}
+	return "", false
+}
+
+func (d *Debugger) record(depth int, env *Env, stmt rl.Node) {
+	for len(d.envs) <= depth {
+		d.envs = append(d.envs, nil)
+		d.stmts = append(d.stmts, nil)
+	}
+	d.envs = d.envs[:depth+1]
+	d.stmts = d.stmts[:depth+1]
+	d.envs[depth] = env
+	d.stmts[depth] = stmt
+}
+
+// frames pairs the call stack with where each frame is: the paused statement
+// for the innermost, and the call it's waiting on for each caller.
+//
+// A caller's env is the one recorded at its depth. A debugger attached part-way
+// through, by breakpoint(), has seen no callers, so their variables are unknown.
+func (d *Debugger) frames(i *Interpreter, span rl.Span) []DebugFrame {
+	stack := i.CallStack()
+	frames := make([]DebugFrame, 0, len(stack)+1)
+	for idx := 0; idx <= len(stack); idx++ {
+		depth := len(stack) - idx
+		frame := DebugFrame{Name: debugMainFrame, Span: span}
+		if idx < len(stack) {
+			frame.Name = stack[idx].FunctionName
+		}
+		if idx == 0 {
+			frame.Env = i.env
+		} else if depth < len(d.envs) && d.envs[depth] != nil {
+			frame.Env = d.envs[depth]
+		}
+		frames = append(frames, frame)
+
+		switch {
+		case idx < len(stack) && stack[idx].CallSite != nil:
+			span = *stack[idx].CallSite
+		case depth > 0 && depth-1 < len(d.stmts) && d.stmts[depth-1] != nil:
+			span = d.stmts[depth-1].Span()
+		}
+	}
+	return frames
+}
+
+// DebugFrame is one frame of a paused script, innermost first.
+type DebugFrame struct {
+	Name string
+	Span rl.Span
+	Env  *Env
+}
+
+// DebugVar is a named value shown at a stop.
+type DebugVar struct {
+	Name  string
+	Value RadValue
+}
+
+// DebugScope is a group of variables visible from a frame.
+type DebugScope struct {
+	Name string
+	Vars []DebugVar
+}
+
+// Scopes lists what the frame can see: its own variables, innermost block
+// first, then the script's. Builtins are left out unless a variable shadows
+// one, the same test :vars uses.
+func (f DebugFrame) Scopes() []DebugScope {
+	var root *Env
+	for env := f.Env; env != nil; env = env.Enclosing {
+		root = env
+	}
+
+	seen := make(map[string]bool)
+	var locals []DebugVar
+	for env := f.Env; env != nil && env != root; env = env.Enclosing {
+		locals = append(locals, debugVarsIn(env, seen)...)
+	}
+	globals := debugVarsIn(root, seen)
+
+	var scopes []DebugScope
+	if len(locals) > 0 {
+		scopes = append(scopes, DebugScope{Name: "Locals", Vars: locals})
+	}
+	return append(scopes, DebugScope{Name: "Globals", Vars: globals})
+}
+
+func debugVarsIn(env *Env, seen map[string]bool) []DebugVar {
+	if env == nil {
+		return nil
+	}
+	names := make([]string, 0, len(env.Vars))
+	for name := range env.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var vars []DebugVar
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		val := env.Vars[name]
+		if _, isBuiltIn := FunctionsByName[name]; isBuiltIn && val.Type() == rl.RadFnT {
+			continue
+		}
+		vars = append(vars, DebugVar{Name: name, Value: val})
+	}
+	return vars
+}
+
+// DebugChildren lists what a value contains, for expanding it at a stop. Only
+// lists and maps have any.
+func DebugChildren(val RadValue) []DebugVar {
+	var children []DebugVar
+	switch coerced := val.Val.(type) {
+	case *RadList:
+		for idx, elem := range coerced.Values {
+			children = append(children, DebugVar{Name: fmt.Sprintf("[%d]", idx), Value: elem})
+		}
+	case *RadMap:
+		coerced.Range(func(key, value RadValue) bool {
+			children = append(children, DebugVar{Name: ToPrintableQuoteStr(key, false), Value: value})
+			return true
+		})
+	}
+	return children
+}
+
+// DebugStop is a script paused at a statement. It is only valid until the
+// frontend's Stopped returns.
+type DebugStop struct {
+	d      *Debugger
+	i      *Interpreter
+	Reason string // "breakpoint", "step", "pause" or "entry"
+	Frames []DebugFrame
+}
+
+// Evaluate runs src in the given frame and returns the value of its last
+// statement. Assignments land in the frame, so this is also how a variable is
+// changed mid-run. An error is returned rather than reported: the script being
+// inspected carries on regardless.
+func (s *DebugStop) Evaluate(frame int, src string) (RadValue, error) {
+	if frame < 0 || frame >= len(s.Frames) {
+		return VOID_SENTINEL, errors.New("No such frame")
+	}
+
+	parser, err := rts.NewRadParser()
+	if err != nil {
+		return VOID_SENTINEL, err
+	}
+	defer parser.Close()
+	tree := parser.Parse(src)
+	defer tree.Close()
+
+	var ast *rl.SourceFile
+	if !tree.HasInvalidNodes() {
+		ast = tryConvertAST(tree, src, debugEvalName)
+	}
+	if ast == nil || len(ast.Stmts) == 0 {
+		return VOID_SENTINEL, errors.New("Invalid syntax")
+	}
+	return s.evaluate(frame, ast, &ScriptData{ScriptName: debugEvalName, Tree: tree, Src: src})
+}
+
+// evaluate is Evaluate for source already parsed; sd is what its diagnostics
+// point into.
+func (s *DebugStop) evaluate(frame int, ast *rl.SourceFile, sd *ScriptData) (out RadValue, err error) {
+	if s.Frames[frame].Env == nil {
+		return VOID_SENTINEL, errors.New("This frame was entered before the debugger started, so its variables are unknown")
+	}
+
+	i := s.i
+	saved := i.saveExecState()
+	savedSd, savedSink := i.sd, i.diagnosticSink
+	i.env = s.Frames[frame].Env
+	i.sd = sd
+	i.diagnosticSink = func(d Diagnostic) { panic(&debugEvalError{diag: d}) }
+	s.d.evaluating = true
+	defer func() {
+		s.d.evaluating = false
+		i.restoreExecState(saved)
+		i.sd, i.diagnosticSink = savedSd, savedSink
+
+		if r := recover(); r != nil {
+			switch coerced := r.(type) {
+			case *debugEvalError:
+				out, err = VOID_SENTINEL, coerced
+			case *RadPanic:
+				out, err = VOID_SENTINEL, errors.New(coerced.Err().Msg().Plain())
+			default:
+				panic(r)
+			}
+		}
+	}()
+
+	out = VOID_SENTINEL
+	for _, stmt := range ast.Stmts {
+		out = i.eval(stmt).Val
+	}
+	return out, nil
+}
+
+// debugEvalError carries a diagnostic out of an evaluation as a panic, the way
+// an abort would carry it out of a script, minus the exiting.
+type debugEvalError struct {
+	diag Diagnostic
+}
+
+func (e *debugEvalError) Error() string {
+	return e.diag.Message
+}
+
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
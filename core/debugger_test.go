@@ -0,0 +1,136 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/amterp/rad/rts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const debugTestScript = "main.rad"
+
+// debugStepSrc nests two calls, so stepping has depths to tell apart:
+// top level is depth 0, outer's body 1, inner's body 2.
+const debugStepSrc = `fn inner():
+    a = 1
+    return a
+
+fn outer():
+    b = inner()
+    return b
+
+c = outer()
+print(c)
+`
+
+// scriptedFrontend records the line of each stop and resumes with the
+// next of its steps, continuing once they run out.
+type scriptedFrontend struct {
+	steps []DebugStep
+	lines []int
+	names []string
+}
+
+func (f *scriptedFrontend) Stopped(stop *DebugStop) DebugStep {
+	f.lines = append(f.lines, stop.Frames[0].Span.StartLine())
+	f.names = append(f.names, stop.Frames[0].Name)
+	if len(f.steps) == 0 {
+		return DebugContinue
+	}
+	step := f.steps[0]
+	f.steps = f.steps[1:]
+	return step
+}
+
+// runDebugged runs src as debugTestScript with d attached, the way the
+// runner attaches RDebugger, and returns what it printed.
+func runDebugged(t *testing.T, src string, d *Debugger) string {
+	t.Helper()
+	home := t.TempDir()
+	var out bytes.Buffer
+	setGlobals(RunnerInput{
+		RIo:     &RadIo{StdIn: NewBufferReader(&bytes.Buffer{}), StdOut: &out, StdErr: &out},
+		RadHome: &home,
+	})
+	t.Cleanup(ResetGlobals)
+	RP = NewPrinter(nil, false, false, false, false)
+
+	parser, err := rts.NewRadParser()
+	require.NoError(t, err)
+	defer parser.Close()
+	tree := parser.Parse(src)
+	defer tree.Close()
+
+	i := NewInterpreter(InterpreterInput{Src: src, Tree: tree, ScriptName: debugTestScript})
+	i.debugger = d
+	i.InitBuiltIns()
+	i.Run()
+	return out.String()
+}
+
+func stopsWhenStepping(t *testing.T, breakpoint int, steps ...DebugStep) []int {
+	t.Helper()
+	frontend := &scriptedFrontend{steps: steps}
+	d := NewDebugger(frontend)
+	verified := d.SetBreakpointsIn(debugTestScript, debugStepSrc, []int{breakpoint})
+	require.Equal(t, []bool{true}, verified)
+
+	out := runDebugged(t, debugStepSrc, d)
+	assert.Equal(t, "1\n", out)
+	return frontend.lines
+}
+
+func TestDebugger_StepOverSkipsCallees(t *testing.T) {
+	assert.Equal(t, []int{6, 7}, stopsWhenStepping(t, 6, DebugStepOver))
+}
+
+func TestDebugger_StepOverAtDeepestFrameStaysInIt(t *testing.T) {
+	assert.Equal(t, []int{2, 3}, stopsWhenStepping(t, 2, DebugStepOver))
+}
+
+func TestDebugger_StepInEntersCallee(t *testing.T) {
+	assert.Equal(t, []int{6, 2}, stopsWhenStepping(t, 6, DebugStepIn))
+}
+
+func TestDebugger_StepOutStopsInCaller(t *testing.T) {
+	assert.Equal(t, []int{2, 7}, stopsWhenStepping(t, 2, DebugStepOut))
+	assert.Equal(t, []int{6, 10}, stopsWhenStepping(t, 6, DebugStepOut))
+}
+
+func TestDebugger_FramesNameTheFunction(t *testing.T) {
+	frontend := &scriptedFrontend{}
+	d := NewDebugger(frontend)
+	d.SetBreakpointsIn(debugTestScript, debugStepSrc, []int{2, 9})
+	runDebugged(t, debugStepSrc, d)
+	assert.Equal(t, []int{9, 2}, frontend.lines)
+	assert.Equal(t, []string{debugMainFrame, "inner"}, frontend.names)
+}
+
+func TestDebugger_SetBreakpointsVerifiesStatementLines(t *testing.T) {
+	d := NewDebugger(&scriptedFrontend{})
+	// 4 is blank, 11 is past the end; every other line starts a statement.
+	verified := d.SetBreakpointsIn(debugTestScript, debugStepSrc, []int{1, 2, 4, 5, 9, 11})
+	assert.Equal(t, []bool{true, true, false, true, true, false}, verified)
+}
+
+func TestDebugger_SetBreakpointsReadsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.rad")
+	require.NoError(t, os.WriteFile(path, []byte("x = 1\n\nprint(x)\n"), 0o644))
+
+	d := NewDebugger(&scriptedFrontend{})
+	assert.Equal(t, []bool{true, false, true}, d.SetBreakpoints(path, []int{1, 2, 3}))
+	assert.Equal(t, []bool{false}, d.SetBreakpoints(path+".missing", []int{1}))
+}
+
+func TestDebugger_SetBreakpointsReplacesFileBreakpoints(t *testing.T) {
+	frontend := &scriptedFrontend{}
+	d := NewDebugger(frontend)
+	d.SetBreakpointsIn(debugTestScript, debugStepSrc, []int{9})
+	d.SetBreakpointsIn(debugTestScript, debugStepSrc, []int{10})
+	runDebugged(t, debugStepSrc, d)
+	assert.Equal(t, []int{10}, frontend.lines)
+}
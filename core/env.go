@@ -187,12 +187,17 @@ func (e *Env) PrintShellExports() {
 		switch coerced := val.Val.(type) {
 		case RadString, int64, float64, bool:
 			printFunc(varName, ToPrintable(val))
+		case RadDatetime, RadDuration:
+			// exported as they print, e.g. "2024-05-01T09:30:00Z"
+			printFunc(varName, ToPrintable(ToPrintableQuoteStr(val, false)))
 		case *RadList:
 			printFunc(varName, "("+strings.Join(coerced.AsStringList(true), " ")+")")
+		case *RadSet:
+			printFunc(varName, "("+strings.Join(coerced.ToList().AsStringList(true), " ")+")")
 		case *RadMap:
 			// todo can do some stuff with declare -A ?
 			printFunc(varName, "'"+coerced.ToString()+"'")
-		case RadFn:
+		case RadFn, *RadIter:
 			// skip, doesn't make sense
 		case RadNull:
 			// skip, implies undefined
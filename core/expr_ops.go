@@ -18,12 +18,14 @@ func (i *Interpreter) executeUnaryOp(n *rl.OpUnary) RadValue {
 		argVal.RequireNonVoid(i, n.Operand)
 		argVal.RequireType(i, n.Operand,
 			fmt.Sprintf("Invalid operand type '%s' for op '-'", TypeAsString(argVal)),
-			rl.RadIntT, rl.RadFloatT)
+			rl.RadIntT, rl.RadFloatT, rl.RadDurationT)
 		switch coerced := argVal.Val.(type) {
 		case int64:
 			return newRadValue(i, n, -coerced)
 		case float64:
 			return newRadValue(i, n, -coerced)
+		case RadDuration:
+			return newRadValue(i, n, RadDuration{Dur: -coerced.Dur})
 		default:
 			i.emitErrorf(rl.ErrInternalBug, n, "Bug: Unhandled type for unary minus: %T", argVal.Val)
 			panic(UNREACHABLE)
@@ -34,7 +36,7 @@ func (i *Interpreter) executeUnaryOp(n *rl.OpUnary) RadValue {
 		argVal.RequireNonVoid(i, n.Operand)
 		argVal.RequireType(i, n.Operand,
 			fmt.Sprintf("Invalid operand type '%s' for op '+'", TypeAsString(argVal)),
-			rl.RadIntT, rl.RadFloatT)
+			rl.RadIntT, rl.RadFloatT, rl.RadDurationT)
 		return newRadValue(i, n, argVal.Val)
 	case rl.OpNot:
 		return newRadValue(i, n, !i.eval(n.Operand).Val.TruthyFalsy())
@@ -84,6 +86,11 @@ func (i *Interpreter) executeOp(
 		return (op == rl.OpEq) == eq
 	}
 
+	// Set membership is a lookup, whatever the left operand is.
+	if set, ok := right().TryGetSet(); ok && (op == rl.OpIn || op == rl.OpNotIn) {
+		return (op == rl.OpIn) == set.Contains(left())
+	}
+
 	// Set when the operand types suggest a specific fix. Rendered as a
 	// "= help:" line rather than appended to the message, so the message stays
 	// one sentence naming what is wrong.
@@ -158,6 +165,11 @@ func (i *Interpreter) executeOp(
 			case rl.OpMul:
 				return coercedRight.Repeat(coercedLeft)
 			}
+		case RadDuration:
+			switch op {
+			case rl.OpMul:
+				return i.scaleDuration(parentNode, coercedRight.Dur, coercedLeft)
+			}
 		case *RadList:
 			switch op {
 			case rl.OpIn:
@@ -229,6 +241,11 @@ func (i *Interpreter) executeOp(
 			case rl.OpLte:
 				return coercedLeft <= coercedRight
 			}
+		case RadDuration:
+			switch op {
+			case rl.OpMul:
+				return i.scaleDuration(parentNode, coercedRight.Dur, coercedLeft)
+			}
 		case *RadList:
 			switch op {
 			case rl.OpIn:
@@ -335,6 +352,31 @@ func (i *Interpreter) executeOp(
 				return !coercedRight.Contains(left())
 			}
 		}
+	case *RadSet:
+		switch coercedRight := rightV.(type) {
+		case *RadSet:
+			switch op {
+			case rl.OpAdd:
+				return coercedLeft.Union(coercedRight)
+			case rl.OpSub:
+				return coercedLeft.Difference(coercedRight)
+			case rl.OpLte:
+				return coercedLeft.IsSubsetOf(coercedRight)
+			case rl.OpLt:
+				return coercedLeft.Len() < coercedRight.Len() && coercedLeft.IsSubsetOf(coercedRight)
+			case rl.OpGte:
+				return coercedRight.IsSubsetOf(coercedLeft)
+			case rl.OpGt:
+				return coercedRight.Len() < coercedLeft.Len() && coercedRight.IsSubsetOf(coercedLeft)
+			}
+		case *RadList:
+			switch op {
+			case rl.OpIn:
+				return coercedRight.Contains(left())
+			case rl.OpNotIn:
+				return !coercedRight.Contains(left())
+			}
+		}
 	case RadNull:
 		switch coercedRight := rightV.(type) {
 		case *RadList:
@@ -352,6 +394,33 @@ func (i *Interpreter) executeOp(
 				return true
 			}
 		}
+	case RadDatetime, RadDuration:
+		var res interface{}
+		var ok bool
+		if dt, isDt := coercedLeft.(RadDatetime); isDt {
+			res, ok = datetimeOp(op, dt, rightV)
+		} else {
+			res, ok = i.durationOp(parentNode, rightNode, op, coercedLeft.(RadDuration), rightV)
+		}
+		if ok {
+			return res
+		}
+		switch coercedRight := rightV.(type) {
+		case *RadList:
+			switch op {
+			case rl.OpIn:
+				return coercedRight.Contains(left())
+			case rl.OpNotIn:
+				return !coercedRight.Contains(left())
+			}
+		case *RadMap:
+			switch op {
+			case rl.OpIn:
+				return coercedRight.ContainsKey(left())
+			case rl.OpNotIn:
+				return !coercedRight.ContainsKey(left())
+			}
+		}
 	case *RadError:
 		switch coercedRight := rightV.(type) {
 		case RadString:
@@ -1,10 +1,17 @@
 package core
 
+import "github.com/samber/lo"
+
 const (
 	COLOR_AUTO   = "auto"
 	COLOR_ALWAYS = "always"
 	COLOR_NEVER  = "never"
 
+	OUTPUT_TEXT   = "text"
+	OUTPUT_JSON   = "json"
+	OUTPUT_YAML   = "yaml"
+	OUTPUT_NDJSON = "ndjson"
+
 	FLAG_HELP          = "help"
 	FLAG_H             = "h"
 	FLAG_DEBUG         = "debug"
@@ -27,6 +34,8 @@ const (
 	FLAG_I             = "i"
 	FLAG_REPLY         = "reply"
 	FLAG_REPLY_NA      = "reply-na"
+	FLAG_OUTPUT        = "output"
+	FLAG_BREAK         = "break"
 )
 
 // GlobalFlagScope classifies which invocations a global flag applies to.
@@ -48,6 +57,7 @@ type ScopedGlobalFlag struct {
 
 var (
 	MODES          = []string{COLOR_AUTO, COLOR_ALWAYS, COLOR_NEVER}
+	OUTPUT_FORMATS = []string{OUTPUT_TEXT, OUTPUT_JSON, OUTPUT_YAML, OUTPUT_NDJSON}
 	NO_CONSTRAINTS []string
 
 	FlagHelp                 BoolRadArg
@@ -67,6 +77,8 @@ var (
 	FlagInteractive          BoolRadArg
 	FlagReply                StringListRadArg
 	FlagReplyNa              StringListRadArg
+	FlagOutput               StringRadArg
+	FlagBreak                StringListRadArg
 	// ^ when adding more, update ResetGlobals and the GlobalFlagScopes table below
 
 	// GlobalFlagScopes is the single source of truth for global flag ordering
@@ -74,7 +86,11 @@ var (
 	GlobalFlagScopes []ScopedGlobalFlag
 )
 
-func CreateAndRegisterGlobalFlags(invocationType InvocationType) []RadArg {
+// CreateAndRegisterGlobalFlags builds and registers rad's global flags.
+// outputFlag adds --output, which only scripts that opt in get: on any other
+// script it'd promise machine-readable output the script never produces, and
+// would take the name from scripts with an `output` arg of their own.
+func CreateAndRegisterGlobalFlags(invocationType InvocationType, outputFlag bool) []RadArg {
 	FlagHelp = NewBoolRadArg(
 		FLAG_HELP,
 		FLAG_H,
@@ -272,6 +288,31 @@ func CreateAndRegisterGlobalFlags(invocationType InvocationType) []RadArg {
 	)
 	FlagReplyNa.SetUsagePlaceholder("line")
 
+	FlagOutput = NewStringRadArg(
+		FLAG_OUTPUT,
+		"",
+		"Format for the script's emitted output. Non-text formats move other output to stderr.",
+		true,
+		OUTPUT_TEXT,
+		&OUTPUT_FORMATS,
+		nil,
+		NO_CONSTRAINTS,
+		NO_CONSTRAINTS,
+	)
+	FlagOutput.SetUsagePlaceholder("format")
+
+	FlagBreak = NewStringListRadArg(
+		FLAG_BREAK,
+		"",
+		"Pause at a line and open a debugger prompt there. Repeatable; file:line for a module.",
+		true,
+		[]string{},
+		NO_CONSTRAINTS,
+		NO_CONSTRAINTS,
+	)
+	FlagBreak.SetUsagePlaceholder("line")
+	hideFromUsageIfHaveScript(&FlagBreak.hidden)
+
 	// ordering of this table matters -- it's the order in which flags are printed in the usage string
 	GlobalFlagScopes = []ScopedGlobalFlag{
 		{&FlagHelp, ScopeUniversal},
@@ -280,6 +321,7 @@ func CreateAndRegisterGlobalFlags(invocationType InvocationType) []RadArg {
 		{&FlagRadDebug, ScopeUniversal},
 		{&FlagColor, ScopeUniversal},
 		{&FlagQuiet, ScopeUniversal},
+		{&FlagOutput, ScopeScriptOnly},
 		{&FlagShell, ScopeScriptOnly},
 		{&FlagVersion, ScopeRootOnly},
 		{&FlagConfirmShellCommands, ScopeScriptOnly},
@@ -291,6 +333,12 @@ func CreateAndRegisterGlobalFlags(invocationType InvocationType) []RadArg {
 		{&FlagMockResponse, ScopeScriptOnly},
 		{&FlagReply, ScopeScriptOnly},
 		{&FlagReplyNa, ScopeScriptOnly},
+		{&FlagBreak, ScopeScriptOnly},
+	}
+	if !outputFlag {
+		GlobalFlagScopes = lo.Reject(GlobalFlagScopes, func(scoped ScopedGlobalFlag, _ int) bool {
+			return scoped.Arg == RadArg(&FlagOutput)
+		})
 	}
 
 	flags := make([]RadArg, 0, len(GlobalFlagScopes))
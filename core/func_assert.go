@@ -0,0 +1,54 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncAssert = BuiltInFunc{
+	Name: FUNC_ASSERT,
+	Execute: func(f FuncInvocation) RadValue {
+		if !f.GetArg("_cond").TruthyFalsy() {
+			failAssertion(f, "")
+		}
+		return VOID_SENTINEL
+	},
+}
+
+var FuncAssertEq = BuiltInFunc{
+	Name: FUNC_ASSERT_EQ,
+	Execute: func(f FuncInvocation) RadValue {
+		actual := f.GetArg("_actual")
+		expected := f.GetArg("_expected")
+		if !actual.Equals(expected) {
+			failAssertion(f, fmt.Sprintf("expected %s, got %s",
+				ToPrintableQuoteStr(expected, true), ToPrintableQuoteStr(actual, true)))
+		}
+		return VOID_SENTINEL
+	},
+}
+
+var FuncAssertNe = BuiltInFunc{
+	Name: FUNC_ASSERT_NE,
+	Execute: func(f FuncInvocation) RadValue {
+		actual := f.GetArg("_actual")
+		if actual.Equals(f.GetArg("_expected")) {
+			failAssertion(f, fmt.Sprintf("expected anything but %s", ToPrintableQuoteStr(actual, true)))
+		}
+		return VOID_SENTINEL
+	},
+}
+
+// failAssertion reports a failed assertion at the call. The caller's message,
+// when they gave one, leads; detail says how the values differed.
+func failAssertion(f FuncInvocation, detail string) {
+	msg := "Assertion failed"
+	if userMsg := f.GetArg("_msg"); !userMsg.IsNull() {
+		msg = userMsg.RequireStr(f.i, f.callNode).Plain()
+	}
+	if detail != "" {
+		msg += ": " + detail
+	}
+	f.i.emitError(rl.ErrAssertionFailed, f.callNode, msg)
+}
@@ -0,0 +1,28 @@
+package core
+
+import "github.com/amterp/rad/rts/rl"
+
+var FuncBreakpoint = BuiltInFunc{
+	Name: FUNC_BREAKPOINT,
+	Execute: func(f FuncInvocation) RadValue {
+		f.i.breakHere(f.callNode)
+		return VOID_SENTINEL
+	},
+}
+
+// breakHere stops the script at a breakpoint() call. With no debugger attached,
+// one is started in the terminal - which is the point of writing breakpoint()
+// rather than setting one from an editor.
+func (i *Interpreter) breakHere(at rl.Node) {
+	if i.sd.ScriptName == replScriptName {
+		// a REPL session is already the prompt a breakpoint would open
+		return
+	}
+	if i.debugger == nil {
+		if RDebugger == nil {
+			RDebugger = NewDebugger(&terminalDebugger{})
+		}
+		i.debugger = RDebugger
+	}
+	i.debugger.Break(i, at.Span())
+}
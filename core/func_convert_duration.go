@@ -13,31 +13,42 @@ var FuncConvertDuration = BuiltInFunc{
 		value := f.GetFloat("_value")
 		unit := f.GetStr("_unit").Plain()
 
-		var nanosFloat float64
-		switch unit {
-		case "nanos":
-			nanosFloat = value
-		case "micros":
-			nanosFloat = value * float64(time.Microsecond)
-		case "millis":
-			nanosFloat = value * float64(time.Millisecond)
-		case "seconds":
-			nanosFloat = value * float64(time.Second)
-		case "minutes":
-			nanosFloat = value * float64(time.Minute)
-		case "hours":
-			nanosFloat = value * float64(time.Hour)
-		case "days":
-			nanosFloat = value * 24 * float64(time.Hour)
-		default:
-			bugIncorrectTypes(FUNC_CONVERT_DURATION)
-			panic(UNREACHABLE)
+		nanos, errVal := unitDurationNanos(f, FUNC_CONVERT_DURATION, value, unit)
+		if errVal != nil {
+			return *errVal
 		}
 
-		if math.IsNaN(nanosFloat) || math.IsInf(nanosFloat, 0) || math.Abs(nanosFloat) > float64(math.MaxInt64) {
-			return f.ReturnErrf(rl.ErrNumInvalidRange, "Duration overflow: value too large for %s", unit)
-		}
-
-		return f.Return(NewDurationMap(int64(nanosFloat)))
+		return f.Return(NewDurationMap(nanos))
 	},
 }
+
+// unitDurationNanos converts value, in the given unit, to nanoseconds. A
+// non-nil errVal is ready to return as-is.
+func unitDurationNanos(f FuncInvocation, funcName string, value float64, unit string) (int64, *RadValue) {
+	var nanosFloat float64
+	switch unit {
+	case "nanos":
+		nanosFloat = value
+	case "micros":
+		nanosFloat = value * float64(time.Microsecond)
+	case "millis":
+		nanosFloat = value * float64(time.Millisecond)
+	case "seconds":
+		nanosFloat = value * float64(time.Second)
+	case "minutes":
+		nanosFloat = value * float64(time.Minute)
+	case "hours":
+		nanosFloat = value * float64(time.Hour)
+	case "days":
+		nanosFloat = value * 24 * float64(time.Hour)
+	default:
+		bugIncorrectTypes(funcName)
+		panic(UNREACHABLE)
+	}
+
+	if math.IsNaN(nanosFloat) || math.IsInf(nanosFloat, 0) || math.Abs(nanosFloat) > float64(math.MaxInt64) {
+		errVal := f.ReturnErrf(rl.ErrNumInvalidRange, "Duration overflow: value too large for %s", unit)
+		return 0, &errVal
+	}
+	return int64(nanosFloat), nil
+}
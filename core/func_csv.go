@@ -0,0 +1,149 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncParseCsv = BuiltInFunc{
+	Name: FUNC_PARSE_CSV,
+	Execute: func(f FuncInvocation) RadValue {
+		r := csv.NewReader(strings.NewReader(f.GetStr("_str").Plain()))
+		r.Comma = csvDelimiter(f)
+		r.LazyQuotes = f.GetBool("lazy_quotes")
+		if comment, ok := f.GetArg("comment").TryGetStr(); ok {
+			r.Comment = csvRune(f, "comment", comment.Plain())
+		}
+
+		records, err := r.ReadAll()
+		if err != nil {
+			return f.ReturnErrf(rl.ErrParseData, "Error parsing CSV: %v", err)
+		}
+
+		out := NewRadList()
+		if !f.GetBool("header") {
+			for _, record := range records {
+				out.Append(newRadValue(f.i, f.callNode, record))
+			}
+			return f.Return(out)
+		}
+
+		if len(records) == 0 {
+			return f.Return(out)
+		}
+		header := records[0]
+		seen := make(map[string]bool, len(header))
+		for _, name := range header {
+			if seen[name] {
+				return f.ReturnErrf(rl.ErrParseData, "Error parsing CSV: duplicate column '%s' in header", name)
+			}
+			seen[name] = true
+		}
+		// Rows keep the header's column order. The reader already rejected any
+		// row with a different number of fields.
+		for _, record := range records[1:] {
+			row := NewRadMap()
+			for idx, name := range header {
+				row.SetPrimitiveStr(name, record[idx])
+			}
+			out.Append(newRadValueMap(row))
+		}
+		return f.Return(out)
+	},
+}
+
+var FuncToCsv = BuiltInFunc{
+	Name: FUNC_TO_CSV,
+	Execute: func(f FuncInvocation) RadValue {
+		rows := f.GetList("_rows").Values
+
+		var columns []string
+		if cols, ok := f.GetArg("columns").TryGetList(); ok {
+			for _, col := range cols.Values {
+				columns = append(columns, col.RequireStr(f.i, f.callNode).Plain())
+			}
+		} else {
+			columns = csvColumns(rows)
+		}
+
+		buf := &bytes.Buffer{}
+		w := csv.NewWriter(buf)
+		w.Comma = csvDelimiter(f)
+		if columns != nil && f.GetBool("header") {
+			w.Write(columns)
+		}
+		for idx, row := range rows {
+			var cells []RadValue
+			switch coerced := row.Val.(type) {
+			case *RadList:
+				cells = coerced.Values
+			case *RadMap:
+				for _, col := range columns {
+					cell, _ := coerced.Get(newRadValueStr(col))
+					cells = append(cells, cell)
+				}
+			default:
+				return f.ReturnErrf(rl.ErrEncodeData, "Rows must be maps or lists, row %d is %s", idx, row.Type().AsString())
+			}
+
+			record := make([]string, len(cells))
+			for c, cell := range cells {
+				switch cell.Type() {
+				case rl.RadNullT:
+					// also covers a map row missing one of the columns
+					record[c] = ""
+				case rl.RadListT, rl.RadMapT, rl.RadSetT:
+					return f.ReturnErrf(rl.ErrEncodeData, "Can't write a %s to a CSV cell, in row %d", cell.Type().AsString(), idx)
+				default:
+					record[c] = ToPrintableQuoteStr(cell, false)
+				}
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return f.ReturnErrf(rl.ErrEncodeData, "Failed to serialize to CSV: %v", err)
+		}
+
+		return f.Return(strings.TrimSuffix(buf.String(), "\n"))
+	},
+}
+
+// csvColumns names the columns for rows of maps: every key, in the order the
+// rows first use it. Nil if the rows are lists.
+func csvColumns(rows []RadValue) []string {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		m, ok := row.TryGetMap()
+		if !ok {
+			continue
+		}
+		for _, key := range m.Keys() {
+			name := ToPrintableQuoteStr(key, false)
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+	}
+	return columns
+}
+
+func csvDelimiter(f FuncInvocation) rune {
+	return csvRune(f, "delimiter", f.GetStr("delimiter").Plain())
+}
+
+// csvRune requires a single-character argument, as the delimiter and comment
+// are. Quotes, newlines and the Unicode replacement character can't be used.
+func csvRune(f FuncInvocation, arg, s string) rune {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 || size != len(s) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode, "'%s' must be a single character other than a quote or newline, got %q", arg, s)
+	}
+	return r
+}
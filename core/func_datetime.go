@@ -0,0 +1,146 @@
+package core
+
+import (
+	"time"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncDatetime = BuiltInFunc{
+	Name: FUNC_DATETIME,
+	Execute: func(f FuncInvocation) RadValue {
+		val := f.GetArg("_val")
+		location, errVal := resolveTimeLocation(f, f.GetStr("tz").Plain())
+		if errVal != nil {
+			return *errVal
+		}
+
+		var t time.Time
+		switch coerced := val.Val.(type) {
+		case RadNull:
+			t = RClock.Now()
+		case RadString:
+			t, errVal = parseDateStr(f, coerced.Plain(), f.GetArg("format"), location)
+		case int64, float64:
+			t, errVal = epochToTime(f, FUNC_DATETIME, val, f.GetStr("unit").Plain())
+		case *RadMap:
+			// a time map, as now() and parse_date() return
+			nanos, ok := timeMapNanos(coerced)
+			if !ok {
+				f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode,
+					"Map must be a time map, as returned by %s() or %s(), with an int 'epoch.nanos'", FUNC_NOW, FUNC_PARSE_DATE)
+			}
+			t = time.Unix(0, nanos)
+		default:
+			bugIncorrectTypes(FUNC_DATETIME)
+		}
+		if errVal != nil {
+			return *errVal
+		}
+
+		return f.Return(RadDatetime{Time: t.In(location)})
+	},
+}
+
+func timeMapNanos(m *RadMap) (int64, bool) {
+	epoch, ok := m.Get(newRadValueStr("epoch"))
+	if !ok {
+		return 0, false
+	}
+	epochMap, ok := epoch.TryGetMap()
+	if !ok {
+		return 0, false
+	}
+	nanos, ok := epochMap.Get(newRadValueStr("nanos"))
+	if !ok {
+		return 0, false
+	}
+	n, ok := nanos.Val.(int64)
+	return n, ok
+}
+
+var FuncDuration = BuiltInFunc{
+	Name: FUNC_DURATION,
+	Execute: func(f FuncInvocation) RadValue {
+		val := f.GetArg("_val")
+
+		switch coerced := val.Val.(type) {
+		case RadString:
+			nanos, err := ParseDurationString(coerced.Plain())
+			if err != nil {
+				return f.ReturnErrf(rl.ErrParseDuration, "Failed to parse duration %q: %s", coerced.Plain(), err)
+			}
+			return f.Return(RadDuration{Dur: time.Duration(nanos)})
+		case int64, float64:
+			value, _ := val.TryGetFloatAllowingInt()
+			nanos, errVal := unitDurationNanos(f, FUNC_DURATION, value, f.GetStr("unit").Plain())
+			if errVal != nil {
+				return *errVal
+			}
+			return f.Return(RadDuration{Dur: time.Duration(nanos)})
+		case *RadMap:
+			// a duration map, as parse_duration() returns
+			nanos, ok := coerced.Get(newRadValueStr("nanos"))
+			if _, isInt := nanos.Val.(int64); !ok || !isInt {
+				f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode,
+					"Map must be a duration map, as returned by %s(), with an int 'nanos'", FUNC_PARSE_DURATION)
+			}
+			return f.Return(RadDuration{Dur: time.Duration(nanos.Val.(int64))})
+		default:
+			bugIncorrectTypes(FUNC_DURATION)
+			panic(UNREACHABLE)
+		}
+	},
+}
+
+var FuncInTz = BuiltInFunc{
+	Name: FUNC_IN_TZ,
+	Execute: func(f FuncInvocation) RadValue {
+		dt := requireDatetime(f, "_dt")
+		location, errVal := resolveTimeLocation(f, f.GetStr("_tz").Plain())
+		if errVal != nil {
+			return *errVal
+		}
+		return f.Return(RadDatetime{Time: dt.Time.In(location)})
+	},
+}
+
+var FuncStartOf = BuiltInFunc{
+	Name: FUNC_START_OF,
+	Execute: func(f FuncInvocation) RadValue {
+		t := requireDatetime(f, "_dt").Time
+		year, month, day := t.Date()
+		hour, minute, second := t.Clock()
+
+		// Truncating by calendar fields, rather than time.Truncate, keeps days
+		// aligned to midnight in the datetime's own zone, not in UTC.
+		switch f.GetStr("_unit").Plain() {
+		case "year":
+			month, day, hour, minute, second = time.January, 1, 0, 0, 0
+		case "month":
+			day, hour, minute, second = 1, 0, 0, 0
+		case "day":
+			hour, minute, second = 0, 0, 0
+		case "hour":
+			minute, second = 0, 0
+		case "minute":
+			second = 0
+		case "second":
+		default:
+			bugIncorrectTypes(FUNC_START_OF)
+		}
+		return f.Return(RadDatetime{Time: time.Date(year, month, day, hour, minute, second, 0, t.Location())})
+	},
+}
+
+// requireDatetime returns the named argument, which the signature can only
+// declare as `any`, as a datetime.
+func requireDatetime(f FuncInvocation, name string) RadDatetime {
+	arg := f.GetArg(name)
+	dt, ok := arg.Val.(RadDatetime)
+	if !ok {
+		f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode,
+			"'%s' must be a datetime, made with %s(), got %s", name, FUNC_DATETIME, TypeAsString(arg))
+	}
+	return dt
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	com "github.com/amterp/rad/core/common"
+	"github.com/amterp/rad/rts/rl"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// FuncEmit writes a script's result in whatever format --output asks for. In
+// text (the default, and the only format for scripts that haven't opted into
+// --output) it renders for a person and goes through RP like print. In a
+// machine format it writes straight to stdout, which the printer has kept clear
+// of everything else.
+var FuncEmit = BuiltInFunc{
+	Name: FUNC_EMIT,
+	Execute: func(f FuncInvocation) RadValue {
+		val := f.GetArg("_val")
+
+		if !isMachineOutput() {
+			emitText(val)
+			return VOID_SENTINEL
+		}
+
+		out, err := renderEmitMachine(val, outputFormat())
+		if err != nil {
+			return f.ReturnErrf(rl.ErrInternalBug, "Failed to emit as %s: %v", outputFormat(), err)
+		}
+		fmt.Fprint(RIo.StdOut, out)
+		return VOID_SENTINEL
+	},
+}
+
+// outputFormat is the --output format in effect. Text when the script hasn't
+// opted into the flag, since it's then never registered or parsed.
+func outputFormat() string {
+	if FlagOutput.Value == "" {
+		return OUTPUT_TEXT
+	}
+	return FlagOutput.Value
+}
+
+func isMachineOutput() bool {
+	return outputFormat() != OUTPUT_TEXT
+}
+
+func renderEmitMachine(val RadValue, format string) (string, error) {
+	jsonStruct := RadToJsonType(val)
+	switch format {
+	case OUTPUT_JSON:
+		return encodeEmitJson(jsonStruct, "  ")
+	case OUTPUT_NDJSON:
+		// A list is a stream of records, one per line; anything else is one.
+		items, ok := jsonStruct.([]interface{})
+		if !ok {
+			items = []interface{}{jsonStruct}
+		}
+		var sb strings.Builder
+		for _, item := range items {
+			line, err := encodeEmitJson(item, "")
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(line)
+		}
+		return sb.String(), nil
+	case OUTPUT_YAML:
+		buf := &bytes.Buffer{}
+		enc := yaml.NewEncoder(buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(jsonStruct); err != nil {
+			return "", err
+		}
+		if err := enc.Close(); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// encodeEmitJson encodes one JSON document, newline-terminated. HTML
+// characters aren't escaped, matching to_json.
+func encodeEmitJson(jsonStruct interface{}, indent string) (string, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if indent != "" {
+		enc.SetIndent("", indent)
+	}
+	if err := enc.Encode(jsonStruct); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// emitText prints a value for a person to read: a list of maps as a table, a
+// map as aligned key-value lines, a list one item per line, and anything else
+// as print would show it.
+func emitText(val RadValue) {
+	if list, ok := val.Val.(*RadList); ok && !list.IsEmpty() &&
+		lo.EveryBy(list.Values, func(v RadValue) bool { _, ok := v.Val.(*RadMap); return ok }) {
+		emitTable(list)
+		return
+	}
+	RP.Print(renderEmitText(val))
+}
+
+func renderEmitText(val RadValue) string {
+	switch coerced := val.Val.(type) {
+	case *RadList:
+		var sb strings.Builder
+		for _, item := range coerced.Values {
+			sb.WriteString(emitCellText(item) + "\n")
+		}
+		return sb.String()
+	case *RadMap:
+		keys := lo.Map(coerced.Keys(), func(k RadValue, _ int) string { return ToPrintableQuoteStr(k, false) })
+		width := lo.Max(lo.Map(keys, func(k string, _ int) int { return com.StrLen(k) }))
+		var sb strings.Builder
+		for idx, key := range coerced.Keys() {
+			value, _ := coerced.Get(key)
+			padding := strings.Repeat(" ", width-com.StrLen(keys[idx]))
+			sb.WriteString(com.YellowS("%s", keys[idx]) + padding + "  " + emitCellText(value) + "\n")
+		}
+		return sb.String()
+	default:
+		return emitCellText(val) + "\n"
+	}
+}
+
+// emitTable prints rows as a table whose columns are every key seen, in the
+// order first seen. A row missing a key leaves its cell blank.
+func emitTable(rows *RadList) {
+	var headers []string
+	var headerKeys []RadValue
+	seen := make(map[string]bool)
+	for _, row := range rows.Values {
+		for _, key := range row.Val.(*RadMap).Keys() {
+			header := ToPrintableQuoteStr(key, false)
+			if !seen[header] {
+				seen[header] = true
+				headers = append(headers, header)
+				headerKeys = append(headerKeys, key)
+			}
+		}
+	}
+
+	tbl := NewTblWriter()
+	tbl.SetHeader(headers)
+	for _, row := range rows.Values {
+		m := row.Val.(*RadMap)
+		cells := make([]RadString, len(headers))
+		for idx, key := range headerKeys {
+			if value, ok := m.Get(key); ok {
+				cells[idx] = NewRadString(emitCellText(value))
+			} else {
+				cells[idx] = NewRadString("")
+			}
+		}
+		tbl.Append(cells)
+	}
+	tbl.Render()
+}
+
+func emitCellText(val RadValue) string {
+	return ToPrintableQuoteStr(val, false)
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncFind = BuiltInFunc{
+	Name: FUNC_FIND,
+	Execute: func(f FuncInvocation) RadValue {
+		input := f.GetStr("_str").Plain()
+
+		re, err := regexp.Compile(f.GetStr("_pattern").Plain())
+		if err != nil {
+			return f.ReturnErrf(rl.ErrInvalidRegex, "Error compiling regex pattern: %s", err)
+		}
+
+		match := re.FindStringSubmatchIndex(input)
+		if match == nil {
+			return f.Return(RAD_NULL_VAL)
+		}
+		return f.Return(newMatchMap(re, input, match, newRuneOffsets(input)))
+	},
+}
+
+var FuncFindAll = BuiltInFunc{
+	Name: FUNC_FIND_ALL,
+	Execute: func(f FuncInvocation) RadValue {
+		input := f.GetStr("_str").Plain()
+
+		re, err := regexp.Compile(f.GetStr("_pattern").Plain())
+		if err != nil {
+			return f.ReturnErrf(rl.ErrInvalidRegex, "Error compiling regex pattern: %s", err)
+		}
+
+		offsets := newRuneOffsets(input)
+		out := NewRadList()
+		for _, match := range re.FindAllStringSubmatchIndex(input, -1) {
+			out.Append(newRadValueMap(newMatchMap(re, input, match, offsets)))
+		}
+		return f.Return(out)
+	},
+}
+
+// newMatchMap describes one match of re in s, given its submatch byte offsets
+// as Go's regexp reports them:
+//
+//	{ "match": str, "start": int, "end": int, "groups": str?[], "named": { str: str? } }
+//
+// start and end are character offsets, matching how strings index and slice,
+// so s[m.start:m.end] is the match. A group that didn't take part in the
+// match, e.g. one in an alternation branch that didn't run, is null.
+func newMatchMap(re *regexp.Regexp, s string, match []int, offsets runeOffsets) *RadMap {
+	group := func(g int) RadValue {
+		start, end := match[2*g], match[2*g+1]
+		if start < 0 {
+			return RAD_NULL_VAL
+		}
+		return newRadValueStr(s[start:end])
+	}
+
+	groups := NewRadList()
+	named := NewRadMap()
+	for g, name := range re.SubexpNames() {
+		if g == 0 {
+			continue
+		}
+		groups.Append(group(g))
+		if name != "" {
+			named.Set(newRadValueStr(name), group(g))
+		}
+	}
+
+	out := NewRadMap()
+	out.SetPrimitiveStr("match", s[match[0]:match[1]])
+	out.SetPrimitiveInt("start", offsets.at(match[0]))
+	out.SetPrimitiveInt("end", offsets.at(match[1]))
+	out.SetPrimitiveList("groups", groups)
+	out.SetPrimitiveMap("named", named)
+	return out
+}
+
+// runeOffsets converts byte offsets into s to character offsets. Nil for
+// ASCII strings, where the two are the same.
+type runeOffsets []int
+
+func newRuneOffsets(s string) runeOffsets {
+	if utf8.RuneCountInString(s) == len(s) {
+		return nil
+	}
+	offsets := make(runeOffsets, len(s)+1)
+	idx := 0
+	for b := range s {
+		offsets[b] = idx
+		idx++
+	}
+	// Only rune starts and the end of s are ever looked up, as that's all
+	// a match can begin or end on.
+	offsets[len(s)] = idx
+	return offsets
+}
+
+func (o runeOffsets) at(b int) int {
+	if o == nil {
+		return b
+	}
+	return o[b]
+}
@@ -43,6 +43,16 @@ func (i *Interpreter) callFunction(callNode *rl.Call, ufcsArg *PosArg) RadValue
 		}
 	}
 
+	// `ns.fn()` on an import() namespace calls the module's fn, with no
+	// receiver - the namespace is where fn lives, not its first argument.
+	var moduleFn *RadFn
+	if ufcsArg != nil && funcName != "" {
+		if fn, ok := i.moduleFn(ufcsArg.value, funcName, funcExpr); ok {
+			moduleFn = &fn
+			ufcsArg = nil
+		}
+	}
+
 	var args []PosArg
 
 	if ufcsArg != nil {
@@ -75,6 +85,10 @@ func (i *Interpreter) callFunction(callNode *rl.Call, ufcsArg *PosArg) RadValue
 		}
 	}
 
+	if moduleFn != nil {
+		return moduleFn.Execute(NewFnInvocation(i, callNode, funcName, args, namedArgs, false))
+	}
+
 	// For UFCS or method-like calls, resolve via VarPath
 	if funcName == "" {
 		// Dynamic call - evaluate Func expression to get callable
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/rl"
+	"github.com/samber/lo"
+)
+
+// radModule is a file loaded by import(). Its top level runs once, in an
+// interpreter of its own; the functions it defines are then shared by every
+// importer through ns, and run against the module's env rather than theirs.
+type radModule struct {
+	path string
+	src  string
+	ns   *RadMap
+}
+
+// moduleCache is the modules one run has imported. It belongs to the
+// interpreter doing the run and is shared with each module's own interpreter,
+// so a fresh interpreter - a `rad test` case, a reset session - starts with
+// nothing loaded.
+type moduleCache struct {
+	// byPath caches loaded modules by absolute path, so a module imported
+	// from several places is parsed and run once.
+	byPath map[string]*radModule
+	// namespaces tells a namespace returned by import() apart from a map
+	// that merely holds functions: only the former makes `ns.fn()` a call to
+	// fn rather than fn(ns).
+	namespaces map[*RadMap]*radModule
+	// loading is the chain of modules currently loading, to report a cycle
+	// rather than recurse into one.
+	loading []string
+}
+
+func newModuleCache() *moduleCache {
+	return &moduleCache{
+		byPath:     make(map[string]*radModule),
+		namespaces: make(map[*RadMap]*radModule),
+	}
+}
+
+var FuncImport = BuiltInFunc{
+	Name: FUNC_IMPORT,
+	Execute: func(f FuncInvocation) RadValue {
+		spec := f.GetStr("_path").Plain()
+		modules := f.i.modules
+		path, err := filepath.Abs(rts.ResolveImport(spec, modules.importingDir(f.callNode), RadHomeInst.HomeDir))
+		if err != nil {
+			return f.ReturnErrf(rl.ErrImportFailed, "Cannot import '%s': %v", spec, err)
+		}
+
+		var given *RadMap
+		if withArgs := f.GetArg("with_args"); !withArgs.IsNull() {
+			if currentTestRun == nil {
+				return f.ReturnErrf(rl.ErrImportFailed, "Cannot import '%s': 'with_args' is only for tests run by rad test", spec)
+			}
+			given = withArgs.RequireMap(f.i, f.callNode)
+		}
+
+		mod, err := modules.load(path, given)
+		if err != nil {
+			return f.ReturnErrf(rl.ErrImportFailed, "Cannot import '%s': %v", spec, err)
+		}
+		return f.Return(mod.ns)
+	},
+}
+
+// importingDir is the directory an import call's relative paths resolve
+// against: that of the file the call is written in, which for a call inside a
+// module is the module's, not the running script's.
+func (c *moduleCache) importingDir(callNode rl.Node) string {
+	if callNode != nil {
+		if mod, ok := c.byPath[callNode.Span().File]; ok {
+			return filepath.Dir(mod.path)
+		}
+	}
+	if ScriptDir != "" {
+		return ScriptDir
+	}
+	return "."
+}
+
+// load returns the module at path, running it first if no import has yet. The
+// cycle check comes before the cache since a module is cached as soon as it
+// starts loading.
+//
+// Under `rad test` a module may be a script with args or commands, so a test
+// can reach the functions it defines. Its args are bound from given, and it's
+// run afresh whenever given is, since the args can differ from the last time.
+func (c *moduleCache) load(path string, given *RadMap) (*radModule, error) {
+	if ScriptPath != "" {
+		if running, err := filepath.Abs(ScriptPath); err == nil && running == path {
+			return nil, fmt.Errorf("it's the running script")
+		}
+	}
+	if lo.Contains(c.loading, path) {
+		cycle := append(lo.Drop(c.loading, lo.IndexOf(c.loading, path)), path)
+		return nil, fmt.Errorf("import cycle: %s", strings.Join(lo.Map(cycle, func(p string, _ int) string {
+			return filepath.Base(p)
+		}), " -> "))
+	}
+	if mod, ok := c.byPath[path]; ok && given == nil {
+		return mod, nil
+	}
+
+	src, err := readSource(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parser, err := rts.NewRadParser()
+	if err != nil {
+		return nil, err
+	}
+	tree := parser.Parse(src)
+	// Spans carry the module's path, which is how errors raised in its
+	// functions find its source rather than the importer's.
+	ast, _ := validateSyntaxOf(path, src, tree, parser, nil)
+	if ast == nil {
+		return nil, fmt.Errorf("it doesn't parse")
+	}
+	if (ast.Args != nil || len(ast.Cmds) > 0) && currentTestRun == nil {
+		return nil, fmt.Errorf("a module can't declare args or commands")
+	}
+
+	interp := NewInterpreter(InterpreterInput{Src: src, Tree: tree, ScriptName: path})
+	interp.sd.Ast = ast
+	interp.modules = c
+	interp.InitBuiltIns()
+	if err := interp.stubArgs(extractArgsFromAST(ast.Args, src), given); err != nil {
+		return nil, err
+	}
+
+	mod := &radModule{path: path, src: src, ns: NewRadMap()}
+	c.byPath[path] = mod
+
+	c.loading = append(c.loading, path)
+	interp.safelyExecuteTopLevel(ast)
+	c.loading = c.loading[:len(c.loading)-1]
+
+	for _, stmt := range ast.Stmts {
+		if fnDef, ok := stmt.(*rl.FnDef); ok {
+			if fn, ok := interp.env.GetVar(fnDef.Name); ok {
+				mod.ns.Set(newRadValueStr(fnDef.Name), fn)
+			}
+		}
+	}
+	c.namespaces[mod.ns] = mod
+	return mod, nil
+}
+
+// stubArgs binds the args of a script loaded as a module under `rad test`,
+// where there's no command line to parse them from. Each takes its value from
+// given, else its default, else null (an empty list, if variadic). Values in
+// given must match the arg's type, but constraints and validators aren't run.
+func (i *Interpreter) stubArgs(args []*ScriptArg, given *RadMap) error {
+	i.InitArgs(lo.Map(args, func(arg *ScriptArg, _ int) RadArg { return CreateFlag(arg) }))
+	if given == nil {
+		return nil
+	}
+
+	byName := lo.KeyBy(args, func(arg *ScriptArg) string { return arg.Name })
+	for _, key := range given.Keys() {
+		name := ToPrintableQuoteStr(key, false)
+		arg, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("it has no arg '%s'", name)
+		}
+		val, _ := given.Get(key)
+		typing, err := rts.ParseTypeSpec(arg.Type.TypeName())
+		if err != nil {
+			return err
+		}
+		if !(val.IsNull() && arg.IsNullable) && !typing.IsCompatibleWith(val.ToCompatSubject()) {
+			return fmt.Errorf("arg '%s' should be %s, got %s", name, arg.Type.TypeName(), val.Type().AsString())
+		}
+		i.env.SetVar(name, val)
+	}
+	return nil
+}
+
+// moduleFn resolves `ns.name()` against an import() namespace, reporting
+// whether val is one at all.
+func (i *Interpreter) moduleFn(val RadValue, name string, funcExpr rl.Node) (RadFn, bool) {
+	m, ok := val.TryGetMap()
+	if !ok {
+		return RadFn{}, false
+	}
+	mod, ok := i.modules.namespaces[m]
+	if !ok {
+		return RadFn{}, false
+	}
+	fnVal, exist := m.Get(newRadValueStr(name))
+	if !exist {
+		i.emitErrorf(rl.ErrUnknownFunction, funcExpr, "Module '%s' has no function '%s'", filepath.Base(mod.path), name)
+	}
+	fn, _ := fnVal.TryGetFn()
+	return fn, true
+}
+
+// srcFor returns the source a span points into: an imported module's, when
+// the span is in one, else the running script's.
+func (i *Interpreter) srcFor(span rl.Span) string {
+	if i.tmpSrc == nil {
+		if mod, ok := i.modules.byPath[span.File]; ok {
+			return mod.src
+		}
+	}
+	return i.GetSrc()
+}
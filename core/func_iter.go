@@ -0,0 +1,104 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	com "github.com/amterp/rad/core/common"
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncReadLines = BuiltInFunc{
+	Name: FUNC_READ_LINES,
+	Execute: func(f FuncInvocation) RadValue {
+		pathArg := f.GetArg("_path")
+		if pathArg.IsNull() {
+			if !RIo.StdIn.HasContent() {
+				return f.Return(NewRadIter(func(yield func(RadValue) bool) {}))
+			}
+			return f.Return(newLinesIter(f, func() (io.ReadCloser, error) {
+				return io.NopCloser(RIo.StdIn), nil
+			}))
+		}
+
+		path := com.ExpandTilde(pathArg.RequireStr(f.i, f.callNode).Plain())
+		// checked now so a missing file is an error the caller can catch; the
+		// file itself is only opened once the lines are read
+		if stat, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				return f.Return(NewErrorStr(err.Error()).SetCode(rl.ErrFileNoExist))
+			} else if os.IsPermission(err) {
+				return f.Return(NewErrorStr(err.Error()).SetCode(rl.ErrFileNoPermission))
+			}
+			return f.Return(NewErrorStr(err.Error()).SetCode(rl.ErrFileRead))
+		} else if stat.IsDir() {
+			return f.ReturnErrf(rl.ErrFileRead, "Cannot read lines of %q: path is a directory", NormalizePath(path))
+		}
+
+		return f.Return(newLinesIter(f, func() (io.ReadCloser, error) {
+			return os.Open(path)
+		}))
+	},
+}
+
+// newLinesIter returns an iterator over the lines of what open returns,
+// without their line endings.
+func newLinesIter(f FuncInvocation, open func() (io.ReadCloser, error)) *RadIter {
+	return NewRadIter(func(yield func(RadValue) bool) {
+		reader, err := open()
+		if err != nil {
+			f.i.emitErrorf(rl.ErrFileRead, f.callNode, "Failed to open for reading: %v", err)
+		}
+		defer reader.Close()
+
+		buffered := bufio.NewReader(reader)
+		for {
+			line, err := buffered.ReadString('\n')
+			if line != "" {
+				line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+				if !yield(newRadValueStr(line)) {
+					return
+				}
+			}
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				f.i.emitErrorf(rl.ErrFileRead, f.callNode, "Failed to read line: %v", err)
+			}
+		}
+	})
+}
+
+var FuncTake = BuiltInFunc{
+	Name: FUNC_TAKE,
+	Execute: func(f FuncInvocation) RadValue {
+		items := f.GetArg("_items")
+		n := f.GetInt("_n")
+		if n < 0 {
+			f.i.emitErrorf(rl.ErrNumInvalidRange, f.callNode, "Cannot take a negative number of items: %d", n)
+		}
+
+		out := NewRadList()
+		switch coerced := items.Val.(type) {
+		case *RadList:
+			end := min(n, coerced.Len())
+			out.Values = append(out.Values, coerced.Values[:end]...)
+		case *RadIter:
+			if n == 0 {
+				// don't start the iterator, it may have side effects
+				return f.Return(out)
+			}
+			coerced.Each(f.i, f.callNode, func(val RadValue) bool {
+				out.Append(val)
+				return out.Len() < n
+			})
+		default:
+			f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode,
+				"Cannot %s from a %s, expected a list or iterator", FUNC_TAKE, TypeAsString(items))
+		}
+		return f.Return(out)
+	},
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncMockShell = BuiltInFunc{
+	Name: FUNC_MOCK_SHELL,
+	Execute: func(f FuncInvocation) RadValue {
+		test := requireTestRun(f, FUNC_MOCK_SHELL)
+		test.shellMocks = append(test.shellMocks, shellMock{
+			pattern: compileMockPattern(f, "_pattern"),
+			stdout:  f.GetStr("_stdout").Plain(),
+			stderr:  f.GetStr("stderr").Plain(),
+			code:    int(f.GetInt("code")),
+		})
+		return VOID_SENTINEL
+	},
+}
+
+var FuncMockHttp = BuiltInFunc{
+	Name: FUNC_MOCK_HTTP,
+	Execute: func(f FuncInvocation) RadValue {
+		test := requireTestRun(f, FUNC_MOCK_HTTP)
+		pattern := compileMockPattern(f, "_url")
+
+		var body string
+		bodyArg := f.GetArg("_body")
+		if str, ok := bodyArg.TryGetStr(); ok {
+			body = str.Plain()
+		} else {
+			bytes, err := json.Marshal(RadToJsonType(bodyArg))
+			if err != nil {
+				f.i.emitErrorf(rl.ErrEncodeData, f.callNode, "Cannot mock a response body of %s: %v",
+					TypeAsString(bodyArg), err)
+			}
+			body = string(bytes)
+		}
+
+		headers := make(map[string][]string)
+		if headersArg := f.GetArg("headers"); !headersArg.IsNull() {
+			headersMap := headersArg.RequireMap(f.i, f.callNode)
+			for _, key := range headersMap.Keys() {
+				val, _ := headersMap.Get(key)
+				headers[ToPrintableQuoteStr(key, false)] = []string{ToPrintableQuoteStr(val, false)}
+			}
+		}
+
+		method := ""
+		if methodArg := f.GetArg("method"); !methodArg.IsNull() {
+			method = methodArg.RequireStr(f.i, f.callNode).Plain()
+		}
+
+		status := int(f.GetInt("status"))
+		test.requester.AddResponseMock(pattern, method, NewResponseDef(&status, &headers, &body, nil, 0))
+		return VOID_SENTINEL
+	},
+}
+
+var FuncMockNow = BuiltInFunc{
+	Name: FUNC_MOCK_NOW,
+	Execute: func(f FuncInvocation) RadValue {
+		test := requireTestRun(f, FUNC_MOCK_NOW)
+		arg := f.GetArg("_time")
+		switch coerced := arg.Val.(type) {
+		case RadDatetime:
+			test.clock.NowTime = coerced.Time
+		case RadString:
+			t, errVal := parseDateStr(f, coerced.Plain(), RAD_NULL_VAL, test.clock.Local())
+			if errVal != nil {
+				radErr, _ := errVal.TryGetError()
+				f.i.emitError(rl.ErrParseDate, f.callNode, radErr.Msg().Plain())
+			}
+			test.clock.NowTime = t
+		case int64:
+			test.clock.NowTime = time.Unix(coerced, 0).In(test.clock.Local())
+		case float64:
+			test.clock.NowTime = time.UnixMilli(int64(coerced * 1000)).In(test.clock.Local())
+		default:
+			f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode,
+				"Cannot mock the time with a %s, expected a datetime, a date string or epoch seconds", TypeAsString(arg))
+		}
+		return VOID_SENTINEL
+	},
+}
+
+var FuncMockCalls = BuiltInFunc{
+	Name: FUNC_MOCK_CALLS,
+	Execute: func(f FuncInvocation) RadValue {
+		test := requireTestRun(f, FUNC_MOCK_CALLS)
+		calls := NewRadList()
+		for _, call := range test.calls {
+			calls.Append(newRadValueStr(call))
+		}
+		return f.Return(calls)
+	},
+}
+
+// requireTestRun returns the running test, erroring if there isn't one. The
+// mocks replace the real shell and network, which only a test should do.
+func requireTestRun(f FuncInvocation, name string) *radTestRun {
+	if currentTestRun == nil {
+		f.i.emitErrorf(rl.ErrTestOnly, f.callNode, "Cannot call %s outside of a test run by 'rad test'", name)
+	}
+	return currentTestRun
+}
+
+func compileMockPattern(f FuncInvocation, arg string) *regexp.Regexp {
+	pattern := f.GetStr(arg).Plain()
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		f.i.emitErrorf(rl.ErrInvalidRegex, f.callNode, "Invalid mock pattern %q: %v", pattern, err)
+	}
+	return re
+}
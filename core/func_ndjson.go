@@ -0,0 +1,49 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncParseNdjson = BuiltInFunc{
+	Name: FUNC_PARSE_NDJSON,
+	Execute: func(f FuncInvocation) RadValue {
+		out := NewRadList()
+		for idx, line := range strings.Split(f.GetStr("_str").Plain(), "\n") {
+			// Blank lines, including the one after a trailing newline, aren't records.
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			val, err := TryConvertJsonToNativeTypes(f.i, f.callNode, line)
+			if err != nil {
+				return f.ReturnErrf(rl.ErrParseJson, "Error parsing JSON on line %d: %v", idx+1, err)
+			}
+			val = conformInto(f, fmt.Sprintf("Line %d", idx+1), val)
+			if val.IsError() {
+				return f.Return(val)
+			}
+			out.Append(val)
+		}
+		return f.Return(out)
+	},
+}
+
+var FuncToNdjson = BuiltInFunc{
+	Name: FUNC_TO_NDJSON,
+	Execute: func(f FuncInvocation) RadValue {
+		buf := &bytes.Buffer{}
+		enc := json.NewEncoder(buf)
+		// as in to_json
+		enc.SetEscapeHTML(false)
+		for _, val := range f.GetList("_vals").Values {
+			if err := enc.Encode(RadToJsonType(val)); err != nil {
+				return f.ReturnErrf(rl.ErrInternalBug, "Failed to serialize to JSON: %v", err)
+			}
+		}
+		return f.Return(strings.TrimSuffix(buf.String(), "\n"))
+	},
+}
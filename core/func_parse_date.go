@@ -65,58 +65,75 @@ var FuncParseDate = BuiltInFunc{
 			}
 		}
 
-		if dateStr == "" {
-			return f.Return(NewErrorStr("Cannot parse an empty date string").SetCode(rl.ErrParseDate))
+		parsedTime, errVal := parseDateStr(f, dateStr, formatArg, location)
+		if errVal != nil {
+			return *errVal
 		}
 
-		var parsedTime time.Time
+		return f.Return(NewTimeMap(parsedTime))
+	},
+}
 
-		if !formatArg.IsNull() {
-			// Explicit format: convert tokens to Go layout, parse in target tz
-			format := formatArg.RequireStr(f.i, f.callNode).Plain()
-			if format == "" {
-				return f.Return(NewErrorStr("Cannot parse date with an empty format string").SetCode(rl.ErrParseDate))
-			}
-			goLayout := convertFormatToGoLayout(format)
+// parseDateStr parses dateStr as parse_date does: with formatArg if it's set,
+// otherwise by trying the common layouts. Times without an offset are read in
+// location, and the rest converted to it. A non-nil errVal is ready to return
+// as-is.
+func parseDateStr(f FuncInvocation, dateStr string, formatArg RadValue, location *time.Location) (time.Time, *RadValue) {
+	if dateStr == "" {
+		errVal := f.Return(NewErrorStr("Cannot parse an empty date string").SetCode(rl.ErrParseDate))
+		return time.Time{}, &errVal
+	}
 
-			t, err := time.ParseInLocation(goLayout, dateStr, location)
-			if err != nil {
-				errMsg := fmt.Sprintf("Failed to parse date %q with format %q", dateStr, format)
-				return f.Return(NewErrorStr(errMsg).SetCode(rl.ErrParseDate))
-			}
-			parsedTime = t
-		} else {
-			// Auto-detect: try known unambiguous formats
-			var parsed bool
-			for _, af := range autoDetectFormats {
-				if af.hasTz {
-					t, err := time.Parse(af.layout, dateStr)
-					if err == nil {
-						parsedTime = t.In(location)
-						parsed = true
-						break
-					}
-				} else {
-					t, err := time.ParseInLocation(af.layout, dateStr, location)
-					if err == nil {
-						parsedTime = t
-						parsed = true
-						break
-					}
+	var parsedTime time.Time
+
+	if !formatArg.IsNull() {
+		// Explicit format: convert tokens to Go layout, parse in target tz
+		format := formatArg.RequireStr(f.i, f.callNode).Plain()
+		if format == "" {
+			errVal := f.Return(NewErrorStr("Cannot parse date with an empty format string").SetCode(rl.ErrParseDate))
+			return time.Time{}, &errVal
+		}
+		goLayout := convertFormatToGoLayout(format)
+
+		t, err := time.ParseInLocation(goLayout, dateStr, location)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed to parse date %q with format %q", dateStr, format)
+			errVal := f.Return(NewErrorStr(errMsg).SetCode(rl.ErrParseDate))
+			return time.Time{}, &errVal
+		}
+		parsedTime = t
+	} else {
+		// Auto-detect: try known unambiguous formats
+		var parsed bool
+		for _, af := range autoDetectFormats {
+			if af.hasTz {
+				t, err := time.Parse(af.layout, dateStr)
+				if err == nil {
+					parsedTime = t.In(location)
+					parsed = true
+					break
+				}
+			} else {
+				t, err := time.ParseInLocation(af.layout, dateStr, location)
+				if err == nil {
+					parsedTime = t
+					parsed = true
+					break
 				}
 			}
+		}
 
-			if !parsed {
-				errMsg := fmt.Sprintf(
-					"Failed to parse date %q. Supported formats: YYYY-MM-DD, YYYY-MM-DDTHH:mm:ss, "+
-						"YYYY-MM-DD HH:mm:ss (with optional timezone offset and fractional seconds). "+
-						"Use 'format' to specify a custom format, e.g. parse_date(%q, format=\"DD/MM/YYYY\").",
-					dateStr, dateStr,
-				)
-				return f.Return(NewErrorStr(errMsg).SetCode(rl.ErrParseDate))
-			}
+		if !parsed {
+			errMsg := fmt.Sprintf(
+				"Failed to parse date %q. Supported formats: YYYY-MM-DD, YYYY-MM-DDTHH:mm:ss, "+
+					"YYYY-MM-DD HH:mm:ss (with optional timezone offset and fractional seconds). "+
+					"Use 'format' to specify a custom format, e.g. parse_date(%q, format=\"DD/MM/YYYY\").",
+				dateStr, dateStr,
+			)
+			errVal := f.Return(NewErrorStr(errMsg).SetCode(rl.ErrParseDate))
+			return time.Time{}, &errVal
 		}
+	}
 
-		return f.Return(NewTimeMap(parsedTime))
-	},
+	return parsedTime, nil
 }
@@ -0,0 +1,336 @@
+package core
+
+import (
+	"regexp"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/rl"
+)
+
+var patternIdentRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type patternKind int
+
+const (
+	patternType patternKind = iota
+	patternRange
+	patternBind
+	patternRest
+	patternRegex
+	patternGuard
+)
+
+// pattern is what a matcher made by of_type(), between(), bind(), rest(),
+// regex() or where() matches. A switch case holding one matches structurally
+// instead of by equality, and a list or map literal holding one becomes a
+// pattern for its whole shape.
+type pattern struct {
+	kind     patternKind
+	typ      rl.TypingT     // patternType
+	min, max float64        // patternRange
+	name     string         // patternBind, patternRest; "" binds nothing
+	inner    *RadValue      // patternBind, patternGuard; nil matches anything
+	re       *regexp.Regexp // patternRegex
+	guard    RadFn          // patternGuard
+}
+
+// matcherTyping is the signature every matcher shares: fn(any?) -> bool.
+var matcherTyping = func() *rl.TypingFnT {
+	var valT rl.TypingT = rl.NewOptionalType(rl.NewAnyType())
+	var retT rl.TypingT = rl.NewBoolType()
+	return &rl.TypingFnT{Params: []rl.TypingFnParam{{Name: "_val", Type: &valT}}, ReturnT: &retT}
+}()
+
+// newPatternFn wraps p as a function value. Called directly, a matcher is a
+// predicate, so it can also be handed to filter() and friends.
+func newPatternFn(name string, p *pattern) RadValue {
+	fn := NewBuiltIn(BuiltInFunc{
+		Name:      name,
+		Signature: &rts.FnSignature{Name: name, Typing: matcherTyping},
+		Execute: func(f FuncInvocation) RadValue {
+			m := patternMatcher{i: f.i, node: f.callNode}
+			return f.Return(m.matchMatcher(p, f.GetArg("_val")))
+		},
+	})
+	fn.Pattern = p
+	return newRadValueFn(fn)
+}
+
+var FuncOfType = BuiltInFunc{
+	Name: FUNC_OF_TYPE,
+	Execute: func(f FuncInvocation) RadValue {
+		spec := f.GetStr("_type").Plain()
+		typ, err := rts.ParseTypeSpec(spec)
+		if err != nil {
+			f.i.emitErrorf(rl.ErrInvalidPattern, f.callNode, "Invalid pattern: %v", err)
+		}
+		return newPatternFn(FUNC_OF_TYPE, &pattern{kind: patternType, typ: typ})
+	},
+}
+
+var FuncBetween = BuiltInFunc{
+	Name: FUNC_BETWEEN,
+	Execute: func(f FuncInvocation) RadValue {
+		lo := f.GetFloat("_min")
+		hi := f.GetFloat("_max")
+		if lo > hi {
+			f.i.emitErrorf(rl.ErrInvalidPattern, f.callNode,
+				"Invalid pattern: min (%s) is greater than max (%s)",
+				ToPrintable(f.GetArg("_min")), ToPrintable(f.GetArg("_max")))
+		}
+		return newPatternFn(FUNC_BETWEEN, &pattern{kind: patternRange, min: lo, max: hi})
+	},
+}
+
+var FuncBind = BuiltInFunc{
+	Name: FUNC_BIND,
+	Execute: func(f FuncInvocation) RadValue {
+		name := requirePatternName(f, f.GetStr("_name").Plain())
+		p := &pattern{kind: patternBind, name: name}
+		if inner := f.GetArg("_pattern"); inner.Type() != rl.RadNullT {
+			p.inner = &inner
+		}
+		return newPatternFn(FUNC_BIND, p)
+	},
+}
+
+var FuncRest = BuiltInFunc{
+	Name: FUNC_REST,
+	Execute: func(f FuncInvocation) RadValue {
+		p := &pattern{kind: patternRest}
+		if name, ok := f.GetArg("_name").TryGetStr(); ok {
+			p.name = requirePatternName(f, name.Plain())
+		}
+		return newPatternFn(FUNC_REST, p)
+	},
+}
+
+var FuncRegex = BuiltInFunc{
+	Name: FUNC_REGEX,
+	Execute: func(f FuncInvocation) RadValue {
+		expr := f.GetStr("_pattern").Plain()
+		if !f.GetBool("partial") {
+			// anchored the same way matches() does, so cat|dog stays whole-string
+			expr = "^(?:" + expr + ")$"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			f.i.emitErrorf(rl.ErrInvalidRegex, f.callNode, "Error compiling regex pattern: %s", err)
+		}
+		// Only named groups bind, so an unnamed one would capture into
+		// nothing. The anchoring group above doesn't capture.
+		for idx, name := range re.SubexpNames() {
+			if idx > 0 && name == "" {
+				f.i.emitErrorf(rl.ErrInvalidPattern, f.callNode,
+					"Invalid pattern: regex group %d has no name to bind it to; name it (?P<name>...) or make it non-capturing (?:...)",
+					idx)
+			}
+		}
+		return newPatternFn(FUNC_REGEX, &pattern{kind: patternRegex, re: re})
+	},
+}
+
+var FuncWhere = BuiltInFunc{
+	Name: FUNC_WHERE,
+	Execute: func(f FuncInvocation) RadValue {
+		inner := f.GetArg("_pattern")
+		return newPatternFn(FUNC_WHERE, &pattern{kind: patternGuard, inner: &inner, guard: f.GetFn("_guard")})
+	},
+}
+
+func requirePatternName(f FuncInvocation, name string) string {
+	if !patternIdentRegex.MatchString(name) {
+		f.i.emitErrorf(rl.ErrInvalidPattern, f.callNode, "Invalid pattern: %q isn't a valid variable name", name)
+	}
+	return name
+}
+
+// isPattern reports whether a case key matches structurally: a matcher, a
+// record constructor, or a list or map with one somewhere inside it.
+func isPattern(v RadValue) bool {
+	if fn, ok := v.TryGetFn(); ok {
+		if fn.Pattern != nil {
+			return true
+		}
+		_, isRecord := recordTypeOf(fn)
+		return isRecord
+	}
+	if list, ok := v.TryGetList(); ok {
+		for _, elem := range list.Values {
+			if isPattern(elem) {
+				return true
+			}
+		}
+		return false
+	}
+	if m, ok := v.TryGetMap(); ok {
+		for _, elem := range m.Values() {
+			if isPattern(elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// patternBinding is a variable a successful match assigns.
+type patternBinding struct {
+	name string
+	val  RadValue
+}
+
+// matchPattern matches val against pat, returning the variables the match
+// binds, in the order the pattern names them. Anything that isn't a pattern
+// matches by equality, so literals mix freely with matchers inside lists and
+// maps.
+func (i *Interpreter) matchPattern(node rl.Node, pat, val RadValue) (bool, []patternBinding) {
+	m := patternMatcher{i: i, node: node}
+	if !m.match(pat, val) {
+		return false, nil
+	}
+	return true, m.binds
+}
+
+type patternMatcher struct {
+	i     *Interpreter
+	node  rl.Node
+	binds []patternBinding
+}
+
+func (m *patternMatcher) match(pat, val RadValue) bool {
+	if fn, ok := pat.TryGetFn(); ok {
+		if fn.Pattern != nil {
+			return m.matchMatcher(fn.Pattern, val)
+		}
+		if typing, ok := recordTypeOf(fn); ok {
+			_, err := conformRecord(m.i, typing, val)
+			return err == nil
+		}
+	}
+	if !isPattern(pat) {
+		return pat.Equals(val)
+	}
+	if list, ok := pat.TryGetList(); ok {
+		return m.matchList(list, val)
+	}
+	return m.matchMap(pat.RequireMap(m.i, m.node), val)
+}
+
+func (m *patternMatcher) matchMatcher(p *pattern, val RadValue) bool {
+	switch p.kind {
+	case patternType:
+		return p.typ.IsCompatibleWith(val.ToCompatSubject())
+	case patternRange:
+		if val.Type() != rl.RadIntT && val.Type() != rl.RadFloatT {
+			return false
+		}
+		n, _ := val.TryGetFloatAllowingInt()
+		return n >= p.min && n <= p.max
+	case patternBind:
+		if p.inner != nil && !m.match(*p.inner, val) {
+			return false
+		}
+		m.bind(p.name, val)
+		return true
+	case patternRest:
+		// Only meaningful as a list's last element, where matchList takes it.
+		m.i.emitErrorf(rl.ErrInvalidPattern, m.node, "Invalid pattern: %s() must be the last element of a list", FUNC_REST)
+	case patternRegex:
+		str, ok := val.TryGetStr()
+		if !ok {
+			return false
+		}
+		groups := p.re.FindStringSubmatch(str.Plain())
+		if groups == nil {
+			return false
+		}
+		for idx, name := range p.re.SubexpNames() {
+			if name != "" {
+				m.bind(name, newRadValueStr(groups[idx]))
+			}
+		}
+		return true
+	case patternGuard:
+		if !m.match(*p.inner, val) {
+			return false
+		}
+		return m.runGuard(p.guard)
+	}
+	return false
+}
+
+func (m *patternMatcher) matchList(pat *RadList, val RadValue) bool {
+	list, ok := val.TryGetList()
+	if !ok {
+		return false
+	}
+	elems := pat.Values
+	var rest *pattern
+	if n := len(elems); n > 0 {
+		if fn, ok := elems[n-1].TryGetFn(); ok && fn.Pattern != nil && fn.Pattern.kind == patternRest {
+			rest = fn.Pattern
+			elems = elems[:n-1]
+		}
+	}
+
+	if rest == nil && len(list.Values) != len(elems) || len(list.Values) < len(elems) {
+		return false
+	}
+	for idx, elem := range elems {
+		if !m.match(elem, list.Values[idx]) {
+			return false
+		}
+	}
+	if rest != nil {
+		tail := NewRadList()
+		for _, v := range list.Values[len(elems):] {
+			tail.Append(v)
+		}
+		m.bind(rest.name, newRadValueList(tail))
+	}
+	return true
+}
+
+// matchMap matches maps holding at least the pattern's keys; others are
+// ignored, so a pattern names only the parts of a response it cares about.
+func (m *patternMatcher) matchMap(pat *RadMap, val RadValue) bool {
+	target, ok := val.TryGetMap()
+	if !ok {
+		return false
+	}
+	for _, key := range pat.Keys() {
+		actual, exists := target.Get(key)
+		if !exists {
+			return false
+		}
+		expected, _ := pat.Get(key)
+		if !m.match(expected, actual) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *patternMatcher) bind(name string, val RadValue) {
+	if name != "" {
+		m.binds = append(m.binds, patternBinding{name: name, val: val})
+	}
+}
+
+// runGuard calls a where() guard with the bindings so far in scope. They're
+// set in a frame of the guard's own, so a failed match leaves nothing behind.
+func (m *patternMatcher) runGuard(guard RadFn) bool {
+	if !guard.IsBuiltIn() {
+		parent := m.i.env
+		if guard.Env != nil {
+			parent = guard.Env
+		}
+		frame := parent.NewChildEnv()
+		for _, b := range m.binds {
+			frame.SetVar(b.name, b.val)
+		}
+		guard.Env = &frame
+	}
+	out := guard.Execute(NewFnInvocation(m.i, m.node, guard.Name(), []PosArg{}, NO_NAMED_ARGS_INPUT, guard.IsBuiltIn()))
+	return out.RequireBool(m.i, m.node)
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncRecord = BuiltInFunc{
+	Name: FUNC_RECORD,
+	Execute: func(f FuncInvocation) RadValue {
+		name := f.GetStr("_name").Plain()
+		fieldsMap := f.GetMap("_fields")
+
+		fields := make([]rts.RecordField, 0, int(fieldsMap.Len()))
+		for _, key := range fieldsMap.Keys() {
+			spec, _ := fieldsMap.Get(key)
+			fields = append(fields, rts.RecordField{
+				Name: key.RequireStr(f.i, f.callNode).Plain(),
+				Spec: spec.RequireStr(f.i, f.callNode).Plain(),
+			})
+		}
+
+		typing, err := rts.NewRecordTyping(name, fields)
+		if err != nil {
+			f.i.emitErrorf(rl.ErrInvalidRecord, f.callNode, "Invalid record '%s': %v", name, err)
+		}
+
+		constructor := NewBuiltIn(BuiltInFunc{
+			Name:      name,
+			Signature: &rts.FnSignature{Name: name, Typing: typing},
+			// Arguments arrive bound and type-checked against the signature,
+			// defaults and all, so building the map is all that's left.
+			Execute: func(f FuncInvocation) RadValue {
+				out := NewRadMap()
+				for _, param := range typing.Params {
+					out.Set(newRadValueStr(param.Name), f.GetArg(param.Name))
+				}
+				return f.Return(out)
+			},
+		})
+		// The constructor's signature is also the record's definition:
+		// parse_json(into=...) is handed the constructor and validates
+		// against the fields it takes.
+		constructor.Record = typing
+		return f.Return(constructor)
+	},
+}
+
+// recordTypeOf returns the record a constructor builds, if fn is one.
+func recordTypeOf(fn RadFn) (*rl.TypingFnT, bool) {
+	return fn.Record, fn.Record != nil
+}
+
+// conformRecord checks data from outside the script, e.g. decoded JSON,
+// against a record's fields and returns the record it describes. A field the
+// data leaves out takes its default, or null if its type allows one. Unlike a
+// constructor call, a mismatch is the data's fault rather than the script's,
+// so it's reported as an error for the caller to handle.
+func conformRecord(i *Interpreter, typing *rl.TypingFnT, val RadValue) (*RadMap, error) {
+	m, ok := val.TryGetMap()
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %s", val.Type().AsString())
+	}
+
+	byName := typing.ByName()
+	for _, key := range m.Keys() {
+		name := ToPrintableQuoteStr(key, false)
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("unknown field '%s'", name)
+		}
+	}
+
+	out := NewRadMap()
+	for _, param := range typing.Params {
+		fieldVal, exists := m.Get(newRadValueStr(param.Name))
+		switch {
+		case exists:
+			if !(*param.Type).IsCompatibleWith(fieldVal.ToCompatSubject()) {
+				return nil, fmt.Errorf("field '%s' should be %s, got %s",
+					param.Name, (*param.Type).Name(), fieldVal.Type().AsString())
+			}
+		case param.DefaultAST != nil:
+			i.WithTmpSrc(param.DefaultAST.Src, func() {
+				fieldVal = i.eval(param.DefaultAST.Node).Val
+			})
+		case (*param.Type).IsCompatibleWith(rl.NewNullSubject()):
+			fieldVal = RAD_NULL_VAL
+		default:
+			return nil, fmt.Errorf("missing field '%s'", param.Name)
+		}
+		out.Set(newRadValueStr(param.Name), fieldVal)
+	}
+	return out, nil
+}
+
+// intoRecord returns the record a parse function's 'into' argument names, or
+// nil if it wasn't given.
+func intoRecord(f FuncInvocation) *rl.TypingFnT {
+	into := f.GetArg("into")
+	if into.IsNull() {
+		return nil
+	}
+	typing, ok := recordTypeOf(into.RequireFn(f.i, f.callNode))
+	if !ok {
+		f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode, "'into' must be a record, made with %s()", FUNC_RECORD)
+	}
+	return typing
+}
+
+// conformInto applies a parse function's 'into' argument to data it decoded
+// from format, returning the data as-is if there's no record to conform to,
+// or an error value if the data doesn't fit.
+func conformInto(f FuncInvocation, format string, out RadValue) RadValue {
+	typing := intoRecord(f)
+	if typing == nil {
+		return out
+	}
+	rec, err := conformRecord(f.i, typing, out)
+	if err != nil {
+		return newRadValue(f.i, f.callNode, NewErrorStrf("%s doesn't match %s: %v", format, typing.FnName, err).
+			SetCode(rl.ErrRecordMismatch).SetSpan(nodeSpanPtr(f.callNode)))
+	}
+	return newRadValueMap(rec)
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	com "github.com/amterp/rad/core/common"
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncRender = BuiltInFunc{
+	Name: FUNC_RENDER,
+	Execute: func(f FuncInvocation) RadValue {
+		out, err := renderTemplate(f, f.GetStr("_template").Plain())
+		if err != nil {
+			return f.ReturnErrf(rl.ErrInvalidTemplate, "Invalid template: %v", err)
+		}
+		return f.Return(out)
+	},
+}
+
+var FuncRenderFile = BuiltInFunc{
+	Name: FUNC_RENDER_FILE,
+	Execute: func(f FuncInvocation) RadValue {
+		path := com.ExpandTilde(f.GetStr("_path").Plain())
+		src, err := readSource(path)
+		if err != nil {
+			return f.Return(fileReadError(err))
+		}
+
+		out, err := renderTemplate(f, src)
+		if err != nil {
+			return f.ReturnErrf(rl.ErrInvalidTemplate, "Invalid template %q: %v", NormalizePath(path), err)
+		}
+		return f.Return(out)
+	},
+}
+
+var FuncRenderDir = BuiltInFunc{
+	Name: FUNC_RENDER_DIR,
+	Execute: func(f FuncInvocation) RadValue {
+		srcDir := com.ExpandTilde(f.GetStr("_src").Plain())
+		destDir := com.ExpandTilde(f.GetStr("_dest").Plain())
+		overwrite := f.GetBool("overwrite")
+
+		if stat, err := os.Stat(srcDir); err != nil {
+			return f.Return(fileReadError(err))
+		} else if !stat.IsDir() {
+			return f.ReturnErrf(rl.ErrFileRead, "Cannot render %q: not a directory", NormalizePath(srcDir))
+		}
+
+		type renderedFile struct {
+			path    string
+			content string
+			mode    fs.FileMode
+		}
+		var dirs []string
+		var files []renderedFile
+
+		// Everything is rendered before anything is written, so a bad
+		// template or a clash leaves the destination untouched.
+		err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path == srcDir {
+				return nil
+			}
+			rel, err := filepath.Rel(srcDir, path)
+			if err != nil {
+				return err
+			}
+
+			// names are templates too; one that renders empty, or with an
+			// empty directory in it, is left out along with what's under it
+			renderedRel, err := renderTemplate(f, filepath.ToSlash(rel))
+			if err != nil {
+				return &templateError{path: rel, err: err}
+			}
+			if renderedRel == "" || strings.Contains("/"+renderedRel+"/", "//") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			dest := filepath.Join(destDir, filepath.FromSlash(renderedRel))
+			// a name can render to `..` and climb out; nothing lands outside dest
+			if destRel, err := filepath.Rel(destDir, dest); err != nil || destRel == ".." ||
+				strings.HasPrefix(destRel, ".."+string(filepath.Separator)) || filepath.IsAbs(destRel) {
+				return &outsideDestError{path: rel, dest: dest}
+			}
+
+			if d.IsDir() {
+				dirs = append(dirs, dest)
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			src, err := readSource(path)
+			if err != nil {
+				return err
+			}
+			content, err := renderTemplate(f, src)
+			if err != nil {
+				return &templateError{path: rel, err: err}
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			files = append(files, renderedFile{path: dest, content: content, mode: info.Mode().Perm()})
+			return nil
+		})
+		if tplErr, ok := err.(*templateError); ok {
+			return f.ReturnErrf(rl.ErrInvalidTemplate, "Invalid template %q: %v", NormalizePath(tplErr.path), tplErr.err)
+		} else if outErr, ok := err.(*outsideDestError); ok {
+			return f.ReturnErrf(rl.ErrFileWrite, "Cannot render %q to %q: it's outside %q",
+				NormalizePath(outErr.path), NormalizePath(outErr.dest), NormalizePath(destDir))
+		} else if err != nil {
+			return f.Return(fileReadError(err))
+		}
+
+		if !overwrite {
+			for _, file := range files {
+				if _, err := os.Stat(file.path); err == nil {
+					return f.ReturnErrf(rl.ErrFileWrite,
+						"Cannot render to %q: file already exists (use overwrite=true to replace it)", NormalizePath(file.path))
+				}
+			}
+		}
+
+		// 0755 matches Rad's convention for internally created directories.
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			return f.Return(fileWriteError(err))
+		}
+		for _, dir := range dirs {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return f.Return(fileWriteError(err))
+			}
+		}
+		written := NewRadList()
+		for _, file := range files {
+			if err := os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+				return f.Return(fileWriteError(err))
+			}
+			if err := os.WriteFile(file.path, []byte(file.content), file.mode); err != nil {
+				return f.Return(fileWriteError(err))
+			}
+			written.Append(newRadValueStr(NormalizePath(file.path)))
+		}
+		return f.Return(written)
+	},
+}
+
+// templateError is a template that failed to compile or render, found
+// while walking a directory.
+type templateError struct {
+	path string
+	err  error
+}
+
+func (e *templateError) Error() string {
+	return e.path + ": " + e.err.Error()
+}
+
+// outsideDestError is a name that rendered to a path outside the
+// destination directory.
+type outsideDestError struct {
+	path string
+	dest string
+}
+
+func (e *outsideDestError) Error() string {
+	return e.path + ": renders outside the destination, to " + e.dest
+}
+
+// renderTemplate compiles src and renders it against the call's `_data`.
+func renderTemplate(f FuncInvocation, src string) (string, error) {
+	tpl, err := compileTemplate(src)
+	if err != nil {
+		return "", err
+	}
+	vars := NewRadMap()
+	if data := f.GetArg("_data"); !data.IsNull() {
+		vars = data.RequireMap(f.i, f.callNode)
+	}
+	return tpl.render(f.i, vars)
+}
+
+func fileReadError(err error) *RadError {
+	if os.IsNotExist(err) {
+		return NewErrorStr(err.Error()).SetCode(rl.ErrFileNoExist)
+	} else if os.IsPermission(err) {
+		return NewErrorStr(err.Error()).SetCode(rl.ErrFileNoPermission)
+	}
+	return NewErrorStr(err.Error()).SetCode(rl.ErrFileRead)
+}
+
+func fileWriteError(err error) *RadError {
+	if os.IsPermission(err) {
+		return NewErrorStr(err.Error()).SetCode(rl.ErrFileNoPermission)
+	}
+	return NewErrorStr(err.Error()).SetCode(rl.ErrFileWrite)
+}
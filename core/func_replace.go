@@ -12,9 +12,29 @@ var FuncReplace = BuiltInFunc{
 	Execute: func(f FuncInvocation) RadValue {
 		original := f.GetStr("_original").Plain()
 		find := f.GetStr("_find").Plain()
-		replace := f.GetStr("_replace").Plain()
+		replaceArg := f.GetArg("_replace")
+		isRegex := f.GetBool("regex")
 
-		if !f.GetBool("regex") {
+		if fn, ok := replaceArg.TryGetFn(); ok {
+			pattern := find
+			if !isRegex {
+				pattern = regexp.QuoteMeta(find)
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return f.ReturnErrf(rl.ErrInvalidRegex, "Error compiling regex pattern: %s", err)
+			}
+			offsets := newRuneOffsets(original)
+			return f.Return(replaceAllMatches(re, original, func(match []int) string {
+				arg := newRadValueMap(newMatchMap(re, original, match, offsets))
+				out := fn.Execute(NewFnInvocation(f.i, f.callNode, fn.Name(),
+					NewPosArgs(NewPosArg(f.callNode, arg)), NO_NAMED_ARGS_INPUT, fn.IsBuiltIn()))
+				return ToPrintableQuoteStr(out, false)
+			}))
+		}
+
+		replace := replaceArg.RequireStr(f.i, f.callNode).Plain()
+		if !isRegex {
 			return f.Return(strings.ReplaceAll(original, find, replace))
 		}
 
@@ -38,19 +58,8 @@ var FuncReplace = BuiltInFunc{
 // empty. Taking the group offsets from the original match is both correct and
 // one pass cheaper.
 func replaceAllExpanding(re *regexp.Regexp, original, template string) string {
-	matches := re.FindAllStringSubmatchIndex(original, -1)
-	if matches == nil {
-		return original
-	}
-
-	var sb strings.Builder
-	sb.Grow(len(original))
-
 	submatches := make([]string, 0, re.NumSubexp()+1)
-	end := 0
-	for _, match := range matches {
-		sb.WriteString(original[end:match[0]])
-
+	return replaceAllMatches(re, original, func(match []int) string {
 		submatches = submatches[:0]
 		for g := 0; g < len(match)/2; g++ {
 			start, stop := match[2*g], match[2*g+1]
@@ -61,8 +70,25 @@ func replaceAllExpanding(re *regexp.Regexp, original, template string) string {
 				submatches = append(submatches, original[start:stop])
 			}
 		}
+		return expandGroupRefs(template, submatches)
+	})
+}
+
+// replaceAllMatches rewrites every match of re in original with what
+// replacement returns for it, given the match's submatch byte offsets.
+func replaceAllMatches(re *regexp.Regexp, original string, replacement func(match []int) string) string {
+	matches := re.FindAllStringSubmatchIndex(original, -1)
+	if matches == nil {
+		return original
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(original))
 
-		sb.WriteString(expandGroupRefs(template, submatches))
+	end := 0
+	for _, match := range matches {
+		sb.WriteString(original[end:match[0]])
+		sb.WriteString(replacement(match))
 		end = match[1]
 	}
 	sb.WriteString(original[end:])
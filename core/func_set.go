@@ -0,0 +1,42 @@
+package core
+
+import (
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncSet = BuiltInFunc{
+	Name: FUNC_SET,
+	Execute: func(f FuncInvocation) RadValue {
+		out := NewRadSet()
+		items := f.GetArg("_items")
+		if items.IsNull() {
+			return f.Return(out)
+		}
+		for _, item := range items.RequireList(f.i, f.callNode).Values {
+			out.AddChecked(f.i, f.callNode, item)
+		}
+		return f.Return(out)
+	},
+}
+
+var FuncIntersect = BuiltInFunc{
+	Name: FUNC_INTERSECT,
+	Execute: func(f FuncInvocation) RadValue {
+		out := requireSet(f, f.GetArg("_first"))
+		for _, other := range f.GetList("_others").Values {
+			out = out.Intersect(requireSet(f, other))
+		}
+		return f.Return(out)
+	},
+}
+
+// requireSet returns val, an argument the signature can only declare as
+// `any`, as a set.
+func requireSet(f FuncInvocation, val RadValue) *RadSet {
+	set, ok := val.TryGetSet()
+	if !ok {
+		f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode,
+			"Expected a set, made with %s(), got %s", FUNC_SET, TypeAsString(val))
+	}
+	return set
+}
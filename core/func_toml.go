@@ -0,0 +1,102 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/amterp/rad/rts/rl"
+)
+
+var FuncParseToml = BuiltInFunc{
+	Name: FUNC_PARSE_TOML,
+	Execute: func(f FuncInvocation) RadValue {
+		var doc map[string]interface{}
+		if _, err := toml.Decode(f.GetStr("_str").Plain(), &doc); err != nil {
+			return f.ReturnErrf(rl.ErrParseData, "Error parsing TOML: %v", err)
+		}
+		return f.Return(conformInto(f, "TOML", ConvertToNativeTypes(f.i, f.callNode, plainTomlTimes(doc))))
+	},
+}
+
+// plainTomlTimes replaces the datetimes in a decoded TOML document with
+// strings written the way the document wrote them, since Rad has no datetime
+// type: local dates stay "2024-05-01", local times "07:30:00", and so on.
+func plainTomlTimes(val interface{}) interface{} {
+	switch coerced := val.(type) {
+	case time.Time:
+		// BurntSushi marks local datetimes with these zones; anything else
+		// carried an offset.
+		switch coerced.Location().String() {
+		case "datetime-local":
+			return coerced.Format("2006-01-02T15:04:05.999999999")
+		case "date-local":
+			return coerced.Format("2006-01-02")
+		case "time-local":
+			return coerced.Format("15:04:05.999999999")
+		}
+		return coerced.Format(time.RFC3339Nano)
+	case map[string]interface{}:
+		for key, elem := range coerced {
+			coerced[key] = plainTomlTimes(elem)
+		}
+	case []interface{}:
+		for idx, elem := range coerced {
+			coerced[idx] = plainTomlTimes(elem)
+		}
+	case []map[string]interface{}:
+		for _, elem := range coerced {
+			plainTomlTimes(elem)
+		}
+	}
+	return val
+}
+
+var FuncToToml = BuiltInFunc{
+	Name: FUNC_TO_TOML,
+	Execute: func(f FuncInvocation) RadValue {
+		val := f.GetArg("_val")
+		// TOML has no null, and the encoder would silently drop one. Better
+		// to say so than write a file that's missing keys.
+		if path, ok := findNull(val, ""); ok {
+			return f.ReturnErrf(rl.ErrEncodeData, "Can't write null to TOML, found at '%s'", path)
+		}
+
+		buf := &bytes.Buffer{}
+		enc := toml.NewEncoder(buf)
+		enc.Indent = ""
+		if err := enc.Encode(RadToJsonType(val)); err != nil {
+			return f.ReturnErrf(rl.ErrEncodeData, "Failed to serialize to TOML: %v", err)
+		}
+
+		return f.Return(strings.TrimSuffix(buf.String(), "\n"))
+	},
+}
+
+// findNull returns the path to the first null inside val, e.g. "deps.serde[1]".
+func findNull(val RadValue, path string) (string, bool) {
+	switch coerced := val.Val.(type) {
+	case RadNull:
+		return path, true
+	case *RadList:
+		for idx, elem := range coerced.Values {
+			if found, ok := findNull(elem, fmt.Sprintf("%s[%d]", path, idx)); ok {
+				return found, true
+			}
+		}
+	case *RadMap:
+		for _, key := range coerced.Keys() {
+			elem, _ := coerced.Get(key)
+			elemPath := ToPrintableQuoteStr(key, false)
+			if path != "" {
+				elemPath = path + "." + elemPath
+			}
+			if found, ok := findNull(elem, elemPath); ok {
+				return found, true
+			}
+		}
+	}
+	return "", false
+}
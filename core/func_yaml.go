@@ -0,0 +1,102 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/amterp/rad/rts/rl"
+	"gopkg.in/yaml.v3"
+)
+
+var FuncParseYaml = BuiltInFunc{
+	Name: FUNC_PARSE_YAML,
+	Execute: func(f FuncInvocation) RadValue {
+		docs, err := decodeYamlDocs(f.GetStr("_str").Plain())
+		if err != nil {
+			return f.ReturnErrf(rl.ErrParseData, "Error parsing YAML: %v", err)
+		}
+
+		if !f.GetBool("all") {
+			if len(docs) == 0 {
+				return f.Return(conformInto(f, "YAML", RAD_NULL_VAL))
+			}
+			return f.Return(conformInto(f, "YAML", ConvertToNativeTypes(f.i, f.callNode, docs[0])))
+		}
+
+		out := NewRadList()
+		for _, doc := range docs {
+			val := conformInto(f, "YAML", ConvertToNativeTypes(f.i, f.callNode, doc))
+			if val.IsError() {
+				return f.Return(val)
+			}
+			out.Append(val)
+		}
+		return f.Return(out)
+	},
+}
+
+// decodeYamlDocs decodes every document in a YAML stream, in order.
+func decodeYamlDocs(s string) ([]interface{}, error) {
+	dec := yaml.NewDecoder(strings.NewReader(s))
+	docs := make([]interface{}, 0)
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		plainYamlTimestamps(&node)
+		var doc interface{}
+		if err := node.Decode(&doc); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// plainYamlTimestamps retags unquoted timestamps, e.g. `date: 2024-05-01`, as
+// strings. Rad has no datetime type, and decoding them as strings keeps them
+// as written rather than as a formatted time.Time.
+func plainYamlTimestamps(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!timestamp" {
+		node.Tag = "!!str"
+	}
+	for _, child := range node.Content {
+		plainYamlTimestamps(child)
+	}
+}
+
+var FuncToYaml = BuiltInFunc{
+	Name: FUNC_TO_YAML,
+	Execute: func(f FuncInvocation) RadValue {
+		indent := f.GetInt("indent")
+		if indent < 1 {
+			return f.ReturnErrf(rl.ErrNumInvalidRange, "Indent must be positive, got %d", indent)
+		}
+
+		val := f.GetArg("_val")
+		docs := []RadValue{val}
+		if f.GetBool("all") {
+			docs = val.RequireList(f.i, f.callNode).Values
+		}
+
+		buf := &bytes.Buffer{}
+		enc := yaml.NewEncoder(buf)
+		enc.SetIndent(int(indent))
+		for _, doc := range docs {
+			if err := enc.Encode(RadToJsonType(doc)); err != nil {
+				return f.ReturnErrf(rl.ErrInternalBug, "Failed to serialize to YAML: %v", err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			return f.ReturnErrf(rl.ErrInternalBug, "Failed to serialize to YAML: %v", err)
+		}
+
+		return f.Return(strings.TrimSuffix(buf.String(), "\n"))
+	},
+}
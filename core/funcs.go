@@ -37,6 +37,7 @@ const (
 	FUNC_PRINT              = "print"
 	FUNC_PRINT_ERR          = "print_err"
 	FUNC_PPRINT             = "pprint"
+	FUNC_EMIT               = "emit"
 	FUNC_DEBUG              = "debug"
 	FUNC_EXIT               = "exit"
 	FUNC_SLEEP              = "sleep"
@@ -123,6 +124,43 @@ const (
 	FUNC_UUID_V7            = "uuid_v7"
 	FUNC_GEN_FID            = "gen_fid"
 	FUNC_GET_RAD_HOME       = "get_rad_home"
+	FUNC_IMPORT             = "import"
+	FUNC_RECORD             = "record"
+	FUNC_OF_TYPE            = "of_type"
+	FUNC_BETWEEN            = "between"
+	FUNC_BIND               = "bind"
+	FUNC_BREAKPOINT         = "breakpoint"
+	FUNC_REST               = "rest"
+	FUNC_REGEX              = "regex"
+	FUNC_WHERE              = "where"
+	FUNC_FIND               = "find"
+	FUNC_FIND_ALL           = "find_all"
+	FUNC_PARSE_YAML         = "parse_yaml"
+	FUNC_TO_YAML            = "to_yaml"
+	FUNC_PARSE_TOML         = "parse_toml"
+	FUNC_TO_TOML            = "to_toml"
+	FUNC_PARSE_CSV          = "parse_csv"
+	FUNC_TO_CSV             = "to_csv"
+	FUNC_PARSE_NDJSON       = "parse_ndjson"
+	FUNC_TO_NDJSON          = "to_ndjson"
+	FUNC_DATETIME           = "datetime"
+	FUNC_DURATION           = "duration"
+	FUNC_IN_TZ              = "in_tz"
+	FUNC_START_OF           = "start_of"
+	FUNC_SET                = "set"
+	FUNC_INTERSECT          = "intersect"
+	FUNC_READ_LINES         = "read_lines"
+	FUNC_TAKE               = "take"
+	FUNC_RENDER             = "render"
+	FUNC_RENDER_FILE        = "render_file"
+	FUNC_RENDER_DIR         = "render_dir"
+	FUNC_ASSERT             = "assert"
+	FUNC_ASSERT_EQ          = "assert_eq"
+	FUNC_ASSERT_NE          = "assert_ne"
+	FUNC_MOCK_SHELL         = "mock_shell"
+	FUNC_MOCK_HTTP          = "mock_http"
+	FUNC_MOCK_NOW           = "mock_now"
+	FUNC_MOCK_CALLS         = "mock_calls"
 	FUNC_GET_STASH_PATH     = "get_stash_path"
 	FUNC_LOAD_STATE         = "load_state"
 	FUNC_SAVE_STATE         = "save_state"
@@ -159,6 +197,8 @@ const (
 	INTERNAL_FUNC_DOCS_FULL       = "_rad_docs_full"
 	INTERNAL_FUNC_DOCS_URL        = "_rad_docs_url"
 	INTERNAL_FUNC_RENDER          = "_rad_render"
+	INTERNAL_FUNC_GEN_REF         = "_rad_gen_ref"
+	INTERNAL_FUNC_RUN_TESTS       = "_rad_run_tests"
 
 	namedArgPreferExact    = "prefer_exact"
 	namedArgReverse        = "reverse"
@@ -317,6 +357,7 @@ func init() {
 	functions := []BuiltInFunc{
 		FuncPrint,
 		FuncPPrint,
+		FuncEmit,
 		FuncDebug,
 		FuncPrintErr,
 		FuncExit,
@@ -345,6 +386,43 @@ func init() {
 		FuncSplitLines,
 		FuncRange,
 		FuncColorize,
+		FuncImport,
+		FuncRecord,
+		FuncOfType,
+		FuncBetween,
+		FuncBind,
+		FuncBreakpoint,
+		FuncRest,
+		FuncRegex,
+		FuncWhere,
+		FuncFind,
+		FuncFindAll,
+		FuncParseYaml,
+		FuncToYaml,
+		FuncParseToml,
+		FuncToToml,
+		FuncParseCsv,
+		FuncToCsv,
+		FuncParseNdjson,
+		FuncToNdjson,
+		FuncDatetime,
+		FuncDuration,
+		FuncInTz,
+		FuncStartOf,
+		FuncSet,
+		FuncIntersect,
+		FuncReadLines,
+		FuncTake,
+		FuncRender,
+		FuncRenderFile,
+		FuncRenderDir,
+		FuncAssert,
+		FuncAssertEq,
+		FuncAssertNe,
+		FuncMockShell,
+		FuncMockHttp,
+		FuncMockNow,
+		FuncMockCalls,
 		{
 			Name: FUNC_LEN,
 			Execute: func(f FuncInvocation) RadValue {
@@ -356,8 +434,11 @@ func init() {
 					return f.Return(v.Len())
 				case *RadMap:
 					return f.Return(v.Len())
+				case *RadSet:
+					return f.Return(v.Len())
 				default:
-					bugIncorrectTypes(FUNC_LEN)
+					f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode,
+						"Cannot take the length of a %s, expected a str, list, map or set", TypeAsString(coll))
 					panic(UNREACHABLE)
 				}
 			},
@@ -469,53 +550,17 @@ func init() {
 				tz := f.GetStr("tz").Plain()
 				unit := f.GetStr("unit").Plain()
 
-				var isFloat bool
-				var epochInt int64
-				var fracPart float64
-				var isNegative bool
-
-				switch epochArg.Type() {
-				case rl.RadIntT:
-					epochInt = epochArg.RequireInt(f.i, f.callNode)
-					isNegative = epochInt < 0
-				case rl.RadFloatT:
-					isFloat = true
-					epochFloat := epochArg.RequireFloatAllowingInt(f.i, f.callNode)
-					isNegative = epochFloat < 0
-					epochInt = int64(epochFloat)
-					fracPart = epochFloat - float64(epochInt)
-				default:
-					bugIncorrectTypes(FUNC_PARSE_EPOCH)
-				}
-
-				absEpoch := epochInt
-				absFracPart := fracPart
-				if isNegative {
-					absEpoch = -absEpoch
-					absFracPart = -absFracPart
-				}
-
-				second, nanoSecond, fracMultiplier, errVal := resolveEpochUnit(f, FUNC_PARSE_EPOCH, absEpoch, unit)
+				epochTime, errVal := epochToTime(f, FUNC_PARSE_EPOCH, epochArg, unit)
 				if errVal != nil {
 					return *errVal
 				}
 
-				if isFloat {
-					nanoSecond += int64(math.Round(absFracPart * fracMultiplier))
-				}
-
-				if isNegative {
-					second = -second
-					nanoSecond = -nanoSecond
-				}
-
 				location, errVal := resolveTimeLocation(f, tz)
 				if errVal != nil {
 					return *errVal
 				}
 
-				goTime := time.Unix(second, nanoSecond).In(location)
-				timeMap := NewTimeMap(goTime)
+				timeMap := NewTimeMap(epochTime.In(location))
 				return f.Return(timeMap)
 			},
 		},
@@ -673,7 +718,7 @@ func init() {
 				if err != nil {
 					return f.ReturnErrf(rl.ErrParseJson, "Error parsing JSON: %v", err)
 				}
-				return f.Return(out)
+				return f.Return(conformInto(f, "JSON", out))
 			},
 		},
 		{
@@ -1653,8 +1698,23 @@ func init() {
 						return true // signal to keep going
 					})
 					return f.Return(outputList)
+				case *RadIter:
+					// lazy: each value is mapped as it's read
+					return f.Return(coerced.Map(f.i, f.callNode, func(val RadValue) RadValue {
+						invocation := NewFnInvocation(
+							f.i,
+							f.callNode,
+							fn.Name(),
+							NewPosArgs(NewPosArg(f.callNode, val)),
+							NO_NAMED_ARGS_INPUT,
+							fn.IsBuiltIn(),
+						)
+						return fn.Execute(invocation)
+					}))
 				default:
-					panic(fmt.Sprintf("Bug! Expected either list or map %s", coll.Type().AsString()))
+					f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode,
+						"Cannot %s a %s, expected a list, map or iterator", FUNC_MAP, TypeAsString(coll))
+					panic(UNREACHABLE)
 				}
 			},
 		},
@@ -1702,8 +1762,24 @@ func init() {
 					})
 					return f.Return(outputMap)
 
+				case *RadIter:
+					// lazy: each value is tested as it's read
+					return f.Return(coerced.Filter(f.i, f.callNode, func(val RadValue) bool {
+						invocation := NewFnInvocation(
+							f.i,
+							f.callNode,
+							fn.Name(),
+							NewPosArgs(NewPosArg(f.callNode, val)),
+							NO_NAMED_ARGS_INPUT,
+							fn.IsBuiltIn(),
+						)
+						return fn.Execute(invocation).RequireBool(f.i, f.callNode)
+					}))
+
 				default:
-					panic(fmt.Sprintf("Bug! Expected either list or map %s", coll.Type().AsString()))
+					f.i.emitErrorf(rl.ErrInvalidArgType, f.callNode,
+						"Cannot %s a %s, expected a list, map or iterator", FUNC_FILTER, TypeAsString(coll))
+					panic(UNREACHABLE)
 				}
 			},
 		},
@@ -87,6 +87,7 @@ func AddInternalFuncs() {
 				}
 
 				checker.SetStrict(f.GetBool("_strict"))
+				checker.SetRules(newCheckRuleLoader().RulesFor(scriptPath))
 
 				checker.UpdateSrc(contents)
 
@@ -131,6 +132,7 @@ func AddInternalFuncs() {
 			},
 		},
 		FuncInternalCheckFromLogs,
+		FuncInternalRunTests,
 		{
 			Name: INTERNAL_FUNC_EXPLAIN,
 			Execute: func(f FuncInvocation) RadValue {
@@ -213,6 +215,27 @@ func AddInternalFuncs() {
 				return newRadValues(f.i, f.callNode, NewRadString(md))
 			},
 		},
+		{
+			Name: INTERNAL_FUNC_GEN_REF,
+			Execute: func(f FuncInvocation) RadValue {
+				scriptPath := f.GetStr("_script").Plain()
+				if !com.IsRegularFile(scriptPath) {
+					f.i.emitErrorf(rl.ErrFileRead, f.callNode, "Cannot document '%s': not a regular file", scriptPath)
+				}
+				data, err := loadScriptDataForRef(scriptPath)
+				if err != nil {
+					f.i.emitErrorf(rl.ErrFileRead, f.callNode, "Cannot document '%s': %s", scriptPath, err.Error())
+				}
+
+				name := data.ScriptName
+				if nameArg := f.GetArg("_name"); !nameArg.IsNull() {
+					name = nameArg.RequireStr(f.i, f.callNode).Plain()
+				}
+
+				ref := RenderScriptRef(data, name, f.GetStr("_format").Plain())
+				return newRadValues(f.i, f.callNode, NewRadString(ref))
+			},
+		},
 	}
 
 	for _, f := range functions {
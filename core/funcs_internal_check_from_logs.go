@@ -93,6 +93,7 @@ var FuncInternalCheckFromLogs = BuiltInFunc{
 		if chk != nil {
 			chk.SetStrict(strict)
 		}
+		rules := newCheckRuleLoader()
 
 		// First pass: collect results and determine max path width for alignment
 		results := make([]scriptResult, 0, len(scripts))
@@ -105,7 +106,7 @@ var FuncInternalCheckFromLogs = BuiltInFunc{
 				continue
 			}
 			checkStart := time.Now()
-			counts, ok := checkScriptWith(script.Path, chk, strict)
+			counts, ok := checkScriptWith(script.Path, chk, strict, rules)
 			RP.RadDebugf("  checked in %s (ok=%t, errors=%d)", time.Since(checkStart), ok, counts.Errors)
 			r := scriptResult{Path: script.Path, Counts: counts, Ok: ok}
 			results = append(results, r)
@@ -380,7 +381,8 @@ func sortScriptsByLastOccurrence(scripts []scriptInfo) {
 // The bool is false when the file can't be loaded or parsed at all.
 // strict is applied to the fallback checker created when reusable is nil; the
 // caller has already set it on a non-nil reusable checker.
-func checkScriptWith(scriptPath string, reusable check.RadChecker, strict bool) (diagCounts, bool) {
+// rules resolves each script's configured rule levels.
+func checkScriptWith(scriptPath string, reusable check.RadChecker, strict bool, rules *check.RuleLoader) (diagCounts, bool) {
 	result := com.LoadFile(scriptPath)
 	if result.Error != nil {
 		return diagCounts{}, false
@@ -395,6 +397,9 @@ func checkScriptWith(scriptPath string, reusable check.RadChecker, strict bool)
 		}
 		chk.SetStrict(strict)
 	}
+	// Rules can differ per script: each one picks up the .radcheck.toml
+	// nearest to it.
+	chk.SetRules(rules.RulesFor(scriptPath))
 
 	chk.UpdateSrc(NormalizeLineEndings(result.Content))
 	checkResult, err := chk.Check()
@@ -76,6 +76,9 @@ var (
 	RSignal      SignalSource
 	RInteractive InteractiveDriver
 	RTerminal    TerminalSource
+	// RDebugger is attached to the script's interpreter when it runs under a
+	// debugger. nil otherwise.
+	RDebugger *Debugger
 	// RReplies holds the answers supplied via --reply / --reply-na, bound to the
 	// prompt sites they address. nil when the script has no prompts.
 	RReplies   *prompts.Replies
@@ -150,6 +153,7 @@ func ResetGlobals() {
 	RSignal = nil
 	RInteractive = nil
 	RTerminal = nil
+	RDebugger = nil
 	RReplies = nil
 	RNG = nil
 	HasScript = false
@@ -179,6 +183,8 @@ func ResetGlobals() {
 	FlagInteractive = BoolRadArg{}
 	FlagReply = StringListRadArg{}
 	FlagReplyNa = StringListRadArg{}
+	FlagOutput = StringRadArg{}
+	FlagBreak = StringListRadArg{}
 	GlobalFlagScopes = nil
 
 	StartEpochMillis = 0
@@ -3,6 +3,7 @@ package core
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -87,13 +88,28 @@ func (r *RadRunner) runInteractivePrepass(argsToRead []string) []string {
 		}
 	}
 
+	// A rejected answer is re-asked like any other invalid one, so validator
+	// functions get the same chance to speak as the built-in constraints.
+	validators := newArgValidators()
+	if invoked, _ := r.resolveInvokedCommand(); invoked != nil {
+		script, _ := r.scriptFor(invoked)
+		validators.attachPromptValidators(script, invoked.argChain())
+	}
+	validators.attachPromptValidators(r.scriptData, r.scriptData.Args)
+
 	notef := func(format string, a ...any) {
 		fmt.Fprint(RIo.StdErr, com.YellowS(format, a...))
 	}
-	tokens, err := walkInteractiveArgs(walkArgs, RRootCmd.Configured, r.cliBoolLookup(), prompter, notef)
-	if err != nil {
+	walk := newArgWalk(walkArgs, RRootCmd.Configured, r.cliBoolLookup(), prompter, notef)
+	if err := walk.walk(); err != nil {
+		r.interactiveErrorExit(err)
+	}
+	// Nothing runs until the user has seen every answer together - fixing a
+	// typo in the first one shouldn't mean starting over.
+	if err := walk.review(); err != nil {
 		r.interactiveErrorExit(err)
 	}
+	tokens := walk.tokens()
 
 	finalArgs := make([]string, 0, len(stripped)+len(tokens)+len(cmdPath))
 	// Ra matches a subcommand on the first non-flag token, so the path must lead.
@@ -427,10 +443,54 @@ func walkInteractiveArgs(
 	prompter ArgPrompter,
 	notef func(format string, a ...any),
 ) ([]string, error) {
-	st := newWalkState(args, isConfigured, cliBoolVal)
+	w := newArgWalk(args, isConfigured, cliBoolVal, prompter, notef)
+	if err := w.walk(); err != nil {
+		return nil, err
+	}
+	return w.tokens(), nil
+}
+
+// argWalk is one --interactive pass over a set of args. It keeps each prompt's
+// answer separately, rather than only the tokens they add up to, so that the
+// review screen can re-ask any one of them and re-check what depends on it.
+type argWalk struct {
+	args         []*ScriptArg
+	isConfigured func(externalName string) bool
+	cliBoolVal   func(externalName string) bool
+	prompter     ArgPrompter
+	notef        func(format string, a ...any)
+	answers      []*walkAnswer // in arg order
+}
+
+// walkAnswer is the outcome of one prompt: a single arg, or the bool group
+// that shared a MultiSelect, and the argv tokens the answer emitted. No tokens
+// means skipped - the final parse applies the default.
+type walkAnswer struct {
+	args   []*ScriptArg
+	tokens []string
+}
+
+func newArgWalk(
+	args []*ScriptArg,
+	isConfigured func(externalName string) bool,
+	cliBoolVal func(externalName string) bool,
+	prompter ArgPrompter,
+	notef func(format string, a ...any),
+) *argWalk {
+	return &argWalk{
+		args:         args,
+		isConfigured: isConfigured,
+		cliBoolVal:   cliBoolVal,
+		prompter:     prompter,
+		notef:        notef,
+	}
+}
+
+func (w *argWalk) walk() error {
+	st := newWalkState(w.args, w.isConfigured, w.cliBoolVal)
 
 	var groupBools []*ScriptArg
-	for _, arg := range args {
+	for _, arg := range w.args {
 		if !st.explicit[arg.ExternalName] && isGroupableBool(arg) {
 			groupBools = append(groupBools, arg)
 		}
@@ -440,8 +500,7 @@ func walkInteractiveArgs(
 	}
 	boolsHandled := false
 
-	var tokens []string
-	for _, arg := range args {
+	for _, arg := range w.args {
 		if st.explicit[arg.ExternalName] {
 			continue
 		}
@@ -450,34 +509,236 @@ func walkInteractiveArgs(
 				continue
 			}
 			boolsHandled = true
-			groupTokens, err := promptBoolGroup(groupBools, args, st, prompter, notef)
+			prompted, groupTokens, err := promptBoolGroup(groupBools, w.args, st, w.prompter, w.notef)
 			if err != nil {
-				return nil, err
+				return err
+			}
+			if len(prompted) > 0 {
+				w.answers = append(w.answers, &walkAnswer{args: prompted, tokens: groupTokens})
 			}
-			tokens = append(tokens, groupTokens...)
 			continue
 		}
-		if excluder := excludedBy(arg, args, st); excluder != "" {
-			notef("Skipping --%s (excluded by --%s)\n", arg.ExternalName, excluder)
+		if excluder := excludedBy(arg, w.args, st); excluder != "" {
+			w.notef("Skipping --%s (excluded by --%s)\n", arg.ExternalName, excluder)
 			continue
 		}
-		required := !arg.IsNullable && !arg.HasDefaultValue
-		requiredReason := ""
-		// An arg with a default satisfies a requires constraint by itself, so
-		// only force ones that would otherwise stay valueless.
-		if by := requiredBy(arg, args, st); by != "" && !arg.HasDefaultValue {
-			required = true
-			requiredReason = fmt.Sprintf("required by --%s", by)
-		}
 
-		argTokens, err := promptForArg(arg, required, requiredReason, prompter)
+		required, requiredReason := w.requirement(arg, st)
+		argTokens, err := promptForArg(arg, required, requiredReason, w.prompter)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		st.noteAnswered(arg, argTokens)
-		tokens = append(tokens, argTokens...)
+		w.answers = append(w.answers, &walkAnswer{args: []*ScriptArg{arg}, tokens: argTokens})
+	}
+	return nil
+}
+
+// requirement reports whether arg must be given a value, and why if it's only
+// because another arg requires it.
+func (w *argWalk) requirement(arg *ScriptArg, st *walkState) (bool, string) {
+	// An arg with a default satisfies a requires constraint by itself, so
+	// only force ones that would otherwise stay valueless.
+	if by := requiredBy(arg, w.args, st); by != "" && !arg.HasDefaultValue {
+		return true, fmt.Sprintf("required by --%s", by)
+	}
+	return !arg.IsNullable && !arg.HasDefaultValue, ""
+}
+
+// tokens is the argv the answers add up to, in arg order.
+func (w *argWalk) tokens() []string {
+	var tokens []string
+	for _, a := range w.answers {
+		tokens = append(tokens, a.tokens...)
+	}
+	return tokens
+}
+
+// state rebuilds the walk state from the CLI and the current answers. Edits
+// can change any answer, so it's recomputed rather than patched.
+func (w *argWalk) state() *walkState {
+	st := newWalkState(w.args, w.isConfigured, w.cliBoolVal)
+	for _, a := range w.answers {
+		for _, arg := range a.args {
+			st.noteAnswered(arg, a.tokensFor(arg))
+		}
+	}
+	return st
+}
+
+func (w *argWalk) answerFor(arg *ScriptArg) *walkAnswer {
+	for _, a := range w.answers {
+		if lo.Contains(a.args, arg) {
+			return a
+		}
+	}
+	return nil
+}
+
+const reviewRun = "Run"
+
+// review lists every answer on one screen and re-asks whichever the user
+// picks, until they choose to run. A walk that asked nothing has nothing to
+// review.
+func (w *argWalk) review() error {
+	if len(w.answers) == 0 {
+		return nil
+	}
+	for {
+		options := []string{reviewRun}
+		byLabel := make(map[string]*walkAnswer, len(w.answers))
+		for _, a := range w.answers {
+			label := a.label()
+			options = append(options, label)
+			byLabel[label] = a
+		}
+		summarize := func(choice string) string {
+			if a, ok := byLabel[choice]; ok {
+				return com.BoldS("Review:") + " change " + com.CyanS(a.name())
+			}
+			return com.BoldS("Review:") + " " + com.GreenS("run")
+		}
+
+		choice, err := w.prompter.Select("Review your answers", options, summarize)
+		if err != nil {
+			return err
+		}
+		a, ok := byLabel[choice]
+		if !ok {
+			return nil
+		}
+		if err := w.reask(a); err != nil {
+			return err
+		}
 	}
-	return tokens, nil
+}
+
+// reask prompts for an answer again, from scratch, then restores whatever
+// constraints the new answer broke.
+func (w *argWalk) reask(a *walkAnswer) error {
+	// Forget the old answer first: it mustn't exclude or require anything
+	// while its replacement is being asked for.
+	a.tokens = nil
+	st := w.state()
+
+	if len(a.args) > 1 {
+		prompted, tokens, err := promptBoolGroup(a.args, w.args, st, w.prompter, w.notef)
+		if err != nil {
+			return err
+		}
+		if len(prompted) > 0 {
+			a.tokens = tokens
+		}
+	} else {
+		required, requiredReason := w.requirement(a.args[0], st)
+		tokens, err := promptForArg(a.args[0], required, requiredReason, w.prompter)
+		if err != nil {
+			return err
+		}
+		a.tokens = tokens
+	}
+	return w.reconcile(a)
+}
+
+// reconcile re-applies the relational constraints after an edit, the way the
+// walk applied them the first time: an answer the edit now excludes is dropped
+// (with a note), and an arg it now requires is asked for. The edited answer
+// wins a conflict - it's the one the user just chose. Conflicts among the bool
+// group's members are left to the final parse, as in the walk.
+func (w *argWalk) reconcile(edited *walkAnswer) error {
+	st := w.state()
+	for _, a := range w.answers {
+		if a == edited || len(a.args) != 1 || len(a.tokens) == 0 {
+			continue
+		}
+		if by := excludedBy(a.args[0], w.args, st); by != "" {
+			w.notef("Dropping --%s (excluded by --%s)\n", a.args[0].ExternalName, by)
+			a.tokens = nil
+			st = w.state()
+		}
+	}
+
+	for _, arg := range w.args {
+		if st.explicit[arg.ExternalName] || isGroupableBool(arg) {
+			continue
+		}
+		if excludedBy(arg, w.args, st) != "" {
+			continue
+		}
+		required, requiredReason := w.requirement(arg, st)
+		if !required {
+			continue
+		}
+		tokens, err := promptForArg(arg, required, requiredReason, w.prompter)
+		if err != nil {
+			return err
+		}
+		if a := w.answerFor(arg); a != nil {
+			a.tokens = tokens
+		} else {
+			w.insertAnswer(&walkAnswer{args: []*ScriptArg{arg}, tokens: tokens})
+		}
+		st = w.state()
+	}
+	return nil
+}
+
+// insertAnswer adds an answer for an arg the walk skipped, keeping answers in
+// arg order so the equivalent invocation reads the same as a first-time walk.
+func (w *argWalk) insertAnswer(added *walkAnswer) {
+	pos := lo.IndexOf(w.args, added.args[0])
+	idx := len(w.answers)
+	for i, a := range w.answers {
+		if lo.IndexOf(w.args, a.args[0]) > pos {
+			idx = i
+			break
+		}
+	}
+	w.answers = slices.Insert(w.answers, idx, added)
+}
+
+// tokensFor is the part of the answer belonging to one arg. A group's tokens
+// are each a single --flag or --flag=false.
+func (a *walkAnswer) tokensFor(arg *ScriptArg) []string {
+	if len(a.args) == 1 {
+		return a.tokens
+	}
+	flag := "--" + arg.ExternalName
+	return lo.Filter(a.tokens, func(t string, _ int) bool {
+		return t == flag || strings.HasPrefix(t, flag+"=")
+	})
+}
+
+// name is how the review screen refers to the answer's prompt.
+func (a *walkAnswer) name() string {
+	if len(a.args) > 1 {
+		return "Flags"
+	}
+	return "--" + a.args[0].ExternalName
+}
+
+// label renders the answer as a review-screen row: the tokens it adds to the
+// invocation, or what the final parse will do in their absence. Plain text,
+// since it's a Select option rather than transcript.
+func (a *walkAnswer) label() string {
+	if len(a.args) > 1 {
+		if len(a.tokens) == 0 {
+			return "Flags: (all defaults)"
+		}
+		return "Flags: " + strings.Join(a.tokens, ", ")
+	}
+	if len(a.tokens) > 0 {
+		return strings.Join(lo.Map(a.tokens, func(t string, _ int) string { return shellQuoteIfNeeded(t) }), " ")
+	}
+	arg := a.args[0]
+	if isGroupableBool(arg) {
+		def := arg.DefaultBool != nil && *arg.DefaultBool
+		return fmt.Sprintf("--%s (default: %s)", arg.ExternalName, lo.Ternary(def, "yes", "no"))
+	}
+	if d := argDefaultDisplay(arg); d != "" {
+		return fmt.Sprintf("--%s (skip - default: %s)", arg.ExternalName, d)
+	}
+	return fmt.Sprintf("--%s (skip)", arg.ExternalName)
 }
 
 func isGroupableBool(arg *ScriptArg) bool {
@@ -490,14 +751,15 @@ func isGroupableBool(arg *ScriptArg) bool {
 // the individual y/n path. Bools excluded by explicitly-set args are dropped
 // (with a note); if only one bool survives, it falls back to a y/n prompt.
 // Exclusions and requirements BETWEEN grouped bools can't react within a
-// single prompt - the final parse backstops those.
+// single prompt - the final parse backstops those. Returns the bools actually
+// prompted alongside their tokens.
 func promptBoolGroup(
 	bools []*ScriptArg,
 	all []*ScriptArg,
 	st *walkState,
 	prompter ArgPrompter,
 	notef func(format string, a ...any),
-) ([]string, error) {
+) ([]*ScriptArg, []string, error) {
 	var eligible []*ScriptArg
 	for _, b := range bools {
 		if excluder := excludedBy(b, all, st); excluder != "" {
@@ -507,14 +769,14 @@ func promptBoolGroup(
 		eligible = append(eligible, b)
 	}
 	if len(eligible) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 	if len(eligible) == 1 {
 		tokens, err := promptForArg(eligible[0], false, "", prompter)
 		if err == nil {
 			st.noteAnswered(eligible[0], tokens)
 		}
-		return tokens, err
+		return eligible, tokens, err
 	}
 
 	labels := make([]string, len(eligible))
@@ -556,7 +818,7 @@ func promptBoolGroup(
 
 	chosen, err := prompter.MultiSelect("Flags", labels, preselected, summarize)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	chosenSet := make(map[string]bool, len(chosen))
 	for _, label := range chosen {
@@ -582,7 +844,7 @@ func promptBoolGroup(
 		}
 		st.explicit[b.ExternalName] = true
 	}
-	return tokens, nil
+	return eligible, tokens, nil
 }
 
 // excludedBy returns the external name of an arg that excludes (in either
@@ -854,7 +1116,27 @@ func validatorFor(arg *ScriptArg, required bool, requiredReason string) func(str
 // prompt sequence completes. It used to be the *only* check - the non-interactive
 // path dropped list constraints entirely - which is why `rad -i` rejected input
 // that plain `rad` accepted.
+//
+// An arg with a validator function has it run last, once the value is known
+// to be well-formed (see attachPromptValidators).
 func elementValidatorFor(arg *ScriptArg) func(string) error {
+	check := constraintValidatorFor(arg)
+	if arg.validatePrompt == nil {
+		return check
+	}
+	return func(s string) error {
+		if check != nil {
+			if err := check(s); err != nil {
+				return err
+			}
+		}
+		return arg.validatePrompt(s)
+	}
+}
+
+// constraintValidatorFor checks a value against the arg's type and declared
+// constraints, or returns nil when anything goes.
+func constraintValidatorFor(arg *ScriptArg) func(string) error {
 	switch elementType(arg.Type) {
 	case ArgIntT:
 		return func(s string) error {
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/amterp/radish"
@@ -395,6 +396,79 @@ func TestWalkPropagatesNotInteractive(t *testing.T) {
 	assert.ErrorIs(t, err, radish.ErrNotInteractive)
 }
 
+func TestReviewRunsAnswersUnchanged(t *testing.T) {
+	args := []*ScriptArg{strArg("alpha")}
+	p := &fakePrompter{t: t, answers: []string{"a1", reviewRun}}
+
+	w := newArgWalk(args, notConfigured, noBoolVals, p, noNotes)
+	assert.NoError(t, w.walk())
+	assert.NoError(t, w.review())
+
+	assert.Equal(t, []string{"--alpha", "a1"}, w.tokens())
+	assert.Equal(t, []string{"--alpha", "Review your answers"}, p.prompts)
+}
+
+func TestReviewReasksPickedAnswer(t *testing.T) {
+	args := []*ScriptArg{strArg("alpha"), strArg("beta")}
+	p := &fakePrompter{t: t, answers: []string{"a1", "b1", "--alpha a1", "a2", reviewRun}}
+
+	w := newArgWalk(args, notConfigured, noBoolVals, p, noNotes)
+	assert.NoError(t, w.walk())
+	assert.NoError(t, w.review())
+
+	assert.Equal(t, []string{"--alpha", "a2", "--beta", "b1"}, w.tokens(), "edits keep their place in arg order")
+	assert.Contains(t, p.summaries, "Review: change --alpha")
+}
+
+func TestReviewEditDropsNowExcludedAnswer(t *testing.T) {
+	json := strArg("json", func(arg *ScriptArg) {
+		arg.IsNullable = true
+		arg.ExcludesConstraint = []string{"csv"}
+	})
+	csv := strArg("csv", func(arg *ScriptArg) { arg.IsNullable = true })
+
+	var notes []string
+	notef := func(format string, args ...any) { notes = append(notes, format) }
+
+	// json is skipped the first time, so csv is free to be answered. Giving
+	// json a value on review means csv's answer can no longer stand.
+	p := &fakePrompter{t: t, answers: []string{"", "out.csv", "--json (skip)", "out.json", reviewRun}}
+	w := newArgWalk([]*ScriptArg{json, csv}, notConfigured, noBoolVals, p, notef)
+	assert.NoError(t, w.walk())
+	assert.NoError(t, w.review())
+
+	assert.Equal(t, []string{"--json", "out.json"}, w.tokens())
+	assert.Len(t, notes, 1)
+}
+
+func TestReviewEditPromptsNewlyRequiredArg(t *testing.T) {
+	user := strArg("user", func(arg *ScriptArg) {
+		arg.IsNullable = true
+		arg.RequiresConstraint = []string{"token"}
+	})
+	token := strArg("token", func(arg *ScriptArg) { arg.IsNullable = true })
+
+	p := &fakePrompter{t: t, answers: []string{"", "", "--user (skip)", "alice", "s3cret", reviewRun}}
+	w := newArgWalk([]*ScriptArg{user, token}, notConfigured, noBoolVals, p, noNotes)
+	assert.NoError(t, w.walk())
+	assert.NoError(t, w.review())
+
+	assert.Equal(t, []string{"--user", "alice", "--token", "s3cret"}, w.tokens())
+	assert.Equal(t, []string{"--user", "--token", "Review your answers", "--user", "--token", "Review your answers"},
+		p.prompts)
+}
+
+func TestReviewSkippedWhenNothingAsked(t *testing.T) {
+	args := []*ScriptArg{strArg("alpha")}
+	p := &fakePrompter{t: t}
+
+	w := newArgWalk(args, func(string) bool { return true }, noBoolVals, p, noNotes)
+	assert.NoError(t, w.walk())
+	assert.NoError(t, w.review())
+
+	assert.Empty(t, p.prompts)
+}
+
 func TestValidators(t *testing.T) {
 	min, max := 1.0, 10.0
 	intArg := &ScriptArg{
@@ -421,6 +495,29 @@ func TestValidators(t *testing.T) {
 	assert.Error(t, bv("y"), "bool list elements must be ra-parseable")
 }
 
+func TestValidatorFunctionRunsAfterConstraints(t *testing.T) {
+	min := 1.0
+	var seen []string
+	arg := &ScriptArg{
+		Name: "n", ExternalName: "n", Type: ArgIntT,
+		RangeConstraint: &ArgRangeConstraint{Min: &min, MinInclusive: true},
+		validatePrompt: func(s string) error {
+			seen = append(seen, s)
+			if s == "13" {
+				return errors.New("unlucky")
+			}
+			return nil
+		},
+	}
+	v := validatorFor(arg, false, "")
+	assert.NoError(t, v(""), "skipping never reaches the validator")
+	assert.Error(t, v("abc"))
+	assert.Error(t, v("0"))
+	assert.EqualError(t, v("13"), "unlucky")
+	assert.NoError(t, v("7"))
+	assert.Equal(t, []string{"13", "7"}, seen, "only well-formed values reach the validator")
+}
+
 func TestShellQuoteIfNeeded(t *testing.T) {
 	assert.Equal(t, "plain-token._/2", shellQuoteIfNeeded("plain-token._/2"))
 	assert.Equal(t, "'has space'", shellQuoteIfNeeded("has space"))
@@ -86,10 +86,25 @@ type Interpreter struct {
 	// Call stack for Rad function calls (not Go stack)
 	callStack []CallFrame
 
+	// set while a generator's body runs, to receive what it yields
+	gen *genContext
+
 	// signals owns signal-handling state. Constructed inactive; the dispatch
 	// goroutine is started by Run so REPL-style usage that never calls Run
 	// does not leak a goroutine.
 	signals *SignalManager
+
+	// debugger, when attached, sees each statement before it runs.
+	debugger *Debugger
+
+	// modules are those import() has loaded during this run.
+	modules *moduleCache
+
+	// diagnosticSink, when set, receives diagnostics in place of rendering
+	// them and exiting. A debugger evaluating in a paused frame reports
+	// errors to its client rather than ending the script it is inspecting.
+	// It must not return: callers carry on as if execution had ended.
+	diagnosticSink func(Diagnostic)
 }
 
 func NewInterpreter(input InterpreterInput) *Interpreter {
@@ -105,6 +120,7 @@ func NewInterpreter(input InterpreterInput) *Interpreter {
 		invokedCommand: input.InvokedCommand,
 		delimiterStack: com.NewStack[Delimiter](),
 		signals:        NewSignalManager(),
+		modules:        newModuleCache(),
 	}
 	i.env = NewEnv(i)
 	return i
@@ -203,8 +219,7 @@ func (i *Interpreter) Run() {
 	i.signals.Start()
 	defer i.signals.Stop()
 
-	// Convert CST to AST once, then interpret the AST
-	astRoot := rts.ConvertCST(i.sd.Tree.Root(), i.sd.Src, i.sd.ScriptName)
+	astRoot := i.astRoot()
 
 	// PHASE 1: Execute top-level code (always)
 	res := i.safelyExecuteTopLevel(astRoot)
@@ -224,16 +239,13 @@ func (i *Interpreter) safelyExecuteTopLevel(root *rl.SourceFile) EvalResult {
 	}()
 
 	// First pass: define custom named functions (function hoisting)
-	for _, stmt := range root.Stmts {
-		if fnDef, ok := stmt.(*rl.FnDef); ok {
-			i.defineCustomNamedFunction(fnDef)
-		}
-	}
+	i.defineFunctions(root)
 
 	// Second pass: evaluate all statements
 	var lastResult EvalResult
 	for _, stmt := range root.Stmts {
 		i.Checkpoint()
+		i.debugPoint(stmt)
 		lastResult = i.eval(stmt)
 	}
 	// Drain one more time so a signal that arrived during the final
@@ -245,6 +257,25 @@ func (i *Interpreter) safelyExecuteTopLevel(root *rl.SourceFile) EvalResult {
 	return lastResult
 }
 
+// astRoot converts the CST to an AST once, then hands out the same one - arg
+// validators need the script's functions before Run interprets the rest.
+func (i *Interpreter) astRoot() *rl.SourceFile {
+	if i.sd.Ast == nil {
+		i.sd.Ast = rts.ConvertCST(i.sd.Tree.Root(), i.sd.Src, i.sd.ScriptName)
+	}
+	return i.sd.Ast
+}
+
+// defineFunctions hoists the script's named functions into the environment
+// without running anything else.
+func (i *Interpreter) defineFunctions(root *rl.SourceFile) {
+	for _, stmt := range root.Stmts {
+		if fnDef, ok := stmt.(*rl.FnDef); ok {
+			i.defineCustomNamedFunction(fnDef)
+		}
+	}
+}
+
 func (i *Interpreter) safelyExecuteCommandCallback(cmd *ScriptCommand) {
 	defer func() {
 		// Use nil node since we don't have a specific node for the command invocation
@@ -331,10 +362,21 @@ func (i *Interpreter) eval(node rl.Node) (out EvalResult) {
 		// no-op
 
 	case *rl.Return:
+		if i.gen != nil && len(n.Values) > 0 {
+			i.emitError(rl.ErrInvalidSyntax, n, "Cannot return a value from a generator, only 'yield' values")
+		}
 		return ReturnVal(i.evalValues(n, n.Values))
 
 	case *rl.Yield:
-		return YieldVal(i.evalValues(n, n.Values))
+		val := i.evalValues(n, n.Values)
+		if i.gen != nil {
+			if !i.gen.yield(n, val) {
+				// the consumer stopped early; unwind the generator's body
+				return ReturnVal(VOID_SENTINEL)
+			}
+			return VoidNormal
+		}
+		return YieldVal(val)
 
 	case *rl.Break:
 		if i.forWhileLoopLevel > 0 {
@@ -389,10 +431,25 @@ func (i *Interpreter) eval(node rl.Node) (out EvalResult) {
 		discriminantVal := i.eval(n.Discriminant).Val
 		discriminantVal.RequireNonVoid(i, n.Discriminant)
 
+		// Equality cases can't overlap, so every one is tried and two matching
+		// is an error. Patterns may (of_type("int") and between(1, 10)), so the
+		// first to match wins, but only if no equality case matches: which case
+		// runs never depends on where a pattern sits among equality cases.
 		var matchedCases []rl.SwitchCase
-		for _, sc := range n.Cases {
+		var patternCase *rl.SwitchCase
+		var patternBinds []patternBinding
+		for idx, sc := range n.Cases {
 			for _, key := range sc.Keys {
 				caseKey := i.eval(key).Val
+				if isPattern(caseKey) {
+					if patternCase != nil || len(matchedCases) > 0 {
+						continue
+					}
+					if matched, binds := i.matchPattern(key, caseKey, discriminantVal); matched {
+						patternCase, patternBinds = &n.Cases[idx], binds
+					}
+					continue
+				}
 				if caseKey.Equals(discriminantVal) {
 					matchedCases = append(matchedCases, sc)
 					break
@@ -400,6 +457,13 @@ func (i *Interpreter) eval(node rl.Node) (out EvalResult) {
 			}
 		}
 
+		if len(matchedCases) == 0 && patternCase != nil {
+			for _, b := range patternBinds {
+				i.env.SetVar(b.name, b.val)
+			}
+			return i.executeSwitchCaseAlt(patternCase.Alt)
+		}
+
 		if len(matchedCases) == 0 {
 			if n.Default != nil {
 				return i.executeSwitchCaseAlt(n.Default.Alt)
@@ -521,6 +585,7 @@ func (i *Interpreter) eval(node rl.Node) (out EvalResult) {
 				RequireNotType(i, entry.Key, "Map keys cannot be null", rl.RadNullT).
 				RequireNotType(i, entry.Key, "Map keys cannot be lists", rl.RadListT).
 				RequireNotType(i, entry.Key, "Map keys cannot be maps", rl.RadMapT).
+				RequireNotType(i, entry.Key, "Map keys cannot be sets", rl.RadSetT).
 				RequireNotType(i, entry.Key, "Map keys cannot be functions", rl.RadFnT)
 			radMap.Set(key, i.eval(entry.Value).Val)
 		}
@@ -813,6 +878,10 @@ func (i *Interpreter) emitDiagnostic(d Diagnostic) {
 	if len(d.CallStack) == 0 && len(i.callStack) > 0 {
 		d = d.WithCallStack(i.CallStack())
 	}
+	if i.diagnosticSink != nil {
+		i.diagnosticSink(d)
+		return
+	}
 	renderer := NewDiagnosticRenderer(RIo.StdErr)
 	renderer.Render(d)
 	emitShellExit(1)
@@ -835,7 +904,7 @@ func (i *Interpreter) emitError(code rl.Error, node rl.Node, message string) {
 func (i *Interpreter) emitErrorSpan(code rl.Error, span *rl.Span, message string) {
 	var diag Diagnostic
 	if span != nil {
-		diag = NewDiagnostic(SeverityError, code, message, i.GetSrc(), *span)
+		diag = NewDiagnostic(SeverityError, code, message, i.srcFor(*span), *span)
 	} else {
 		diag = Diagnostic{
 			Severity: SeverityError,
@@ -858,7 +927,7 @@ func (i *Interpreter) emitErrorWithHint(code rl.Error, node rl.Node, message str
 	var diag Diagnostic
 	if node != nil {
 		span := node.Span()
-		diag = NewDiagnostic(SeverityError, code, message, i.GetSrc(), span).WithHint(hint)
+		diag = NewDiagnostic(SeverityError, code, message, i.srcFor(span), span).WithHint(hint)
 	} else {
 		diag = Diagnostic{
 			Severity: SeverityError,
@@ -875,14 +944,16 @@ func (i *Interpreter) emitErrorWithHint(code rl.Error, node rl.Node, message str
 // If primaryNode is nil, the diagnostic will only have the secondary span (if provided).
 func (i *Interpreter) emitErrorWithSecondary(code rl.Error, primaryNode rl.Node, message string, secondarySpan *rl.Span, secondaryMsg string) {
 	var labels []Label
+	src := i.GetSrc()
 	if primaryNode != nil {
 		primarySpan := primaryNode.Span()
 		labels = append(labels, NewPrimaryLabel(primarySpan, ""))
+		src = i.srcFor(primarySpan)
 	}
 	if secondarySpan != nil {
 		labels = append(labels, NewSecondaryLabel(*secondarySpan, secondaryMsg))
 	}
-	diag := NewDiagnosticWithLabels(SeverityError, code, message, i.GetSrc(), labels)
+	diag := NewDiagnosticWithLabels(SeverityError, code, message, src, labels)
 	i.emitDiagnostic(diag)
 }
 
@@ -895,7 +966,7 @@ func (i *Interpreter) emitErrorReRaised(code rl.Error, message string, raiseSpan
 		NewPrimaryLabel(raiseSpan, "re-raised here"),
 		NewSecondaryLabel(originSpan, "originally raised here"),
 	}
-	diag := NewDiagnosticWithLabels(SeverityError, code, message, i.GetSrc(), labels)
+	diag := NewDiagnosticWithLabels(SeverityError, code, message, i.srcFor(raiseSpan), labels)
 	i.emitDiagnostic(diag)
 }
 
@@ -1029,7 +1100,7 @@ func (i *Interpreter) setLoopContext(contextName *string, loopNode rl.Node, idx
 	i.env.SetVar(*contextName, newRadValue(i, loopNode, ctx))
 }
 
-// executeForLoop dispatches a for-loop to the appropriate list or map iterator.
+// executeForLoop dispatches a for-loop to the appropriate list, map or iterator loop.
 // Works for both ForLoop and ListComp AST nodes.
 func (i *Interpreter) executeForLoop(node rl.Node, doOneLoop func() EvalResult) EvalResult {
 	var vars []string
@@ -1056,6 +1127,11 @@ func (i *Interpreter) executeForLoop(node rl.Node, doOneLoop func() EvalResult)
 		return runForLoopList(i, node, vars, iterNode, context, coercedRight.ToRuneList(), res.Val, doOneLoop)
 	case *RadList:
 		return runForLoopList(i, node, vars, iterNode, context, coercedRight, res.Val, doOneLoop)
+	case *RadSet:
+		// in insertion order
+		return runForLoopList(i, node, vars, iterNode, context, coercedRight.ToList(), res.Val, doOneLoop)
+	case *RadIter:
+		return runForLoopIter(i, node, vars, iterNode, context, coercedRight, res.Val, doOneLoop)
 	case *RadMap:
 		return runForLoopMap(i, node, vars, context, coercedRight, doOneLoop)
 	default:
@@ -1097,29 +1173,7 @@ Loop:
 	for idx := 0; idx < len(list.Values); idx++ {
 		val := list.Values[idx]
 		i.setLoopContext(context, loopNode, int64(idx), srcCopy)
-
-		if len(vars) == 1 {
-			i.env.SetVar(vars[0], val)
-		} else {
-			listInList, ok := val.TryGetList()
-			if !ok {
-				if vars[0] == "idx" || vars[0] == "index" || vars[0] == "i" || vars[0] == "_" {
-					i.emitErrorWithHint(rl.ErrUnpackMismatch, iterNode,
-						fmt.Sprintf("Cannot unpack %q into %d values", TypeAsString(val), len(vars)),
-						rl.HintForLoopMigration)
-				}
-				i.emitErrorf(rl.ErrUnpackMismatch, iterNode, "Cannot unpack %q into %d values", TypeAsString(val), len(vars))
-			}
-
-			if listInList.LenInt() < len(vars) {
-				i.emitErrorf(rl.ErrUnpackMismatch, iterNode, "Expected at least %s in inner list, got %d",
-					com.Pluralize(len(vars), "value"), listInList.LenInt())
-			}
-
-			for j, varName := range vars {
-				i.env.SetVar(varName, listInList.Values[j])
-			}
-		}
+		bindLoopVars(i, iterNode, vars, val)
 
 		res := doOneLoop()
 		switch res.Ctrl {
@@ -1132,6 +1186,70 @@ Loop:
 	return VoidNormal
 }
 
+// bindLoopVars sets the loop's variables for one element, unpacking it
+// across them if there are several.
+func bindLoopVars(i *Interpreter, iterNode rl.Node, vars []string, val RadValue) {
+	if len(vars) == 1 {
+		i.env.SetVar(vars[0], val)
+		return
+	}
+
+	listInList, ok := val.TryGetList()
+	if !ok {
+		if vars[0] == "idx" || vars[0] == "index" || vars[0] == "i" || vars[0] == "_" {
+			i.emitErrorWithHint(rl.ErrUnpackMismatch, iterNode,
+				fmt.Sprintf("Cannot unpack %q into %d values", TypeAsString(val), len(vars)),
+				rl.HintForLoopMigration)
+		}
+		i.emitErrorf(rl.ErrUnpackMismatch, iterNode, "Cannot unpack %q into %d values", TypeAsString(val), len(vars))
+	}
+
+	if listInList.LenInt() < len(vars) {
+		i.emitErrorf(rl.ErrUnpackMismatch, iterNode, "Expected at least %s in inner list, got %d",
+			com.Pluralize(len(vars), "value"), listInList.LenInt())
+	}
+
+	for j, varName := range vars {
+		i.env.SetVar(varName, listInList.Values[j])
+	}
+}
+
+// runForLoopIter loops over an iterator, pulling one value per iteration.
+// Breaking out stops the iterator's producer.
+func runForLoopIter(
+	i *Interpreter,
+	loopNode rl.Node,
+	vars []string,
+	iterNode rl.Node,
+	context *string,
+	iter *RadIter,
+	srcValue RadValue,
+	doOneLoop func() EvalResult,
+) EvalResult {
+	if len(vars) == 0 {
+		i.emitError(rl.ErrInvalidSyntax, loopNode, "Expected at least one variable on the left side of for loop")
+	}
+
+	out := VoidNormal
+	idx := int64(0)
+	iter.Each(i, iterNode, func(val RadValue) bool {
+		i.setLoopContext(context, loopNode, idx, srcValue)
+		idx++
+		bindLoopVars(i, iterNode, vars, val)
+
+		res := doOneLoop()
+		switch res.Ctrl {
+		case CtrlBreak:
+			return false
+		case CtrlReturn, CtrlYield:
+			out = res
+			return false
+		}
+		return true
+	})
+	return out
+}
+
 func runForLoopMap(
 	i *Interpreter,
 	loopNode rl.Node,
@@ -1177,6 +1295,7 @@ func (i *Interpreter) runBlock(stmtNodes []rl.Node) EvalResult {
 	var res EvalResult
 	for _, stmtNode := range stmtNodes {
 		i.Checkpoint()
+		i.debugPoint(stmtNode)
 		res = i.eval(stmtNode)
 		if res.Ctrl != CtrlNormal {
 			break
@@ -1366,7 +1485,7 @@ func (i *Interpreter) GetScriptName() string {
 }
 
 func (i *Interpreter) GetSrcForSpan(span rl.Span) string {
-	return i.GetSrc()[span.StartByte:span.EndByte]
+	return i.srcFor(span)[span.StartByte:span.EndByte]
 }
 
 // evalSlice evaluates a slice operation (a[start:end]) on a value.
@@ -1411,9 +1530,10 @@ func (i *Interpreter) resolveSliceBounds(seg rl.PathSegment, length int64) (int6
 
 // todo this is somewhat hacky, not a fan. only use when you're extremely sure fn won't panic
 func (i *Interpreter) WithTmpSrc(tmpSrc string, fn func()) {
+	prev := i.tmpSrc
 	i.tmpSrc = &tmpSrc
 	defer func() {
-		i.tmpSrc = nil
+		i.tmpSrc = prev
 	}()
 	fn()
 }
@@ -1424,6 +1544,10 @@ func (i *Interpreter) executeSwitchCaseAlt(alt rl.Node) EvalResult {
 	case *rl.SwitchCaseExpr:
 		return NormalVal(i.evalValues(n, n.Values))
 	case *rl.SwitchCaseBlock:
+		// a yield here gives the case's value, even inside a generator
+		gen := i.gen
+		i.gen = nil
+		defer func() { i.gen = gen }()
 		res := i.runBlock(n.Stmts)
 		switch res.Ctrl {
 		case CtrlNormal, CtrlBreak, CtrlContinue, CtrlReturn:
@@ -42,6 +42,30 @@ func ToRadArgTypeT(str string) RadArgTypeT {
 	}
 }
 
+// TypeName returns the type as it's written in an args block, e.g. "int[]".
+func (t RadArgTypeT) TypeName() string {
+	switch t {
+	case ArgStringT:
+		return rl.T_STR
+	case ArgIntT:
+		return rl.T_INT
+	case ArgFloatT:
+		return rl.T_FLOAT
+	case ArgBoolT:
+		return rl.T_BOOL
+	case ArgStrListT:
+		return rl.T_STR_LIST
+	case ArgIntListT:
+		return rl.T_INT_LIST
+	case ArgFloatListT:
+		return rl.T_FLOAT_LIST
+	case ArgBoolListT:
+		return rl.T_BOOL_LIST
+	default:
+		panic(fmt.Sprintf("Bug! Unhandled arg type in TypeName: %d", t))
+	}
+}
+
 type SortDir int
 
 const (
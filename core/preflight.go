@@ -28,8 +28,8 @@ const (
 //
 // The replies it parses are stored for the runtime to consume, whether or not a
 // terminal exists - a caller who supplies an answer gets it used either way.
-func (r *RadRunner) runPromptPreflight(invoked *ScriptCommand) {
-	sites := prompts.Find(r.scriptData.Ast, r.scriptData.Resolved, commandPath(invoked))
+func (r *RadRunner) runPromptPreflight(script *ScriptData, invoked *ScriptCommand) {
+	sites := prompts.Find(script.Ast, script.Resolved, commandPath(invoked))
 
 	replies, err := prompts.ParseReplies(FlagReply.Value, FlagReplyNa.Value, sites)
 	if err != nil {
@@ -51,7 +51,7 @@ func (r *RadRunner) runPromptPreflight(invoked *ScriptCommand) {
 	// start and stop at the first one - after earlier commands already ran -
 	// refuse the combination outright. A script with no shell commands at all
 	// has nothing to gate, so it isn't caught by this.
-	if FlagConfirmShellCommands.Value && hasShellCommand(r.scriptData.Ast) {
+	if FlagConfirmShellCommands.Value && hasShellCommand(script.Ast) {
 		var b strings.Builder
 		writeDiagnosticHeader(&b, "",
 			"--confirm-shell asks you to approve every shell command, but there's no terminal to ask at.")
@@ -87,7 +87,7 @@ func (r *RadRunner) runPromptPreflight(invoked *ScriptCommand) {
 		return
 	}
 
-	emitPreflight(preflightMessage(preflightScriptName(), r.scriptData.Src, sites, unanswered))
+	emitPreflight(preflightMessage(preflightScriptName(), script.Src, sites, unanswered))
 	RExit.Exit(exitPromptsNeedAnswers)
 }
 
@@ -426,9 +426,11 @@ func survivingOptions(s prompts.Site) []string {
 	return kept
 }
 
+// commandPath is cmd's path within the script that declares it, which for a
+// bundle's command file starts below the file itself.
 func commandPath(cmd *ScriptCommand) []string {
 	var path []string
-	for c := cmd; c != nil; c = c.Parent {
+	for c := cmd; c != nil && c.Script == nil; c = c.Parent {
 		path = append([]string{c.Name}, path...)
 	}
 	return path
@@ -98,6 +98,7 @@ type Printer interface {
 }
 
 // isShellMode directs all regular output to stderr, to avoid interfering with shell evals
+// or with machine-readable --output
 //
 // isQuiet
 // suppresses all output except shell eval prints and rad usage errors, unless isDebug is true, in which
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"time"
 
@@ -111,6 +112,52 @@ func resolveEpochUnit(f FuncInvocation, funcName string, absEpoch int64, unit st
 	return second, nanoSecond, fracMultiplier, nil
 }
 
+// epochToTime reads an int or float epoch in the given unit, as parse_epoch
+// does. A non-nil errVal is ready to return as-is.
+func epochToTime(f FuncInvocation, funcName string, epochArg RadValue, unit string) (time.Time, *RadValue) {
+	var isFloat bool
+	var epochInt int64
+	var fracPart float64
+	var isNegative bool
+
+	switch epochArg.Type() {
+	case rl.RadIntT:
+		epochInt = epochArg.RequireInt(f.i, f.callNode)
+		isNegative = epochInt < 0
+	case rl.RadFloatT:
+		isFloat = true
+		epochFloat := epochArg.RequireFloatAllowingInt(f.i, f.callNode)
+		isNegative = epochFloat < 0
+		epochInt = int64(epochFloat)
+		fracPart = epochFloat - float64(epochInt)
+	default:
+		bugIncorrectTypes(funcName)
+	}
+
+	absEpoch := epochInt
+	absFracPart := fracPart
+	if isNegative {
+		absEpoch = -absEpoch
+		absFracPart = -absFracPart
+	}
+
+	second, nanoSecond, fracMultiplier, errVal := resolveEpochUnit(f, funcName, absEpoch, unit)
+	if errVal != nil {
+		return time.Time{}, errVal
+	}
+
+	if isFloat {
+		nanoSecond += int64(math.Round(absFracPart * fracMultiplier))
+	}
+
+	if isNegative {
+		second = -second
+		nanoSecond = -nanoSecond
+	}
+
+	return time.Unix(second, nanoSecond), nil
+}
+
 // resolveTimeLocation resolves a user-provided tz string ("local" or an IANA
 // name) to a location. A non-nil errVal is ready to return as-is.
 func resolveTimeLocation(f FuncInvocation, tz string) (*time.Location, *RadValue) {
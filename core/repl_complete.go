@@ -0,0 +1,161 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/rl"
+)
+
+// replCompletions returns what could finish the word at the end of before, and
+// the offset where that word starts - the span a completion replaces.
+//
+// Completion works from the live session rather than from the source, because
+// the session is the only thing that knows what `resp` turned out to hold.
+// After a dot it offers the receiver's map keys or attributes, then the
+// builtins it could be passed to by UFCS; anywhere else, your variables and
+// the builtins.
+func (s *ReplSession) replCompletions(before string) (start int, candidates []string) {
+	start = len(before)
+	for start > 0 && isIdentByte(before[start-1]) {
+		start--
+	}
+	prefix := before[start:]
+
+	var groups [][]string
+	if start > 0 && before[start-1] == '.' {
+		receiver, ok := s.resolveReceiver(before[:start-1])
+		if !ok {
+			return start, nil
+		}
+		groups = [][]string{replAttributeNames(receiver), publicBuiltInNames()}
+	} else {
+		groups = [][]string{s.sessionVarNames(), publicBuiltInNames()}
+	}
+
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		sort.Strings(group)
+		for _, name := range group {
+			if strings.HasPrefix(name, prefix) && !seen[name] {
+				seen[name] = true
+				candidates = append(candidates, name)
+			}
+		}
+	}
+	return start, candidates
+}
+
+// resolveReceiver looks up the dotted path that text ends in, e.g. `resp.body`,
+// without running anything. Completion must never have side effects, so a
+// receiver that can only be known by calling something - `load()` - is not
+// resolved at all.
+func (s *ReplSession) resolveReceiver(text string) (RadValue, bool) {
+	end := len(text)
+	start := end
+	for start > 0 && (isIdentByte(text[start-1]) || text[start-1] == '.') {
+		start--
+	}
+	path := strings.Split(text[start:end], ".")
+	if path[0] == "" || isDigitByte(path[0][0]) {
+		return RAD_NULL_VAL, false
+	}
+
+	val, ok := s.interpreter.env.GetVar(path[0])
+	if !ok {
+		return RAD_NULL_VAL, false
+	}
+	for _, name := range path[1:] {
+		if val, ok = replAttribute(val, name); !ok {
+			return RAD_NULL_VAL, false
+		}
+	}
+	return val, true
+}
+
+// replAttribute is `val.name` for the values where that is a lookup.
+func replAttribute(val RadValue, name string) (RadValue, bool) {
+	switch coerced := val.Val.(type) {
+	case *RadMap:
+		return coerced.Get(newRadValueStr(name))
+	case RadDatetime:
+		return coerced.Field(name)
+	case RadDuration:
+		return coerced.Field(name)
+	default:
+		return RAD_NULL_VAL, false
+	}
+}
+
+// replAttributeNames lists what can follow a dot on val. Map keys that aren't
+// identifiers can only be reached by indexing, so they're left out.
+func replAttributeNames(val RadValue) []string {
+	var keys []RadValue
+	switch coerced := val.Val.(type) {
+	case *RadMap:
+		keys = coerced.Keys()
+	case RadDatetime:
+		keys = append(NewTimeMap(time.Time{}).Keys(), newRadValueStr("tz"))
+	case RadDuration:
+		keys = NewDurationMap(0).Keys()
+	}
+
+	var names []string
+	for _, key := range keys {
+		if str, ok := key.TryGetStr(); ok && isIdent(str.Plain()) {
+			names = append(names, str.Plain())
+		}
+	}
+	return names
+}
+
+// sessionVarNames lists the variables you have defined, by the same test as
+// :vars - a name still holding its builtin is the builtin, not your variable.
+func (s *ReplSession) sessionVarNames() []string {
+	var names []string
+	for _, name := range s.interpreter.env.AllVarNames() {
+		val, ok := s.interpreter.env.GetVar(name)
+		if !ok {
+			continue
+		}
+		if _, isBuiltIn := FunctionsByName[name]; isBuiltIn && val.Type() == rl.RadFnT {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// publicBuiltInNames lists the builtins, minus the internal ones the runtime
+// wires its own commands up with.
+func publicBuiltInNames() []string {
+	var names []string
+	for name, sig := range rts.FnSignaturesByName {
+		if !sig.IsInternal {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func isIdent(s string) bool {
+	if s == "" || isDigitByte(s[0]) {
+		return false
+	}
+	for idx := 0; idx < len(s); idx++ {
+		if !isIdentByte(s[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || isDigitByte(b) || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func isDigitByte(b byte) bool {
+	return b >= '0' && b <= '9'
+}
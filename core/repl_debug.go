@@ -0,0 +1,200 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	com "github.com/amterp/rad/core/common"
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/rl"
+)
+
+// debugPrompt marks a session at a stop, so it isn't mistaken for a REPL that
+// happens to share the script's variables.
+const debugPrompt = "debug> "
+
+// debugCommands resume a stopped script. Each is also accepted with the ':' of
+// a meta command. Bare, they shadow a variable of the same name, which
+// `print(next)` still reaches.
+var debugCommands = map[string]DebugStep{
+	"continue": DebugContinue,
+	"step":     DebugStepIn,
+	"next":     DebugStepOver,
+	"out":      DebugStepOut,
+}
+
+// replMetaUnavailableAtStop are the meta commands that assume the session owns
+// its variables. At a stop they belong to the script: resetting them would pull
+// its env out from under it, and the transcript :save and :type work from is
+// not how the script got here.
+var replMetaUnavailableAtStop = map[string]bool{
+	":reset": true,
+	":save":  true,
+	":type":  true,
+}
+
+// newDebugSession is a session over a paused script's interpreter rather than
+// one of its own. Its history is the REPL's, since what you type at either is
+// the same language.
+func newDebugSession(i *Interpreter) (*ReplSession, error) {
+	parser, err := rts.NewRadParser()
+	if err != nil {
+		return nil, err
+	}
+	reader := newReplReader(debugPrompt)
+	session := &ReplSession{
+		interpreter: i,
+		reader:      reader,
+		parser:      parser,
+		history:     newSessionHistory(reader),
+	}
+	session.bindEditor()
+	return session, nil
+}
+
+// runStop is the prompt at one stop. It returns how the script resumes, and
+// false once input has run out - the script then runs on rather than waiting
+// on a prompt nobody can answer.
+func (s *ReplSession) runStop(stop *DebugStop) (DebugStep, bool) {
+	s.stop, s.interpreter = stop, stop.i
+	defer func() { s.stop = nil }()
+
+	printDebugStop(stop)
+	for {
+		src, outcome := s.reader.Read(s.history.Entries())
+		switch outcome {
+		case replEOF:
+			RP.Print("\n")
+			return DebugContinue, false
+		case replDiscarded:
+			continue
+		}
+
+		src = ReplTrimTrailingBlankLines(src)
+		trimmed := strings.TrimSpace(src)
+		if trimmed == "" {
+			continue
+		}
+		s.history.Add(src)
+
+		word := strings.TrimPrefix(trimmed, ":")
+		if step, ok := debugCommands[word]; ok {
+			return step, true
+		}
+		if word == "where" {
+			s.metaWhere()
+			continue
+		}
+
+		if isReplMeta(src) {
+			if !s.runReplMeta(src) {
+				// leaving a stop's prompt leaves the script it is in
+				RExit.Exit(1)
+			}
+			continue
+		}
+		s.runSource(src)
+	}
+}
+
+// evalAtStop runs a turn in the paused frame. Its errors are reported rather
+// than fatal: the script is still waiting to carry on.
+func (s *ReplSession) evalAtStop(src string, root *rl.SourceFile) {
+	sd := &ScriptData{
+		ScriptName:        replScriptName,
+		Tree:              s.tree,
+		Src:               src,
+		DisableGlobalOpts: true,
+		DisableArgsBlock:  true,
+	}
+	value, err := s.stop.evaluate(0, root, sd)
+	if err != nil {
+		var evalErr *debugEvalError
+		if errors.As(err, &evalErr) {
+			NewDiagnosticRenderer(RIo.StdErr).Render(evalErr.diag)
+		} else {
+			RP.RadStderrf("%s\n", err)
+		}
+		return
+	}
+	if value != VOID_SENTINEL {
+		RP.Printf("%s\n", ToPrintable(value))
+	}
+}
+
+func printDebugStop(stop *DebugStop) {
+	frame := stop.Frames[0]
+	RP.Printf("Stopped at %s (%s)\n", debugFrameWhere(frame), stop.Reason)
+	if line, ok := debugSourceLine(stop.i, frame.Span); ok {
+		RP.Printf("%5d | %s\n", frame.Span.StartLine(), line)
+	}
+}
+
+// debugFrameWhere is a frame's position, as file:line plus the function it is
+// in, if any.
+func debugFrameWhere(frame DebugFrame) string {
+	where := fmt.Sprintf("%s:%d", frame.Span.File, frame.Span.StartLine())
+	if frame.Name != debugMainFrame {
+		where += " in " + frame.Name
+	}
+	return where
+}
+
+// metaWhere prints the call stack, innermost first.
+func (s *ReplSession) metaWhere() {
+	width := 0
+	for _, frame := range s.stop.Frames {
+		width = max(width, len(frame.Name))
+	}
+	for idx, frame := range s.stop.Frames {
+		marker := "  "
+		if idx == 0 {
+			marker = "> "
+		}
+		RP.Printf("%s%-*s  %s:%d\n", marker, width, frame.Name, frame.Span.File, frame.Span.StartLine())
+	}
+}
+
+// metaStopVars is :vars at a stop: what the paused frame can see, its own
+// variables before the script's.
+func (s *ReplSession) metaStopVars() {
+	shown := 0
+	for _, scope := range s.stop.Frames[0].Scopes() {
+		if len(scope.Vars) == 0 {
+			continue
+		}
+		RP.Printf("%s:\n", scope.Name)
+		for _, v := range scope.Vars {
+			RP.Printf("  %-16s %-8s %s\n", v.Name, TypeAsString(v.Value), ToPrintable(v.Value))
+		}
+		shown += len(scope.Vars)
+	}
+	if shown == 0 {
+		RP.Printf("No variables defined yet.\n")
+	}
+}
+
+func (s *ReplSession) metaStopHelp() {
+	RP.Print(strings.Join(com.Wrap(
+		"The script is paused. Type Rad to run it where the script stopped - "+
+			"assignments change the script's variables - or resume with:",
+		DiagnosticProseWidth(),
+	), "\n") + "\n\n")
+	RP.Print(strings.Join([]string{
+		"  continue         run on to the next breakpoint",
+		"  step             run to the next statement, into a call if it makes one",
+		"  next             run to the next statement, stepping over calls",
+		"  out              run until the current function returns",
+		"  where            the call stack",
+		"",
+		"  :vars            variables visible from here",
+		"  :docs <topic>    docs for a function, error code, or page",
+		"  :time <code>     run code, then say how long it took and what it allocated",
+		"  :http            the latest HTTP request and its response",
+		"  :exit, :quit     end the script here",
+		"",
+		"  Ctrl+D           run on, and stop no more",
+		"",
+	}, "\n"))
+}
@@ -0,0 +1,591 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/amterp/rad/rts/radfmt"
+	"github.com/mattn/go-runewidth"
+)
+
+// replEditor is the REPL's own line editor, for a session at a real terminal.
+// Completion, history search and highlighting all need the buffer and cursor
+// on every keystroke, which a prompt model run to completion never hands back,
+// so the REPL reads the keys itself.
+//
+// It is a plain state machine: handle applies one key, view lays out what to
+// draw. The terminal side - raw mode, escape sequences, painting - lives in
+// editorScreen and readEditorInput, so the editing rules test without one.
+type replEditor struct {
+	prompt     string
+	contPrompt string
+	history    []string
+
+	// complete, search and highlight are the session's; any may be nil.
+	complete  func(before string) (start int, candidates []string)
+	search    func(query string) []string
+	highlight func(src string) string
+
+	buf       []rune
+	cursor    int
+	histPos   int    // index into history; len(history) is the draft
+	draft     []rune // what was typed before Up replaced it
+	status    string // shown under the buffer until the next key
+	searching *editorSearch
+	done      bool
+	outcome   replRead
+}
+
+// editorSearch is an in-progress Ctrl+R. The buffer shows the current match;
+// original is what to put back if the search is abandoned.
+type editorSearch struct {
+	query          []rune
+	matches        []string
+	idx            int
+	original       []rune
+	originalCursor int
+}
+
+// replMaxCandidates caps the completions listed under the buffer. Past that,
+// another letter narrows them faster than reading does.
+const replMaxCandidates = 30
+
+func newReplEditor(prompt string, history []string) *replEditor {
+	return &replEditor{
+		prompt:     prompt,
+		contPrompt: replContPrompt,
+		history:    history,
+		histPos:    len(history),
+	}
+}
+
+func (e *replEditor) text() string {
+	return string(e.buf)
+}
+
+func (e *replEditor) finish(outcome replRead) {
+	e.done, e.outcome = true, outcome
+}
+
+// handle applies one key.
+func (e *replEditor) handle(in editorInput) {
+	e.status = ""
+	if e.searching != nil && e.handleSearch(in) {
+		return
+	}
+
+	switch in.key {
+	case keyRune:
+		e.insert(string(in.r))
+	case keyPaste:
+		e.insert(in.text)
+	case keyEnter:
+		e.enter()
+	case keyNewline:
+		e.newline()
+	case keyTab:
+		e.tab()
+	case keyBackspace:
+		if e.cursor > 0 {
+			e.deleteRange(e.cursor-1, e.cursor)
+		}
+	case keyDelete:
+		e.deleteRange(e.cursor, min(e.cursor+1, len(e.buf)))
+	case keyEOF:
+		if len(e.buf) == 0 {
+			e.finish(replEOF)
+			return
+		}
+		e.deleteRange(e.cursor, min(e.cursor+1, len(e.buf)))
+	case keyInterrupt:
+		e.finish(replDiscarded)
+	case keyLeft:
+		e.cursor = max(e.cursor-1, 0)
+	case keyRight:
+		e.cursor = min(e.cursor+1, len(e.buf))
+	case keyHome:
+		e.cursor = e.lineStart()
+	case keyEnd:
+		e.cursor = e.lineEnd()
+	case keyUp:
+		if e.row() == 0 {
+			e.recall(-1)
+		} else {
+			e.moveRow(-1)
+		}
+	case keyDown:
+		if e.row() == strings.Count(e.text(), "\n") {
+			e.recall(1)
+		} else {
+			e.moveRow(1)
+		}
+	case keyKillLine:
+		e.deleteRange(e.lineStart(), e.lineEnd())
+	case keyKillEnd:
+		e.deleteRange(e.cursor, e.lineEnd())
+	case keyKillWord:
+		start := e.cursor
+		for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+			start--
+		}
+		for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+			start--
+		}
+		e.deleteRange(start, e.cursor)
+	case keySearch:
+		e.searching = &editorSearch{original: e.buf, originalCursor: e.cursor}
+	}
+}
+
+// enter submits when the buffer is finished, by the same rule the line reader
+// applies: a blank line, or nothing left open. Otherwise it breaks the line.
+func (e *replEditor) enter() {
+	line := string(e.buf[e.lineStart():e.lineEnd()])
+	if strings.TrimSpace(line) == "" || !ReplNeedsMore(e.text()) {
+		e.finish(replSubmitted)
+		return
+	}
+	e.newline()
+}
+
+func (e *replEditor) newline() {
+	e.insert("\n" + ReplAutoIndent(string(e.buf[:e.cursor])))
+}
+
+// tab completes the word before the cursor. At the start of a line there is
+// no word, and Tab indents instead - the other thing it could mean there.
+func (e *replEditor) tab() {
+	before := string(e.buf[:e.cursor])
+	lineBefore := before[strings.LastIndex(before, "\n")+1:]
+	if strings.TrimSpace(lineBefore) == "" || e.complete == nil {
+		e.insert(radfmt.IndentUnit)
+		return
+	}
+
+	start, candidates := e.complete(before)
+	word := before[start:]
+	switch len(candidates) {
+	case 0:
+		return
+	case 1:
+		e.insert(candidates[0][len(word):])
+		return
+	}
+	if common := commonPrefix(candidates); len(common) > len(word) {
+		e.insert(common[len(word):])
+		return
+	}
+
+	shown := candidates
+	if len(shown) > replMaxCandidates {
+		shown = shown[:replMaxCandidates]
+	}
+	e.status = strings.Join(shown, "  ")
+	if hidden := len(candidates) - len(shown); hidden > 0 {
+		e.status += fmt.Sprintf("  (%d more)", hidden)
+	}
+}
+
+// handleSearch applies a key to a Ctrl+R in progress, reporting whether it was
+// the search's to handle. Any other key accepts the match and then does what
+// it would have anyway - so Enter runs it, as in a shell.
+func (e *replEditor) handleSearch(in editorInput) bool {
+	s := e.searching
+	switch in.key {
+	case keyRune:
+		s.query = append(s.query, in.r)
+		e.runSearch(0)
+	case keyBackspace:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+		}
+		e.runSearch(0)
+	case keySearch:
+		if s.matches == nil {
+			e.runSearch(0)
+		} else {
+			e.runSearch(s.idx + 1)
+		}
+	case keyCancel:
+		e.buf, e.cursor = s.original, s.originalCursor
+		e.searching = nil
+	default:
+		e.searching = nil
+		return false
+	}
+	return true
+}
+
+// runSearch shows match idx of the current query, holding at the oldest match
+// rather than wrapping around to the newest.
+func (e *replEditor) runSearch(idx int) {
+	s := e.searching
+	s.matches = []string{} // searched, as opposed to nil: not yet
+	if e.search != nil {
+		if found := e.search(string(s.query)); found != nil {
+			s.matches = found
+		}
+	}
+	if len(s.matches) == 0 {
+		return
+	}
+	s.idx = min(idx, len(s.matches)-1)
+	e.buf = []rune(s.matches[s.idx])
+	e.cursor = len(e.buf)
+}
+
+func (e *replEditor) searchStatus() string {
+	s := e.searching
+	status := fmt.Sprintf("search: %s", string(s.query))
+	if s.matches != nil && len(s.matches) == 0 {
+		status += "  (no match)"
+	}
+	return status
+}
+
+// recall steps through history, keeping whatever was being typed as the
+// entry past the newest so Down gets it back.
+func (e *replEditor) recall(delta int) {
+	pos := e.histPos + delta
+	if pos < 0 || pos > len(e.history) {
+		return
+	}
+	if e.histPos == len(e.history) {
+		e.draft = e.buf
+	}
+	e.histPos = pos
+	if pos == len(e.history) {
+		e.buf = e.draft
+	} else {
+		e.buf = []rune(e.history[pos])
+	}
+	e.cursor = len(e.buf)
+}
+
+func (e *replEditor) insert(s string) {
+	runes := []rune(s)
+	buf := make([]rune, 0, len(e.buf)+len(runes))
+	buf = append(buf, e.buf[:e.cursor]...)
+	buf = append(buf, runes...)
+	e.buf = append(buf, e.buf[e.cursor:]...)
+	e.cursor += len(runes)
+}
+
+func (e *replEditor) deleteRange(start, end int) {
+	if start >= end {
+		return
+	}
+	buf := make([]rune, 0, len(e.buf)-(end-start))
+	buf = append(buf, e.buf[:start]...)
+	e.buf = append(buf, e.buf[end:]...)
+	e.cursor = start
+}
+
+func (e *replEditor) lineStart() int {
+	idx := e.cursor
+	for idx > 0 && e.buf[idx-1] != '\n' {
+		idx--
+	}
+	return idx
+}
+
+func (e *replEditor) lineEnd() int {
+	idx := e.cursor
+	for idx < len(e.buf) && e.buf[idx] != '\n' {
+		idx++
+	}
+	return idx
+}
+
+func (e *replEditor) row() int {
+	return strings.Count(string(e.buf[:e.cursor]), "\n")
+}
+
+// moveRow moves the cursor a line up or down, keeping its column where the
+// line is long enough to.
+func (e *replEditor) moveRow(delta int) {
+	col := e.cursor - e.lineStart()
+	if delta < 0 {
+		e.cursor = e.lineStart() - 1
+	} else {
+		e.cursor = e.lineEnd() + 1
+	}
+	e.cursor = min(e.lineStart()+col, e.lineEnd())
+}
+
+// editorView is one frame: the lines to draw, their display widths, and where
+// the cursor goes.
+type editorView struct {
+	lines    []string
+	widths   []int
+	row, col int
+}
+
+// view lays out the buffer, highlighted when there's a highlighter. Widths come
+// from the plain text: colour codes take no room on screen.
+func (e *replEditor) view() editorView {
+	var v editorView
+	plain := strings.Split(e.text(), "\n")
+	drawn := plain
+	if e.highlight != nil {
+		// Highlighting colours a line at a time, so its lines pair with ours.
+		if painted := strings.Split(e.highlight(e.text()), "\n"); len(painted) == len(plain) {
+			drawn = painted
+		}
+	}
+	for idx, line := range plain {
+		prefix := e.contPrompt
+		if idx == 0 {
+			prefix = e.prompt
+		}
+		v.lines = append(v.lines, prefix+drawn[idx])
+		v.widths = append(v.widths, runewidth.StringWidth(prefix+line))
+	}
+
+	before := string(e.buf[:e.cursor])
+	v.row = strings.Count(before, "\n")
+	prefix := e.contPrompt
+	if v.row == 0 {
+		prefix = e.prompt
+	}
+	v.col = runewidth.StringWidth(prefix + before[strings.LastIndex(before, "\n")+1:])
+
+	status := e.status
+	if e.searching != nil {
+		status = e.searchStatus()
+	}
+	if status != "" {
+		v.lines = append(v.lines, status)
+		v.widths = append(v.widths, runewidth.StringWidth(status))
+	}
+	return v
+}
+
+// commonPrefix shortens by whole runes, so a completion never ends partway
+// through a character two candidates encode differently.
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// editorScreen paints frames in place. It remembers where the last frame left
+// the cursor, so the next can start from the top of the last one.
+type editorScreen struct {
+	out       io.Writer
+	cursorRow int // rows the cursor sits below the top of the last frame
+	endRow    int // rows the last frame's end sits below its top
+}
+
+func (s *editorScreen) draw(v editorView) {
+	width := max(GetTermWidth(), 1)
+	var sb strings.Builder
+	if s.cursorRow > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dA", s.cursorRow)
+	}
+	sb.WriteString("\r\x1b[J")
+
+	top, targetRow, endRow := 0, 0, 0
+	for idx, line := range v.lines {
+		if idx > 0 {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(line)
+		if idx == v.row {
+			targetRow = top + v.col/width
+		}
+		// A line exactly filling the width leaves the cursor on its last
+		// column rather than wrapping, so it ends on the row it filled.
+		endRow = top + (max(v.widths[idx], 1)-1)/width
+		top = endRow + 1
+	}
+	if targetRow > endRow {
+		// The cursor belongs at the start of a row the text hasn't opened.
+		sb.WriteString("\r\n")
+		endRow = targetRow
+	}
+
+	if up := endRow - targetRow; up > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dA", up)
+	}
+	sb.WriteString("\r")
+	if col := v.col % width; col > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dC", col)
+	}
+	s.cursorRow, s.endRow = targetRow, endRow
+	io.WriteString(s.out, sb.String())
+}
+
+// end leaves the cursor on a fresh line below the last frame.
+func (s *editorScreen) end() {
+	if down := s.endRow - s.cursorRow; down > 0 {
+		fmt.Fprintf(s.out, "\x1b[%dB", down)
+	}
+	io.WriteString(s.out, "\r\n")
+	s.cursorRow, s.endRow = 0, 0
+}
+
+type editorKey int
+
+const (
+	keyNone editorKey = iota
+	keyRune
+	keyEnter
+	keyNewline // Alt+Enter: break the line even where Enter would submit
+	keyTab
+	keyBackspace
+	keyDelete
+	keyLeft
+	keyRight
+	keyUp
+	keyDown
+	keyHome
+	keyEnd
+	keyPaste
+	keyKillLine  // Ctrl+U
+	keyKillEnd   // Ctrl+K
+	keyKillWord  // Ctrl+W
+	keySearch    // Ctrl+R
+	keyCancel    // Esc, Ctrl+G
+	keyInterrupt // Ctrl+C
+	keyEOF       // Ctrl+D
+)
+
+type editorInput struct {
+	key  editorKey
+	r    rune   // for keyRune
+	text string // for keyPaste
+}
+
+// Bracketed paste has the terminal mark pasted text, which is how a pasted
+// newline is told from a pressed Enter and a whole block arrives before any of
+// it runs.
+const (
+	pasteModeOn  = "\x1b[?2004h"
+	pasteModeOff = "\x1b[?2004l"
+	pasteEnd     = "\x1b[201~"
+)
+
+var editorControlKeys = map[rune]editorKey{
+	0x01: keyHome,
+	0x02: keyLeft,
+	0x03: keyInterrupt,
+	0x04: keyEOF,
+	0x05: keyEnd,
+	0x06: keyRight,
+	0x07: keyCancel,
+	0x08: keyBackspace,
+	'\t': keyTab,
+	'\n': keyEnter,
+	0x0b: keyKillEnd,
+	'\r': keyEnter,
+	0x0e: keyDown,
+	0x10: keyUp,
+	0x12: keySearch,
+	0x15: keyKillLine,
+	0x17: keyKillWord,
+	0x7f: keyBackspace,
+}
+
+// readEditorInput reads one key from a terminal in raw mode.
+func readEditorInput(in *bufio.Reader) (editorInput, error) {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return editorInput{}, err
+	}
+	if r == 0x1b {
+		return readEscape(in)
+	}
+	if key, ok := editorControlKeys[r]; ok {
+		return editorInput{key: key}, nil
+	}
+	if unicode.IsControl(r) {
+		return editorInput{key: keyNone}, nil
+	}
+	return editorInput{key: keyRune, r: r}, nil
+}
+
+// readEscape reads what follows an Esc. A terminal writes an escape sequence
+// in one go, so an Esc with nothing queued behind it is the key on its own.
+func readEscape(in *bufio.Reader) (editorInput, error) {
+	if in.Buffered() == 0 {
+		return editorInput{key: keyCancel}, nil
+	}
+	next, _, err := in.ReadRune()
+	if err != nil {
+		return editorInput{}, err
+	}
+	switch next {
+	case '\r':
+		return editorInput{key: keyNewline}, nil
+	case '[', 'O':
+	default:
+		return editorInput{key: keyNone}, nil // Alt+<key>, unbound
+	}
+
+	var params []byte
+	for {
+		b, err := in.ReadByte()
+		if err != nil {
+			return editorInput{}, err
+		}
+		if b >= 0x40 && b <= 0x7e {
+			if b == '~' && string(params) == "200" {
+				return readPaste(in)
+			}
+			return editorInput{key: escapeKey(b, string(params))}, nil
+		}
+		params = append(params, b)
+	}
+}
+
+// readPaste reads pasted text up to the terminal's end marker.
+func readPaste(in *bufio.Reader) (editorInput, error) {
+	var sb strings.Builder
+	for !strings.HasSuffix(sb.String(), pasteEnd) {
+		b, err := in.ReadByte()
+		if err != nil {
+			return editorInput{}, err
+		}
+		sb.WriteByte(b)
+	}
+	// Terminals send a pasted line break as the Enter key's \r.
+	text := NormalizeLineEndings(strings.TrimSuffix(sb.String(), pasteEnd))
+	return editorInput{key: keyPaste, text: strings.ReplaceAll(text, "\r", "\n")}, nil
+}
+
+func escapeKey(final byte, params string) editorKey {
+	switch final {
+	case 'A':
+		return keyUp
+	case 'B':
+		return keyDown
+	case 'C':
+		return keyRight
+	case 'D':
+		return keyLeft
+	case 'H':
+		return keyHome
+	case 'F':
+		return keyEnd
+	case '~':
+		switch params {
+		case "1", "7":
+			return keyHome
+		case "4", "8":
+			return keyEnd
+		case "3":
+			return keyDelete
+		}
+	}
+	return keyNone
+}
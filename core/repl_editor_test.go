@@ -0,0 +1,212 @@
+package core
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/amterp/rad/rts/radfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+// typeKeys feeds s to e a rune at a time, as typing it would.
+func typeKeys(e *replEditor, s string) {
+	for _, r := range s {
+		e.handle(editorInput{key: keyRune, r: r})
+	}
+}
+
+func press(e *replEditor, keys ...editorKey) {
+	for _, key := range keys {
+		e.handle(editorInput{key: key})
+	}
+}
+
+func fixedCompletions(names ...string) func(string) (int, []string) {
+	return func(before string) (int, []string) {
+		start := len(before)
+		for start > 0 && isIdentByte(before[start-1]) {
+			start--
+		}
+		var candidates []string
+		for _, name := range names {
+			if strings.HasPrefix(name, before[start:]) {
+				candidates = append(candidates, name)
+			}
+		}
+		return start, candidates
+	}
+}
+
+func TestReplEditor_EnterSubmitsFinishedInput(t *testing.T) {
+	e := newReplEditor(replPrompt, nil)
+	typeKeys(e, "x = 1")
+	press(e, keyEnter)
+	assert.True(t, e.done)
+	assert.Equal(t, replSubmitted, e.outcome)
+	assert.Equal(t, "x = 1", e.text())
+}
+
+func TestReplEditor_EnterContinuesABlockUntilABlankLine(t *testing.T) {
+	e := newReplEditor(replPrompt, nil)
+	typeKeys(e, "if true:")
+	press(e, keyEnter)
+	typeKeys(e, "print(1)")
+	press(e, keyEnter)
+	assert.False(t, e.done)
+	assert.Equal(t, "if true:\n"+radfmt.IndentUnit+"print(1)\n"+radfmt.IndentUnit, e.text())
+
+	press(e, keyEnter)
+	assert.True(t, e.done)
+}
+
+func TestReplEditor_TabCompletesAUniqueMatch(t *testing.T) {
+	e := newReplEditor(replPrompt, nil)
+	e.complete = fixedCompletions("response", "result")
+	typeKeys(e, "print(resp")
+	press(e, keyTab)
+	assert.Equal(t, "print(response", e.text())
+	assert.Empty(t, e.status)
+}
+
+func TestReplEditor_TabFillsTheCommonPrefixThenLists(t *testing.T) {
+	e := newReplEditor(replPrompt, nil)
+	e.complete = fixedCompletions("items_a", "items_b", "other")
+	typeKeys(e, "it")
+	press(e, keyTab)
+	assert.Equal(t, "items_", e.text())
+
+	press(e, keyTab)
+	assert.Equal(t, "items_", e.text())
+	assert.Equal(t, "items_a  items_b", e.status)
+	assert.Equal(t, "items_a  items_b", e.view().lines[1])
+
+	typeKeys(e, "a")
+	assert.Empty(t, e.status, "the list is gone at the next key")
+}
+
+func TestReplEditor_TabStopsTheCommonPrefixAtAWholeCharacter(t *testing.T) {
+	e := newReplEditor(replPrompt, nil)
+	// é and è share their first utf-8 byte.
+	e.complete = fixedCompletions("café_a", "cafè_b")
+	typeKeys(e, "c")
+	press(e, keyTab)
+	assert.Equal(t, "caf", e.text())
+}
+
+func TestReplEditor_TabIndentsAtTheStartOfALine(t *testing.T) {
+	e := newReplEditor(replPrompt, nil)
+	e.complete = fixedCompletions("x")
+	press(e, keyTab)
+	assert.Equal(t, radfmt.IndentUnit, e.text())
+}
+
+func TestReplEditor_CtrlRStepsBackThroughMatches(t *testing.T) {
+	history := []string{"x = 1", "print(x)", "y = 2"}
+	e := newReplEditor(replPrompt, history)
+	e.search = (&replHistory{entries: history, limit: -1}).Search
+	typeKeys(e, "draft")
+
+	press(e, keySearch)
+	typeKeys(e, "x")
+	assert.Equal(t, "print(x)", e.text())
+	assert.Equal(t, "search: x", e.view().lines[1])
+
+	press(e, keySearch)
+	assert.Equal(t, "x = 1", e.text())
+	press(e, keySearch)
+	assert.Equal(t, "x = 1", e.text(), "the oldest match holds")
+
+	press(e, keyCancel)
+	assert.Equal(t, "draft", e.text())
+	assert.Nil(t, e.searching)
+}
+
+func TestReplEditor_CtrlREnterRunsTheMatch(t *testing.T) {
+	history := []string{"x = 1", "y = 2"}
+	e := newReplEditor(replPrompt, history)
+	e.search = (&replHistory{entries: history, limit: -1}).Search
+
+	press(e, keySearch)
+	typeKeys(e, "x =")
+	press(e, keyEnter)
+	assert.True(t, e.done)
+	assert.Equal(t, "x = 1", e.text())
+}
+
+func TestReplEditor_CtrlRReportsNoMatch(t *testing.T) {
+	e := newReplEditor(replPrompt, []string{"x = 1"})
+	e.search = (&replHistory{entries: []string{"x = 1"}, limit: -1}).Search
+	press(e, keySearch)
+	typeKeys(e, "zzz")
+	assert.Equal(t, "", e.text())
+	assert.Equal(t, "search: zzz  (no match)", e.view().lines[1])
+}
+
+func TestReplEditor_UpRecallsHistoryAndDownRestoresTheDraft(t *testing.T) {
+	e := newReplEditor(replPrompt, []string{"a = 1", "b = 2"})
+	typeKeys(e, "dra")
+	press(e, keyUp)
+	assert.Equal(t, "b = 2", e.text())
+	press(e, keyUp, keyUp)
+	assert.Equal(t, "a = 1", e.text())
+	press(e, keyDown, keyDown)
+	assert.Equal(t, "dra", e.text())
+}
+
+func TestReplEditor_CtrlDLeavesOnlyOnAnEmptyLine(t *testing.T) {
+	e := newReplEditor(replPrompt, nil)
+	typeKeys(e, "ab")
+	press(e, keyHome, keyEOF)
+	assert.False(t, e.done)
+	assert.Equal(t, "b", e.text())
+
+	press(e, keyEOF)
+	assert.False(t, e.done)
+	assert.Equal(t, "", e.text())
+
+	press(e, keyEOF)
+	assert.True(t, e.done)
+	assert.Equal(t, replEOF, e.outcome)
+}
+
+func TestReplEditor_ViewPlacesTheCursor(t *testing.T) {
+	e := newReplEditor(replPrompt, nil)
+	e.handle(editorInput{key: keyPaste, text: "if true:\n    x = 1"})
+	press(e, keyLeft)
+	v := e.view()
+	assert.Equal(t, []string{"> if true:", ". " + "    x = 1"}, v.lines)
+	assert.Equal(t, 1, v.row)
+	assert.Equal(t, len(". "+"    x = "), v.col)
+}
+
+func TestReplEditor_ViewHighlightsWithoutChangingWidths(t *testing.T) {
+	e := newReplEditor(replPrompt, nil)
+	e.highlight = func(src string) string {
+		return strings.ReplaceAll(src, "print", "\x1b[34mprint\x1b[0m")
+	}
+	typeKeys(e, "print(1)")
+	v := e.view()
+	assert.Equal(t, []string{"> \x1b[34mprint\x1b[0m(1)"}, v.lines)
+	assert.Equal(t, []int{len("> print(1)")}, v.widths)
+	assert.Equal(t, len("> print(1)"), v.col)
+}
+
+func TestReadEditorInput(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("a\x12\t\x1b[A\x1b[3~\x1b[200~x = 1\ry\x1b[201~é\r"))
+	want := []editorInput{
+		{key: keyRune, r: 'a'},
+		{key: keySearch},
+		{key: keyTab},
+		{key: keyUp},
+		{key: keyDelete},
+		{key: keyPaste, text: "x = 1\ny"},
+		{key: keyRune, r: 'é'},
+		{key: keyEnter},
+	}
+	for _, w := range want {
+		got, err := readEditorInput(in)
+		assert.NoError(t, err)
+		assert.Equal(t, w, got)
+	}
+}
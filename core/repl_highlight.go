@@ -0,0 +1,71 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/amterp/color"
+	"github.com/amterp/rad/rts/check"
+)
+
+// replTokenColors is the REPL's palette. Variables and parameters stay
+// uncoloured: they are most of any line, and colouring them would leave the
+// line as loud as if nothing were.
+var replTokenColors = map[check.TokenClass]*color.Color{
+	check.TokenKeyword:  color.New(color.FgMagenta),
+	check.TokenString:   color.New(color.FgGreen),
+	check.TokenNumber:   color.New(color.FgYellow),
+	check.TokenComment:  color.New(color.FgHiBlack),
+	check.TokenFunction: color.New(color.FgBlue),
+	check.TokenType:     color.New(color.FgCyan),
+}
+
+// highlight colours src the way an editor would, from the same classification
+// radls gives editors. Like the checker, the resolver only sees src, so a name
+// defined in an earlier turn goes uncoloured - it's unresolved, not wrong.
+func (s *ReplSession) highlight(src string) string {
+	if color.NoColor {
+		return src
+	}
+
+	s.reparse(src)
+	root := s.tree.Root()
+	ast := tryConvertAST(s.tree, src, replScriptName)
+	var resolved *check.Resolved
+	if ast != nil {
+		resolved = check.Resolve(ast)
+	}
+
+	tokens := append(check.LexicalTokens(root), check.SemanticTokens(ast, resolved, root)...)
+	return paintTokens(src, tokens)
+}
+
+// paintTokens wraps each token's text in its class's colour. Colour is applied
+// a line at a time so a token spanning lines - a multi-line string - doesn't
+// bleed into whatever gets printed at the start of the next line.
+func paintTokens(src string, tokens []check.Token) string {
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].StartByte < tokens[j].StartByte
+	})
+
+	var sb strings.Builder
+	pos := 0
+	for _, token := range tokens {
+		c, ok := replTokenColors[token.Class]
+		if !ok || token.StartByte < pos || token.EndByte > len(src) {
+			continue
+		}
+		sb.WriteString(src[pos:token.StartByte])
+		for idx, line := range strings.Split(src[token.StartByte:token.EndByte], "\n") {
+			if idx > 0 {
+				sb.WriteString("\n")
+			}
+			if line != "" {
+				sb.WriteString(c.Sprint(line))
+			}
+		}
+		pos = token.EndByte
+	}
+	sb.WriteString(src[pos:])
+	return sb.String()
+}
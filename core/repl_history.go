@@ -0,0 +1,130 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// replHistoryFileName is the file under rad home that carries input from one
+// session into the next.
+const replHistoryFileName = "repl_history"
+
+// replHistory is the input you have typed, oldest first. A submitted entry moves
+// to the end rather than being stored twice, so Up walks back through distinct
+// inputs instead of the same one repeated.
+//
+// An entry can span lines - a block is one turn - so the file holds one JSON
+// string per line rather than the raw text, which would split it.
+type replHistory struct {
+	path    string // "" keeps history in memory only
+	limit   int
+	entries []string
+}
+
+// loadReplHistory reads the history at path, keeping the newest limit entries.
+// A missing file is an empty history; one that can't be read is too, because a
+// lost history is no reason to refuse a session.
+func loadReplHistory(path string, limit int) *replHistory {
+	h := &replHistory{path: path, limit: limit}
+	if path == "" {
+		return h
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			RP.RadDebugf("Cannot read REPL history %s: %v", path, err)
+		}
+		return h
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry string
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// one mangled line shouldn't cost you the rest
+			continue
+		}
+		h.push(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		RP.RadDebugf("Cannot read REPL history %s: %v", path, err)
+	}
+	h.trim()
+	return h
+}
+
+// Entries returns the history oldest first, as the editor's Up/Down expects.
+func (h *replHistory) Entries() []string {
+	return h.entries
+}
+
+// Add records a submitted turn and saves the history.
+func (h *replHistory) Add(entry string) {
+	h.push(entry)
+	h.trim()
+	h.save()
+}
+
+// Search returns the entries containing query, newest first - the order a
+// reverse search steps through them.
+func (h *replHistory) Search(query string) []string {
+	var matches []string
+	for idx := len(h.entries) - 1; idx >= 0; idx-- {
+		if strings.Contains(h.entries[idx], query) {
+			matches = append(matches, h.entries[idx])
+		}
+	}
+	return matches
+}
+
+func (h *replHistory) push(entry string) {
+	for idx, existing := range h.entries {
+		if existing == entry {
+			h.entries = append(h.entries[:idx], h.entries[idx+1:]...)
+			break
+		}
+	}
+	h.entries = append(h.entries, entry)
+}
+
+func (h *replHistory) trim() {
+	if h.limit >= 0 && len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+	}
+}
+
+// save rewrites the whole file. It is small by construction, and rewriting it
+// through a rename means a crash mid-write leaves the old history, not half of
+// a new one.
+func (h *replHistory) save() {
+	if h.path == "" {
+		return
+	}
+
+	var sb strings.Builder
+	for _, entry := range h.entries {
+		line, _ := json.Marshal(entry)
+		sb.Write(line)
+		sb.WriteString("\n")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		RP.RadDebugf("Cannot save REPL history: %v", err)
+		return
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0600); err != nil {
+		RP.RadDebugf("Cannot save REPL history: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		RP.RadDebugf("Cannot save REPL history: %v", err)
+		_ = os.Remove(tmp)
+	}
+}
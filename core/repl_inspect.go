@@ -0,0 +1,126 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amterp/rad/rts/check"
+	"github.com/amterp/rad/rts/rl"
+	"github.com/dustin/go-humanize"
+)
+
+// metaType prints what the checker makes of expr, without running it.
+//
+// The checker only knows what it can read, so expr is checked at the end of
+// everything that has run this session - that's where `resp` got its type.
+func (s *ReplSession) metaType(expr string) {
+	if expr == "" {
+		RP.RadStderrf("Usage: :type <expression>\n")
+		return
+	}
+
+	var sb strings.Builder
+	for _, turn := range s.transcript {
+		sb.WriteString(turn.src)
+		sb.WriteString("\n")
+	}
+	offset := sb.Len()
+	sb.WriteString(expr)
+	src := sb.String()
+
+	s.reparse(src)
+	var ast *rl.SourceFile
+	if !s.tree.HasInvalidNodes() {
+		ast = tryConvertAST(s.tree, src, replScriptName)
+	}
+	if ast == nil || len(ast.Stmts) == 0 {
+		RP.RadStderrf("Cannot read %q as an expression.\n", expr)
+		return
+	}
+	last, ok := ast.Stmts[len(ast.Stmts)-1].(*rl.ExprStmt)
+	if !ok || last.Span().StartByte < offset {
+		RP.RadStderrf("%q is a statement, not an expression - it has no type.\n", expr)
+		return
+	}
+
+	info := check.TypeCheck(ast, check.Resolve(ast))
+	t, ok := info.ExprTypes[last.Expr]
+	if !ok || t == nil {
+		// no information is the checker's "could be anything"
+		t = rl.NewAnyType()
+	}
+	RP.Printf("%s\n", rl.DisplayName(t))
+}
+
+// metaTime runs src as a normal turn and reports how long it took and what it
+// allocated. Parsing is counted too - it's part of what the turn costs.
+func (s *ReplSession) metaTime(src string) (keepGoing bool) {
+	if src == "" {
+		RP.RadStderrf("Usage: :time <statement>\n")
+		return true
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	keepGoing = s.runSource(src)
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	RP.Printf("Took %s, %s allocations (%s).\n",
+		elapsed.Round(time.Microsecond),
+		humanize.Comma(int64(after.Mallocs-before.Mallocs)),
+		humanize.Bytes(after.TotalAlloc-before.TotalAlloc))
+	return keepGoing
+}
+
+// metaHttp shows the latest request and what came back, which is what you
+// want after a `resp = http_get(...)` that didn't return what you expected.
+func (s *ReplSession) metaHttp() {
+	exchange, ok := RReq.LastExchange()
+	if !ok {
+		RP.Printf("No HTTP requests yet.\n")
+		return
+	}
+	req := exchange.RequestDef
+	resp := exchange.ResponseDef
+
+	RP.Printf("%s %s\n", req.Method, req.Url)
+	if resp.Error != nil {
+		RP.Printf("Failed: %s\n", *resp.Error)
+		return
+	}
+	if resp.StatusCode != nil {
+		RP.Printf("%d in %.3fs\n", *resp.StatusCode, resp.DurationSeconds)
+	}
+
+	if resp.Headers != nil {
+		keys := make([]string, 0, len(*resp.Headers))
+		for key := range *resp.Headers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			RP.Printf("  %s: %s\n", key, strings.Join((*resp.Headers)[key], ", "))
+		}
+	}
+
+	if resp.Body != nil && *resp.Body != "" {
+		RP.Printf("\n%s\n", replHttpBody(*resp.Body))
+	}
+}
+
+// replHttpBody indents a JSON body so its shape can be read; anything else is
+// shown as it arrived.
+func replHttpBody(body string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(body), "", "  "); err != nil {
+		return strings.TrimRight(body, "\n")
+	}
+	return buf.String()
+}
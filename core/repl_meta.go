@@ -26,6 +26,11 @@ func (s *ReplSession) runReplMeta(src string) (keepGoing bool) {
 	cmd := fields[0]
 	args := fields[1:]
 
+	if s.stop != nil && replMetaUnavailableAtStop[cmd] {
+		RP.RadStderrf("%s isn't available while the script is paused. Try :help.\n", cmd)
+		return true
+	}
+
 	switch cmd {
 	case ":exit", ":quit":
 		return false
@@ -37,6 +42,20 @@ func (s *ReplSession) runReplMeta(src string) (keepGoing bool) {
 		s.metaDocs(args)
 	case ":load":
 		s.metaLoad(args)
+	case ":history":
+		s.metaHistory(src, metaRest(src))
+	case ":complete":
+		s.metaComplete(metaRest(src))
+	case ":type":
+		s.metaType(metaRest(src))
+	case ":time":
+		return s.metaTime(metaRest(src))
+	case ":save":
+		s.metaSave(args)
+	case ":edit":
+		return s.metaEdit()
+	case ":http":
+		s.metaHttp()
 	case ":clear":
 		RP.Print("\033[H\033[2J")
 	case ":reset":
@@ -49,6 +68,10 @@ func (s *ReplSession) runReplMeta(src string) (keepGoing bool) {
 }
 
 func (s *ReplSession) metaHelp() {
+	if s.stop != nil {
+		s.metaStopHelp()
+		return
+	}
 	RP.Print(strings.Join(com.Wrap(
 		"Type Rad and press Enter to run it. A block construct keeps taking "+
 			"lines until you enter a blank one, which is also how you get out "+
@@ -60,12 +83,19 @@ func (s *ReplSession) metaHelp() {
 		"  :vars            variables defined this session",
 		"  :docs <topic>    docs for a function, error code, or page",
 		"  :load <file>     run a .rad file into this session",
+		"  :history [text]  earlier input containing text, newest first",
+		"  :complete <text> what could finish the end of text",
+		"  :type <expr>     the type the checker gives expr, without running it",
+		"  :time <code>     run code, then say how long it took and what it allocated",
+		"  :save <file>     write the statements that ran into a new script",
+		"  :edit            open the latest turn in $EDITOR, then run it",
+		"  :http            the latest HTTP request and its response",
 		"  :clear           clear the screen",
 		"  :reset           forget every variable and start over",
 		"  :exit, :quit     leave (Ctrl+D does too)",
 		"",
 		"  Ctrl+C           abandon the line, or interrupt what is running",
-		"  Up / Down        recall earlier input",
+		"  Up / Down        recall earlier input, from this session or before",
 		"",
 	}, "\n"))
 	RP.Print(strings.Join(com.Wrap(
@@ -83,6 +113,10 @@ func (s *ReplSession) metaHelp() {
 // claims it. Rad lets a variable shadow one, and `count = 3` is exactly the
 // kind of thing you want to see listed.
 func (s *ReplSession) metaVars() {
+	if s.stop != nil {
+		s.metaStopVars()
+		return
+	}
 	names := s.interpreter.env.AllVarNames()
 	sort.Strings(names)
 
@@ -133,7 +167,72 @@ func (s *ReplSession) metaLoad(args []string) {
 	s.runSource(NormalizeLineEndings(string(src)))
 }
 
+// metaRest is everything after the command word, spacing intact - the text
+// :history searches for and :complete completes is Rad, not a list of words.
+func metaRest(src string) string {
+	trimmed := strings.TrimSpace(src)
+	if idx := strings.IndexAny(trimmed, " \t"); idx >= 0 {
+		return strings.TrimLeft(trimmed[idx:], " \t")
+	}
+	return ""
+}
+
+// replHistoryShown caps a :history listing. Past that you want a narrower
+// search, not a longer scroll.
+const replHistoryShown = 20
+
+// metaHistory searches earlier input, the way Ctrl+R steps back through it.
+// The search itself was the latest entry, and is not something you went
+// looking for.
+func (s *ReplSession) metaHistory(src string, query string) {
+	var matches []string
+	for _, entry := range s.history.Search(query) {
+		if entry != src {
+			matches = append(matches, entry)
+		}
+	}
+	if len(matches) == 0 {
+		RP.Printf("No history matches %q.\n", query)
+		return
+	}
+
+	shown := matches
+	if len(shown) > replHistoryShown {
+		shown = shown[:replHistoryShown]
+	}
+	for _, entry := range shown {
+		for idx, line := range strings.Split(s.highlight(entry), "\n") {
+			prefix := "  "
+			if idx > 0 {
+				prefix = "  . "
+			}
+			RP.Printf("%s%s\n", prefix, line)
+		}
+	}
+	if hidden := len(matches) - len(shown); hidden > 0 {
+		RP.Printf("...and %d more. Search for something narrower to see them.\n", hidden)
+	}
+}
+
+// metaComplete lists what could finish the end of text, judged from what the
+// session holds now.
+func (s *ReplSession) metaComplete(text string) {
+	if text == "" {
+		RP.RadStderrf("Usage: :complete <text>\n")
+		return
+	}
+	_, candidates := s.replCompletions(text)
+	if len(candidates) == 0 {
+		RP.Printf("No completions.\n")
+		return
+	}
+	RP.Printf("%s\n", strings.Join(candidates, "  "))
+}
+
 func (s *ReplSession) resetEnv() {
+	s.transcript = nil
 	s.interpreter.env = NewEnv(s.interpreter)
 	s.interpreter.InitBuiltIns()
+	// a reset session imports afresh, picking up edits to its modules
+	s.interpreter.modules = newModuleCache()
 }
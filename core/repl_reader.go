@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/amterp/radish"
+	"golang.org/x/term"
 )
 
 const (
@@ -32,25 +33,39 @@ type replReader interface {
 	Close() error
 }
 
-func newReplReader() replReader {
+func newReplReader(prompt string) replReader {
 	if TerminalAvailable() {
-		return &editorReader{}
+		return &editorReader{prompt: prompt}
 	}
 	return &lineReader{scanner: bufio.NewScanner(RIo.StdIn.Unwrap())}
 }
 
-// editorReader runs radish's multi-line editor for each turn.
+// editorReader reads each turn with the REPL's own editor at a real terminal,
+// and with radish's multi-line editor under a scripted driver - the interactive
+// snapshot tests - which has keys to feed a prompt model but no terminal to
+// hand the key loop.
 //
-// A fresh driver per turn is deliberate, not wasteful: radish restores the
-// terminal when a prompt ends, so the terminal is in raw mode only while you
-// are typing and back in cooked mode while your input runs. That is what lets
-// Ctrl+C reach a running statement as a real signal, and what stops it being
-// one while you are still editing.
-type editorReader struct{}
+// Either way the terminal is in raw mode only while you are typing and back in
+// cooked mode while your input runs. That is what lets Ctrl+C reach a running
+// statement as a real signal, and what stops it being one while you are still
+// editing.
+type editorReader struct {
+	prompt string
+
+	// The session's completion, history search and highlighting, bound once
+	// it exists.
+	complete  func(before string) (start int, candidates []string)
+	search    func(query string) []string
+	highlight func(src string) string
+}
 
 func (r *editorReader) Read(history []string) (string, replRead) {
+	if _, ok := RInteractive.(terminalDriver); ok {
+		return r.readTerminal(history)
+	}
+
 	m := radish.NewEditor().
-		Prompt(replPrompt).
+		Prompt(r.prompt).
 		ContPrompt(replContPrompt).
 		Width(GetTermWidth()).
 		MaxHeight(GetTermHeight()).
@@ -76,6 +91,52 @@ func (r *editorReader) Read(history []string) (string, replRead) {
 	}
 }
 
+// readTerminal runs replEditor on the terminal for one turn.
+func (r *editorReader) readTerminal(history []string) (string, replRead) {
+	t, err := RTerminal.Open()
+	if err != nil {
+		return "", replEOF
+	}
+	defer t.Release()
+
+	fd := int(t.In.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		RP.RadDebugf("REPL editor error: %v", err)
+		return "", replEOF
+	}
+	defer term.Restore(fd, state)
+	io.WriteString(t.Out, pasteModeOn)
+	defer io.WriteString(t.Out, pasteModeOff)
+
+	e := newReplEditor(r.prompt, history)
+	e.complete, e.search, e.highlight = r.complete, r.search, r.highlight
+	screen := &editorScreen{out: t.Out}
+	in := bufio.NewReader(t.In)
+	for !e.done {
+		screen.draw(e.view())
+		key, err := readEditorInput(in)
+		if err != nil {
+			e.finish(replEOF)
+			break
+		}
+		e.handle(key)
+	}
+
+	// The last frame is what stays on screen above the output: the input as
+	// submitted, without a search line or completions under it.
+	e.searching, e.status = nil, ""
+	screen.draw(e.view())
+	if e.outcome == replEOF {
+		return "", replEOF // the session starts the next line itself
+	}
+	screen.end()
+	if e.outcome != replSubmitted {
+		return "", e.outcome
+	}
+	return e.text(), replSubmitted
+}
+
 func (r *editorReader) Close() error { return nil }
 
 // lineReader is the no-terminal path: a piped script, CI, an agent. It applies
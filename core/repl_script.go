@@ -0,0 +1,144 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	com "github.com/amterp/rad/core/common"
+	"github.com/amterp/rad/rts/rl"
+)
+
+// replScriptHeader opens a script saved from a session. The args block is left
+// commented out: a session has no arguments to declare, but adding them is the
+// first thing a prototype needs on its way to being a script.
+const replScriptHeader = `#!/usr/bin/env rad
+---
+Saved from a Rad REPL session.
+---
+// args:
+//     name str # Describe each argument, then uncomment the block.
+
+`
+
+// metaSave writes the session out as a script: every turn that ran without
+// error, minus the reads that only looked at a value. A turn that failed is left
+// out because it changed nothing - the turn you retyped after it is what the
+// session actually ran.
+func (s *ReplSession) metaSave(args []string) {
+	if len(args) == 0 {
+		RP.RadStderrf("Usage: :save <file>\n")
+		return
+	}
+	path := args[0]
+	if com.FileExists(path) {
+		RP.RadStderrf("%s already exists. Save to a new file instead.\n", path)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(replScriptHeader)
+	saved := 0
+	for _, turn := range s.transcript {
+		if turn.script == "" {
+			continue
+		}
+		sb.WriteString(turn.script)
+		sb.WriteString("\n")
+		saved++
+	}
+	if saved == 0 {
+		RP.Printf("Nothing to save yet.\n")
+		return
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0755); err != nil {
+		RP.RadStderrf("Cannot write %s: %v\n", path, err)
+		return
+	}
+	RP.Printf("Saved %s to %s.\n", com.Pluralize(saved, "turn"), path)
+}
+
+// replScriptSource is what a turn contributes to a saved script. A turn ending
+// in a bare read - `resp`, `resp.body` - echoed that value, and the read is
+// dropped; everything before it in the turn ran for real and stays. Any other
+// trailing expression might be a call with effects, so it stays too.
+func replScriptSource(src string, root *rl.SourceFile) string {
+	if len(root.Stmts) == 0 {
+		return src
+	}
+	last, ok := root.Stmts[len(root.Stmts)-1].(*rl.ExprStmt)
+	if !ok || last.Catch != nil || !isBareRead(last.Expr) {
+		return src
+	}
+	return strings.TrimRight(src[:last.Span().StartByte], " \t\n")
+}
+
+// isBareRead reports whether expr only reads a variable or a field of one.
+func isBareRead(expr rl.Node) bool {
+	switch e := expr.(type) {
+	case *rl.Identifier:
+		return true
+	case *rl.VarPath:
+		if _, ok := e.Root.(*rl.Identifier); !ok {
+			return false
+		}
+		for _, seg := range e.Segments {
+			if seg.Field == nil || seg.IsUFCS {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// metaEdit opens the latest Rad turn in $EDITOR and runs what you save, so a
+// block with a mistake on its fourth line doesn't have to be retyped. It runs
+// as a turn of its own, and goes into history like one.
+func (s *ReplSession) metaEdit() (keepGoing bool) {
+	if s.lastSource == "" {
+		RP.Printf("Nothing to edit yet.\n")
+		return true
+	}
+
+	file, err := os.CreateTemp("", "rad-repl-*.rad")
+	if err != nil {
+		RP.RadStderrf("Cannot create a file to edit: %v\n", err)
+		return true
+	}
+	path := file.Name()
+	defer os.Remove(path)
+	_, err = file.WriteString(s.lastSource + "\n")
+	file.Close()
+	if err != nil {
+		RP.RadStderrf("Cannot write %s: %v\n", path, err)
+		return true
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		RP.RadStderrf("Editor %q failed: %v\n", editor[0], err)
+		return true
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		RP.RadStderrf("Cannot read %s: %v\n", path, err)
+		return true
+	}
+	src := strings.TrimRight(NormalizeLineEndings(string(edited)), "\n")
+	if strings.TrimSpace(src) == "" {
+		RP.Printf("Nothing to run.\n")
+		return true
+	}
+	s.history.Add(src)
+	return s.runSource(src)
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"path/filepath"
 	"strings"
 
 	"github.com/amterp/rad/rts"
@@ -15,7 +16,18 @@ type ReplSession struct {
 	reader      replReader
 	parser      *rts.RadParser
 	tree        *rts.RadTree
-	history     []string
+	history     *replHistory
+	transcript  []replTurn // turns that ran cleanly, oldest first
+	lastSource  string     // the latest Rad turn, whether or not it ran
+	stop        *DebugStop // the paused script, while the session is a debugger's
+}
+
+// replTurn is a turn the session ran without error. script is what :save keeps
+// of it: the source minus a trailing look at a value - typing `resp` to see it -
+// which a script made from the session has no use for.
+type replTurn struct {
+	src    string
+	script string
 }
 
 // NewReplSession wires up a session. The parser and tree are held for the whole
@@ -31,11 +43,37 @@ func NewReplSession() (*ReplSession, error) {
 	interpreter.InitBuiltIns()
 	interpreter.RegisterWithExit()
 
-	return &ReplSession{
+	reader := newReplReader(replPrompt)
+	session := &ReplSession{
 		interpreter: interpreter,
-		reader:      newReplReader(),
+		reader:      reader,
 		parser:      parser,
-	}, nil
+		history:     newSessionHistory(reader),
+	}
+	session.bindEditor()
+	return session, nil
+}
+
+// bindEditor gives the editor the session's completion and history search, for
+// Tab and Ctrl+R, and its highlighting for the line being typed. The reader is
+// made first because the history depends on it.
+func (s *ReplSession) bindEditor() {
+	if editor, ok := s.reader.(*editorReader); ok {
+		editor.complete = s.replCompletions
+		editor.search = s.history.Search
+		editor.highlight = s.highlight
+	}
+}
+
+// newSessionHistory loads the history file for a session at a terminal. Piped
+// input is another program's script rather than anything you typed, so it
+// neither sees the file nor adds to it.
+func newSessionHistory(reader replReader) *replHistory {
+	if _, typed := reader.(*editorReader); !typed || RConfig.Repl.HistorySize == 0 {
+		return loadReplHistory("", -1)
+	}
+	path := filepath.Join(RadHomeInst.HomeDir, replHistoryFileName)
+	return loadReplHistory(path, RConfig.Repl.HistorySize)
 }
 
 // Run is the read-eval-print loop.
@@ -53,7 +91,7 @@ func (s *ReplSession) Run() error {
 	printReplBanner()
 
 	for {
-		src, outcome := s.reader.Read(s.history)
+		src, outcome := s.reader.Read(s.history.Entries())
 		switch outcome {
 		case replEOF:
 			RP.Print("\n")
@@ -66,7 +104,7 @@ func (s *ReplSession) Run() error {
 		if strings.TrimSpace(src) == "" {
 			continue
 		}
-		s.history = append(s.history, src)
+		s.history.Add(src)
 
 		if isReplMeta(src) {
 			if !s.runReplMeta(src) {
@@ -87,17 +125,23 @@ func (s *ReplSession) runSource(src string) (keepGoing bool) {
 	// Each turn starts with the exit latch clear, or the first error would
 	// leave every later turn skipping its deferred blocks.
 	RExit.ResetExiting()
+	s.lastSource = src
 
 	root, ok := s.parse(src)
 	if !ok {
 		return true
 	}
+	if s.stop != nil {
+		s.evalAtStop(src, root)
+		return true
+	}
 
 	value, abort := s.interpreter.EvalReplTurn(src, root, s.tree)
 	if abort != nil {
 		return s.handleAbort(abort)
 	}
 
+	s.transcript = append(s.transcript, replTurn{src: src, script: replScriptSource(src, root)})
 	if value != VOID_SENTINEL {
 		RP.Printf("%s\n", ToPrintable(value))
 	}
@@ -107,17 +151,23 @@ func (s *ReplSession) runSource(src string) (keepGoing bool) {
 // parse reparses the session's tree in place and converts it, reporting syntax
 // errors rather than letting the converter panic on them.
 func (s *ReplSession) parse(src string) (*rl.SourceFile, bool) {
-	if s.tree == nil {
-		s.tree = s.parser.Parse(src)
-	} else {
-		s.tree.Update(s.parser, src)
-	}
+	s.reparse(src)
 
 	if s.tree.HasInvalidNodes() {
 		s.reportSyntaxErrors(src)
 		return nil, false
 	}
-	return rts.ConvertCST(s.tree.Root(), src, replScriptName), true
+	root := rts.ConvertCST(s.tree.Root(), src, replScriptName)
+	s.reportWarnings(src, root)
+	return root, true
+}
+
+func (s *ReplSession) reparse(src string) {
+	if s.tree == nil {
+		s.tree = s.parser.Parse(src)
+	} else {
+		s.tree.Update(s.parser, src)
+	}
 }
 
 // reportSyntaxErrors renders the same diagnostics a script would get for the
@@ -152,6 +202,26 @@ func (s *ReplSession) reportSyntaxErrors(src string) {
 	}
 }
 
+// reportWarnings renders what the checker warns about in a turn that is about
+// to run, as `rad check` would for a script. The same scope problem as above
+// rules out its errors, but its warnings are about the turn's own text - a
+// constant interpolated into a string, a misleading capture name - and hold
+// whatever earlier turns defined. A warning never stops the turn.
+func (s *ReplSession) reportWarnings(src string, root *rl.SourceFile) {
+	checker := check.NewCheckerWithTree(s.tree, s.parser, src, root)
+	result, err := checker.Check()
+	if err != nil {
+		return
+	}
+
+	renderer := NewDiagnosticRenderer(RIo.StdErr)
+	for _, diag := range result.Diagnostics {
+		if diag.Severity == check.Warning {
+			renderer.Render(NewDiagnosticFromCheck(diag, replScriptName))
+		}
+	}
+}
+
 // handleAbort decides what an early-ended turn means for the session. An error
 // has already printed itself, so the prompt simply comes back; an interrupt
 // says so, because a statement vanishing silently reads like a crash.
@@ -24,7 +24,20 @@ type Requester struct {
 	insecure                  bool
 	insecureClient            *http.Client // cached; created lazily to reuse http.Transport connection pool
 	jsonPathsByMockedUrlRegex map[string]string
-	captureRequest            func(HttpRequest)
+	responseMocks             []responseMock
+	// mockedOnly refuses requests no mock answers, rather than sending them.
+	mockedOnly     bool
+	captureRequest func(HttpRequest)
+	// last is the most recent exchange, kept for the REPL's :http.
+	last *HttpRequest
+}
+
+// responseMock answers requests whose URL matches urlRegex, and whose method
+// is method, if one is given.
+type responseMock struct {
+	urlRegex *regexp.Regexp
+	method   string
+	response ResponseDef
 }
 
 type RequestDef struct {
@@ -149,12 +162,33 @@ func (r *Requester) AddMockedResponse(urlRegex string, jsonPath string) {
 
 func (r *Requester) ClearMockedResponses() {
 	r.jsonPathsByMockedUrlRegex = make(map[string]string)
+	r.responseMocks = nil
+}
+
+// AddResponseMock answers matching requests with response. Later mocks take
+// precedence over earlier ones, so a general mock can be overridden.
+func (r *Requester) AddResponseMock(urlRegex *regexp.Regexp, method string, response ResponseDef) {
+	r.responseMocks = append(r.responseMocks, responseMock{urlRegex: urlRegex, method: method, response: response})
+}
+
+// SetMockedOnly makes requests that no mock answers fail instead of being sent.
+func (r *Requester) SetMockedOnly(mockedOnly bool) {
+	r.mockedOnly = mockedOnly
 }
 
 func (r *Requester) SetCaptureCallback(cb func(HttpRequest)) {
 	r.captureRequest = cb
 }
 
+// LastExchange returns the most recent request and its response, if any
+// request has been made.
+func (r *Requester) LastExchange() (HttpRequest, bool) {
+	if r.last == nil {
+		return HttpRequest{}, false
+	}
+	return *r.last, true
+}
+
 // Request executes an HTTP request. ctx is the signal-cancellation context;
 // if it fires (e.g. SIGINT), the in-flight request is aborted. Tests can pass
 // context.Background() if signal handling is not relevant.
@@ -162,16 +196,14 @@ func (r *Requester) Request(ctx context.Context, def RequestDef) ResponseDef {
 
 	sanitizedURL, err := sanitizeUrlString(def.Url)
 	if err != nil {
-		msg := fmt.Sprintf("Failed to create HTTP request: %v", err)
-		return NewResponseDef(nil, nil, nil, &msg, 0)
+		return r.failUnsent(def, err)
 	}
 
 	// Create request with sanitized URL. Using ctx so a signal interrupts the
 	// blocking I/O of client.Do() below.
 	req, err := http.NewRequestWithContext(ctx, def.Method, sanitizedURL, def.BodyReader())
 	if err != nil {
-		msg := fmt.Sprintf("Failed to create HTTP request: %v", err)
-		return NewResponseDef(nil, nil, nil, &msg, 0)
+		return r.failUnsent(def, err)
 	}
 
 	// Apply headers after successful request creation
@@ -183,24 +215,44 @@ func (r *Requester) Request(ctx context.Context, def RequestDef) ResponseDef {
 
 	response := r.request(req, def.Insecure, def.Quiet)
 
-	if r.captureRequest != nil {
-		// Capture what was actually sent (with sanitized URL)
-		actualDef := RequestDef{
+	// Record what was actually sent (with sanitized URL)
+	exchange := HttpRequest{
+		RequestDef: RequestDef{
 			Method:   def.Method,
 			Url:      req.URL.String(), // Use the actual sanitized URL sent
 			Headers:  def.Headers,
 			Body:     def.Body,
 			Insecure: def.Insecure,
-		}
-		r.captureRequest(HttpRequest{
-			RequestDef:  actualDef,
-			ResponseDef: response,
-		})
+		},
+		ResponseDef: response,
+	}
+	r.last = &exchange
+	if r.captureRequest != nil {
+		r.captureRequest(exchange)
 	}
 
 	return response
 }
 
+// failUnsent fails a request that couldn't be built. It still becomes the
+// last exchange, so :http shows why it failed rather than whatever was sent
+// before it.
+func (r *Requester) failUnsent(def RequestDef, err error) ResponseDef {
+	msg := fmt.Sprintf("Failed to create HTTP request: %v", err)
+	response := NewResponseDef(nil, nil, nil, &msg, 0)
+	r.last = &HttpRequest{
+		RequestDef: RequestDef{
+			Method:   def.Method,
+			Url:      def.Url,
+			Headers:  def.Headers,
+			Body:     def.Body,
+			Insecure: def.Insecure,
+		},
+		ResponseDef: response,
+	}
+	return response
+}
+
 func (r *Requester) RequestJson(ctx context.Context, url string, insecure bool, quiet bool) (interface{}, error) {
 	reqDef := NewRequestDef("GET", url, emptyHeaders, nil)
 	reqDef.Insecure = insecure
@@ -236,11 +288,23 @@ func (r *Requester) RequestJson(ctx context.Context, url string, insecure bool,
 }
 
 func (r *Requester) request(req *http.Request, insecureOverride bool, quiet bool) ResponseDef {
+	for idx := len(r.responseMocks) - 1; idx >= 0; idx-- {
+		mock := r.responseMocks[idx]
+		if (mock.method == "" || strings.EqualFold(mock.method, req.Method)) && mock.urlRegex.MatchString(req.URL.String()) {
+			return mock.response
+		}
+	}
+
 	mockJson, ok := r.resolveMockedResponse(req.URL.String())
 	if ok {
 		return NewResponseDef(&statusOk, &emptyHeaders, &mockJson, nil, 0)
 	}
 
+	if r.mockedOnly {
+		msg := fmt.Sprintf("No mock for %s %s", req.Method, req.URL.String())
+		return NewResponseDef(nil, nil, nil, &msg, 0)
+	}
+
 	if !quiet {
 		RP.RadStderrf("Querying url: %s\n", req.URL.String())
 	}
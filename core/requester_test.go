@@ -0,0 +1,23 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequester_LastExchangeIncludesUnsentRequests(t *testing.T) {
+	r := NewRequester()
+	r.SetMockedOnly(true)
+
+	r.Request(context.Background(), NewRequestDef("GET", "http://example.com/ok", emptyHeaders, nil))
+	r.Request(context.Background(), NewRequestDef("GET", "http://[::1", emptyHeaders, nil))
+
+	exchange, ok := r.LastExchange()
+	require.True(t, ok)
+	assert.Equal(t, "http://[::1", exchange.RequestDef.Url)
+	require.NotNil(t, exchange.ResponseDef.Error)
+	assert.Contains(t, *exchange.ResponseDef.Error, "Failed to create HTTP request")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/amterp/color"
@@ -22,6 +23,7 @@ const (
 	ScriptFile                            // existing file
 	StdinScript                           // "rad -"
 	EmbeddedCommand                       // built-in commands
+	BundleDir                             // directory of scripts with a bundle manifest
 )
 
 type RadRunner struct {
@@ -67,6 +69,11 @@ func (r *RadRunner) Run() error {
 		return r.handleReplCommand(os.Args[2:])
 	}
 
+	// And `rad debug`, which hosts a script rather than being one.
+	if len(os.Args) > 1 && os.Args[1] == "debug" && !com.IsRegularFile("debug") {
+		return r.handleDebugCommand(os.Args[2:])
+	}
+
 	// Phase 1: Detection & Setup
 	invocationType, err := r.detectAndSetup(os.Args[1:])
 	if err != nil {
@@ -134,6 +141,11 @@ func (r *RadRunner) detectInvocationType(args []string) (InvocationType, string,
 		return ScriptFile, source, nil
 	}
 
+	// A bundle is read file by file once the path is known; see detectAndSetup.
+	if isBundleDir(firstArg) {
+		return BundleDir, "", nil
+	}
+
 	// Check if it's an embedded command
 	cmdSource := GetEmbeddedCommandSrc(firstArg)
 	if cmdSource != nil {
@@ -169,7 +181,7 @@ func (r *RadRunner) detectAndSetup(args []string) (InvocationType, error) {
 	}
 
 	scriptPath := ""
-	if invocationType == ScriptFile && len(args) > 0 {
+	if (invocationType == ScriptFile || invocationType == BundleDir) && len(args) > 0 {
 		scriptPath = args[0]
 	} else if invocationType == EmbeddedCommand && len(args) > 0 {
 		// For embedded commands, use the command name as the script name
@@ -191,7 +203,18 @@ func (r *RadRunner) detectAndSetup(args []string) (InvocationType, error) {
 	r.invocationType = invocationType
 	SetScriptPath(scriptPath)
 
-	if HasScript {
+	if invocationType == BundleDir {
+		// The bundle's files sit in the directory itself, not beside it, and
+		// the tool goes by its manifest name rather than the directory's.
+		ScriptDir = filepath.Clean(scriptPath)
+		r.scriptData, err = LoadBundle(scriptPath)
+		if err != nil {
+			return NoScript, err
+		}
+		if !IsTest {
+			ScriptName = r.scriptData.ScriptName
+		}
+	} else if HasScript {
 		r.scriptData = ExtractMetadata(sourceCode)
 	}
 
@@ -229,7 +252,8 @@ func (r *RadRunner) setupRootCommand() {
 	})
 
 	if r.scriptData == nil || !r.scriptData.DisableGlobalOpts {
-		r.globalFlags = CreateAndRegisterGlobalFlags(r.invocationType)
+		outputFlag := r.scriptData != nil && r.scriptData.EnableOutputFlag
+		r.globalFlags = CreateAndRegisterGlobalFlags(r.invocationType, outputFlag)
 	}
 
 	if r.scriptData != nil && r.scriptData.Description != nil {
@@ -308,7 +332,7 @@ func (r *RadRunner) parseAndExecute(invocationType InvocationType) error {
 	// See commit message for detailed rationale.
 
 	var argsToRead []string
-	if invocationType == ScriptFile || invocationType == EmbeddedCommand {
+	if invocationType == ScriptFile || invocationType == EmbeddedCommand || invocationType == BundleDir {
 		if len(os.Args) > 2 {
 			argsToRead = os.Args[2:]
 		} else {
@@ -343,8 +367,9 @@ func (r *RadRunner) parseAndExecute(invocationType InvocationType) error {
 		r.rejectOutOfScopeGlobalFlags()
 	}
 
-	// Set up printer with global flags from first parse
-	RP = NewPrinter(r, FlagShell.Value, FlagQuiet.Value, FlagDebug.Value, FlagRadDebug.Value)
+	// Set up printer with global flags from first parse. A machine --output
+	// reserves stdout for emit() just as --shell reserves it for exports.
+	RP = NewPrinter(r, FlagShell.Value || isMachineOutput(), FlagQuiet.Value, FlagDebug.Value, FlagRadDebug.Value)
 
 	// Handle mock responses
 	mockResponse := FlagMockResponse.Value
@@ -378,21 +403,28 @@ func (r *RadRunner) parseAndExecute(invocationType InvocationType) error {
 		RExit.Exit(0)
 	}
 
-	// Handle inspection flags for script output
+	// Handle inspection flags for script output. In a bundle, that's the
+	// source of whichever command file was named.
+	inspected := r.scriptData
+	if len(r.cmdInvocations) > 0 {
+		named, _ := r.resolveInvokedCommand()
+		inspected, _ = r.scriptFor(named)
+	}
+
 	shouldExit := false
 	if FlagSrc.Value {
 		shouldExit = true
-		printSource(r.scriptData.Src, FlagVersion.Value)
+		printSource(inspected.Src, FlagVersion.Value)
 	}
 
 	if FlagCstTree.Value {
 		shouldExit = true
-		printCstTree(r.scriptData.Tree, FlagSrc.Value)
+		printCstTree(inspected.Tree, FlagSrc.Value)
 	}
 
 	if FlagAstTree.Value {
 		shouldExit = true
-		printAstTree(r.scriptData.Tree, r.scriptData.Src, r.scriptData.ScriptName, FlagSrc.Value || FlagCstTree.Value)
+		printAstTree(inspected.Tree, inspected.Src, inspected.ScriptName, FlagSrc.Value || FlagCstTree.Value)
 	}
 
 	if shouldExit {
@@ -449,22 +481,32 @@ func (r *RadRunner) parseAndExecute(invocationType InvocationType) error {
 		return fmt.Errorf("Bug! Script expected by this point, but found none")
 	}
 
+	script, dispatched := r.scriptFor(invokedCommand)
+
 	// Last stop before anything runs: replies are parsed, the invoked command is
 	// known, and no statement has executed yet.
-	r.runPromptPreflight(invokedCommand)
+	r.runPromptPreflight(script, dispatched)
+
+	if len(FlagBreak.Value) > 0 {
+		applyBreakFlag(r.scriptData)
+	} else if RDebugger != nil {
+		RDebugger.AddFile(r.scriptData.ScriptName, ScriptPath)
+	}
 
 	interpreter := NewInterpreter(InterpreterInput{
-		Src:            r.scriptData.Src,
-		Tree:           r.scriptData.Tree,
-		ScriptName:     r.scriptData.ScriptName,
-		InvokedCommand: invokedCommand,
+		Src:            script.Src,
+		Tree:           script.Tree,
+		ScriptName:     script.ScriptName,
+		InvokedCommand: dispatched,
 	})
+	interpreter.debugger = RDebugger
 	interpreter.InitBuiltIns()
 	interpreter.InitArgs(r.scriptArgs)
 	// Initialize command-specific args if a command was invoked
 	if invokedCommand != nil && len(commandArgs) > 0 {
 		interpreter.InitArgs(commandArgs)
 	}
+	r.runArgValidators(script, interpreter, commandArgs)
 	interpreter.RegisterWithExit()
 	interpreter.Run()
 
@@ -512,6 +554,42 @@ func (r *RadRunner) registerCommands() error {
 	return setDefaultCmd(RRootCmd, r.scriptData.Commands)
 }
 
+// scriptFor returns the script to run for an invoked command (nil for none)
+// and the command to dispatch within it. Outside a bundle that's always this
+// script and the command as given.
+func (r *RadRunner) scriptFor(cmd *ScriptCommand) (*ScriptData, *ScriptCommand) {
+	script, dispatched := cmd.scriptFor()
+	if script == nil {
+		return r.scriptData, cmd
+	}
+	return script, dispatched
+}
+
+// runArgValidators checks the parsed args with the script's validator
+// functions before any of it runs, rejecting the invocation the way a failed
+// constraint does. Each arg's validator runs before the args block's, so a
+// validator over all of them can trust each one is individually sound.
+func (r *RadRunner) runArgValidators(script *ScriptData, interpreter *Interpreter, commandArgs []RadArg) {
+	validators := newArgValidators()
+	validators.seed(script, interpreter)
+
+	// Script-level args belong to the root script, which in a bundle is the
+	// shared one rather than the command file running.
+	checks := []func() string{
+		func() string { return validators.checkArgs(r.scriptData, r.scriptArgs) },
+		func() string { return validators.checkArgs(script, commandArgs) },
+		func() string { return validators.checkAll(r.scriptData, r.scriptArgs) },
+	}
+	if script != r.scriptData {
+		checks = append(checks, func() string { return validators.checkAll(script, commandArgs) })
+	}
+	for _, check := range checks {
+		if msg := check(); msg != "" {
+			RP.UsageErrorExit(msg)
+		}
+	}
+}
+
 // raCmdFor returns the Ra command mirroring a script command, or nil if it was
 // never registered.
 func (r *RadRunner) raCmdFor(cmd *ScriptCommand) *ra.Cmd {
@@ -747,7 +825,7 @@ func (r *RadRunner) handleReplCommand(args []string) error {
 	// deterministic when something is capturing it. Registering as an embedded
 	// command is what keeps the script-only ones out of `rad repl --help`.
 	RRootCmd = cmd
-	CreateAndRegisterGlobalFlags(EmbeddedCommand)
+	CreateAndRegisterGlobalFlags(EmbeddedCommand, false)
 	cmd.ParseOrExit(args) // handles -h/--help, rejects unknown args
 
 	switch FlagColor.Value {
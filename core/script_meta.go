@@ -26,6 +26,11 @@ type ScriptData struct {
 	DisableGlobalOpts bool
 	DisableArgsBlock  bool
 	HasArgsBlock      bool
+	// EnableOutputFlag opts the script into the global --output flag.
+	EnableOutputFlag bool
+	// Validator names the function set by the @validate macro: it's called
+	// once all args are parsed, to check them against each other.
+	Validator *string
 }
 
 func ExtractMetadata(src string) *ScriptData {
@@ -40,17 +45,34 @@ func ExtractMetadata(src string) *ScriptData {
 	// Validate syntax and get the AST plus its name resolution
 	ast, resolved := validateSyntax(src, tree, radTree)
 
-	disableGlobalOpts := false
-	disableArgsBlock := false
-	var description *string
 	if ast != nil && ast.Header != nil {
-		description = &ast.Header.Contents
 		if stashId, ok := ast.Header.MetadataEntries[MACRO_STASH_ID]; ok {
 			RadHomeInst.SetStashId(stashId)
 		}
+	}
 
+	return newScriptData(ScriptName, src, tree, ast, resolved)
+}
+
+// newScriptData gathers a validated script's metadata from its AST. It has no
+// side effects of its own, so a bundle can build one per command file without
+// each of them claiming the run's stash.
+func newScriptData(
+	name string,
+	src string,
+	tree *rts.RadTree,
+	ast *rl.SourceFile,
+	resolved *check.Resolved,
+) *ScriptData {
+	disableGlobalOpts := false
+	disableArgsBlock := false
+	enableOutputFlag := false
+	var description *string
+	if ast != nil && ast.Header != nil {
+		description = &ast.Header.Contents
 		disableGlobalOpts = !defaultTruthyMacroToggle(ast.Header.MetadataEntries, MACRO_ENABLE_GLOBAL_OPTIONS)
 		disableArgsBlock = !defaultTruthyMacroToggle(ast.Header.MetadataEntries, MACRO_ENABLE_ARGS_BLOCK)
+		enableOutputFlag = optInMacroToggle(ast.Header.MetadataEntries, MACRO_ENABLE_OUTPUT_FLAG)
 	}
 
 	var args []*ScriptArg
@@ -65,8 +87,13 @@ func ExtractMetadata(src string) *ScriptData {
 		commands = extractCommandsFromAST(ast.Cmds, src)
 	}
 
+	var validator *string
+	if ast != nil && ast.Header != nil {
+		validator = applyValidatorMacros(ast.Header.MetadataEntries, args, commands)
+	}
+
 	return &ScriptData{
-		ScriptName:        ScriptName,
+		ScriptName:        name,
 		Args:              args,
 		Commands:          commands,
 		Description:       description,
@@ -77,6 +104,8 @@ func ExtractMetadata(src string) *ScriptData {
 		DisableGlobalOpts: disableGlobalOpts,
 		DisableArgsBlock:  disableArgsBlock,
 		HasArgsBlock:      ast != nil && ast.Args != nil,
+		EnableOutputFlag:  enableOutputFlag,
+		Validator:         validator,
 	}
 }
 
@@ -112,8 +141,23 @@ func validateSyntax(
 	tree *rts.RadTree,
 	parser *rts.RadParser,
 ) (*rl.SourceFile, *check.Resolved) {
-	ast := tryConvertAST(tree, src, ScriptName)
-	checker := check.NewCheckerWithTree(tree, parser, src, ast)
+	return validateSyntaxOf(ScriptName, src, tree, parser, nil)
+}
+
+// validateSyntaxOf is validateSyntax for a named file that may use args it
+// doesn't declare: a bundle's command files see the shared args as though
+// declared in their own args block, so they're checked that way too. The
+// returned AST is the file's own - the shared declarations are only lent to
+// the checker - so its args block still describes just this file.
+func validateSyntaxOf(
+	file string,
+	src string,
+	tree *rts.RadTree,
+	parser *rts.RadParser,
+	sharedArgs []rl.ArgDecl,
+) (*rl.SourceFile, *check.Resolved) {
+	ast := tryConvertAST(tree, src, file)
+	checker := check.NewCheckerWithTree(tree, parser, src, withSharedArgs(ast, sharedArgs))
 	result, err := checker.Check()
 	if err != nil {
 		RP.RadErrorExit("Failed to validate syntax: " + err.Error())
@@ -125,7 +169,7 @@ func validateSyntax(
 	for _, diag := range result.Diagnostics {
 		if diag.Severity == check.Error {
 			// Convert to core.Diagnostic using the new format
-			coreDiag := NewDiagnosticFromCheck(diag, ScriptName)
+			coreDiag := NewDiagnosticFromCheck(diag, file)
 			errors = append(errors, coreDiag)
 		}
 	}
@@ -145,6 +189,35 @@ func validateSyntax(
 	return ast, result.Resolved
 }
 
+// withSharedArgs returns a copy of ast whose args block also declares shared,
+// minus any the file redeclares itself - the closer declaration wins, as it
+// does between nested commands.
+func withSharedArgs(ast *rl.SourceFile, shared []rl.ArgDecl) *rl.SourceFile {
+	if ast == nil || len(shared) == 0 {
+		return ast
+	}
+
+	merged := *ast
+	var block rl.ArgBlock
+	if ast.Args != nil {
+		block = *ast.Args
+	}
+
+	own := make(map[string]bool, len(block.Decls))
+	for _, decl := range block.Decls {
+		own[decl.Name] = true
+	}
+	decls := make([]rl.ArgDecl, 0, len(shared)+len(block.Decls))
+	for _, decl := range shared {
+		if !own[decl.Name] {
+			decls = append(decls, decl)
+		}
+	}
+	block.Decls = append(decls, block.Decls...)
+	merged.Args = &block
+	return &merged
+}
+
 func extractArgsFromAST(argBlock *rl.ArgBlock, src string) []*ScriptArg {
 	if argBlock == nil {
 		return nil
@@ -193,7 +266,20 @@ func defaultTruthyMacroToggle(macroMap map[string]string, macro string) bool {
 	if !ok {
 		return true
 	}
+	return macroValueTruthy(val)
+}
+
+// optInMacroToggle is defaultTruthyMacroToggle for a feature that's off unless
+// the script asks for it.
+func optInMacroToggle(macroMap map[string]string, macro string) bool {
+	val, ok := macroMap[macro]
+	if !ok {
+		return false
+	}
+	return macroValueTruthy(val)
+}
 
+func macroValueTruthy(val string) bool {
 	var radVal RadValue
 	if i64, err := strconv.ParseInt(val, 10, 64); err == nil {
 		radVal = newRadValueInt64(i64)
@@ -0,0 +1,481 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amterp/color"
+	"github.com/amterp/ra"
+	com "github.com/amterp/rad/core/common"
+	"github.com/amterp/rad/rts"
+	"github.com/samber/lo"
+)
+
+// Reference page formats accepted by `rad gen-ref`.
+const (
+	RefFormatMan = "man"
+	RefFormatMd  = "md"
+)
+
+// scriptRef is one script's reference page, independent of output format. It
+// is built from the same ScriptData the runner registers with Ra, so a page
+// can only say what --help would: the args block is the single source, and
+// the header is the only prose.
+type scriptRef struct {
+	name     string
+	summary  string   // first line of the file header
+	body     []string // remaining header paragraphs, examples lifted out
+	synopsis []string
+	args     []refArg
+	commands []refCommand
+	examples []refExample
+}
+
+type refCommand struct {
+	path        string // full invocation, e.g. "tool remote add"
+	description string
+	synopsis    []string
+	args        []refArg
+	isDefault   bool
+}
+
+type refArg struct {
+	flag        string // "--name" or "-n, --name"
+	typeName    string
+	description string
+	details     []string // default and constraints, one per line
+}
+
+type refExample struct {
+	cmd         string
+	description string
+}
+
+// loadScriptDataForRef reads and parses a script for documentation without the
+// side effects ExtractMetadata has on the running script (stash ID, script
+// name, exiting on syntax errors): the script being documented is not the
+// script being run.
+func loadScriptDataForRef(path string) (*ScriptData, error) {
+	result := com.LoadFile(path)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	src := NormalizeLineEndings(result.Content)
+
+	parser, err := rts.NewRadParser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parser: %w", err)
+	}
+	defer parser.Close()
+
+	tree := parser.Parse(src)
+	if tree.HasInvalidNodes() {
+		return nil, fmt.Errorf("script has syntax errors; run 'rad check %s' for details", path)
+	}
+	ast := tryConvertAST(tree, src, path)
+	if ast == nil {
+		return nil, fmt.Errorf("script could not be read; run 'rad check %s' for details", path)
+	}
+
+	data := &ScriptData{
+		ScriptName:   scriptNameFromPath(path),
+		Tree:         tree,
+		Ast:          ast,
+		Src:          src,
+		HasArgsBlock: ast.Args != nil,
+	}
+	if ast.Header != nil {
+		data.Description = &ast.Header.Contents
+	}
+	if ast.Args != nil {
+		data.Args = extractArgsFromAST(ast.Args, src)
+	}
+	if len(ast.Cmds) > 0 {
+		data.Commands = extractCommandsFromAST(ast.Cmds, src)
+	}
+	return data, nil
+}
+
+// RenderScriptRef renders a script's reference page as a roff man page or a
+// Markdown document.
+func RenderScriptRef(data *ScriptData, name string, format string) string {
+	ref := buildScriptRef(data, name)
+	if format == RefFormatMd {
+		return renderRefMarkdown(ref)
+	}
+	return renderRefMan(ref)
+}
+
+func buildScriptRef(data *ScriptData, name string) scriptRef {
+	ref := scriptRef{name: name}
+
+	if data.Description != nil {
+		ref.summary, ref.body, ref.examples = splitHeaderForRef(*data.Description)
+	}
+
+	// Synopses come from Ra, the thing that parses the invocation, rather than
+	// being re-derived here - the placeholders and their order have rules
+	// (bools never appear, a variadic ends the sequence) that a copy would
+	// drift from.
+	root := ra.NewCmd(name)
+	raCmds := make(map[*ScriptCommand]*ra.Cmd)
+	if len(data.Commands) == 0 {
+		for _, arg := range data.Args {
+			CreateFlag(arg).Register(root, AsScriptArg)
+		}
+	} else {
+		scriptArgs := make([]RadArg, 0, len(data.Args))
+		for _, arg := range data.Args {
+			scriptArgs = append(scriptArgs, CreateFlag(arg))
+		}
+		for _, scriptCmd := range data.Commands {
+			_ = buildRaCmdTree(root, scriptCmd, scriptArgs,
+				func(cmd *ScriptCommand, raCmd *ra.Cmd, _ *bool, _ []RadArg) {
+					raCmds[cmd] = raCmd
+				})
+		}
+		_ = setDefaultCmd(root, data.Commands)
+	}
+
+	ref.synopsis = []string{plainSynopsis(root, name, name)}
+	for _, arg := range data.Args {
+		ref.args = append(ref.args, buildRefArg(arg))
+	}
+
+	var walk func(cmds []*ScriptCommand)
+	walk = func(cmds []*ScriptCommand) {
+		for _, cmd := range cmds {
+			path := name + " " + strings.Join(cmd.pathNames(), " ")
+			refCmd := refCommand{path: path, isDefault: cmd.IsDefault}
+			if cmd.Description != nil {
+				refCmd.description = strings.TrimSpace(*cmd.Description)
+			}
+			if raCmd, ok := raCmds[cmd]; ok {
+				refCmd.synopsis = []string{plainSynopsis(raCmd, cmd.ExternalName, path)}
+			}
+			for _, arg := range cmd.Args {
+				refCmd.args = append(refCmd.args, buildRefArg(arg))
+			}
+			ref.commands = append(ref.commands, refCmd)
+			walk(cmd.SubCmds)
+		}
+	}
+	walk(data.Commands)
+
+	return ref
+}
+
+// plainSynopsis renders a Ra command's synopsis without color, under the full
+// invocation path rather than the command's own name.
+func plainSynopsis(cmd *ra.Cmd, cmdName string, path string) string {
+	prevNoColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = prevNoColor }()
+
+	synopsis := cmd.GenerateSynopsis(true)
+	return path + strings.TrimPrefix(synopsis, cmdName)
+}
+
+func buildRefArg(arg *ScriptArg) refArg {
+	flag := "--" + arg.ExternalName
+	if arg.Short != nil {
+		flag = "-" + *arg.Short + ", " + flag
+	}
+
+	typeName := arg.Type.TypeName()
+	if arg.IsVariadic {
+		typeName = "*" + typeName
+	}
+	if arg.IsNullable {
+		typeName += "?"
+	}
+
+	ref := refArg{flag: flag, typeName: typeName}
+	if arg.Description != nil {
+		ref.description = strings.TrimSpace(*arg.Description)
+	}
+
+	if arg.DefaultString != nil {
+		// Quoted, or an empty default would read as no default at all.
+		ref.details = append(ref.details, fmt.Sprintf("Default: %q", *arg.DefaultString))
+	} else if d := argDefaultDisplay(arg); d != "" {
+		ref.details = append(ref.details, fmt.Sprintf("Default: %s", d))
+	}
+	if arg.EnumConstraint != nil {
+		ref.details = append(ref.details, fmt.Sprintf("Valid values: %s", strings.Join(*arg.EnumConstraint, ", ")))
+	}
+	if arg.RegexConstraint != nil {
+		ref.details = append(ref.details, fmt.Sprintf("Must match: %s", arg.RegexConstraint.String()))
+	}
+	if arg.RangeConstraint != nil {
+		ref.details = append(ref.details, fmt.Sprintf("Range: %s", rangeText(arg.RangeConstraint)))
+	}
+	if arg.LenConstraint != nil {
+		ref.details = append(ref.details, fmt.Sprintf("Number of values: %s", lenText(arg.LenConstraint)))
+	}
+	if len(arg.RequiresConstraint) > 0 {
+		ref.details = append(ref.details, fmt.Sprintf("Requires: %s", flagList(arg.RequiresConstraint)))
+	}
+	if len(arg.ExcludesConstraint) > 0 {
+		ref.details = append(ref.details, fmt.Sprintf("Excludes: %s", flagList(arg.ExcludesConstraint)))
+	}
+	return ref
+}
+
+func lenText(lc *ArgLenConstraint) string {
+	var sb strings.Builder
+	sb.WriteString(lo.Ternary(lc.MinInclusive, "[", "("))
+	if lc.Min != nil {
+		sb.WriteString(fmt.Sprintf("%d", *lc.Min))
+	}
+	sb.WriteString(", ")
+	if lc.Max != nil {
+		sb.WriteString(fmt.Sprintf("%d", *lc.Max))
+	}
+	sb.WriteString(lo.Ternary(lc.MaxInclusive, "]", ")"))
+	return sb.String()
+}
+
+func flagList(externalNames []string) string {
+	flags := make([]string, len(externalNames))
+	for i, name := range externalNames {
+		flags[i] = "--" + name
+	}
+	return strings.Join(flags, ", ")
+}
+
+// splitHeaderForRef splits a file header into its summary line, its remaining
+// paragraphs, and its examples. Examples follow the convention the built-in
+// commands' headers use: a "> command" line, then the lines describing it.
+// An "Examples:" line introducing them is dropped, since the page gives
+// examples their own section.
+func splitHeaderForRef(header string) (string, []string, []refExample) {
+	lines := strings.Split(strings.TrimSpace(header), "\n")
+	summary := strings.TrimSpace(lines[0])
+
+	var paragraphs []string
+	var examples []refExample
+	var para []string
+	var example *refExample
+
+	flushPara := func() {
+		if len(para) > 0 {
+			paragraphs = append(paragraphs, strings.Join(para, "\n"))
+			para = nil
+		}
+	}
+	flushExample := func() {
+		if example != nil {
+			examples = append(examples, *example)
+			example = nil
+		}
+	}
+
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, ">"):
+			flushPara()
+			flushExample()
+			example = &refExample{cmd: strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))}
+		case trimmed == "":
+			flushPara()
+			flushExample()
+		case example != nil:
+			if example.description != "" {
+				example.description += " "
+			}
+			example.description += trimmed
+		default:
+			para = append(para, line)
+		}
+	}
+	flushPara()
+	flushExample()
+
+	if len(examples) > 0 {
+		paragraphs = lo.Reject(paragraphs, func(p string, _ int) bool {
+			return strings.EqualFold(strings.TrimSpace(p), "Examples:")
+		})
+	}
+
+	return summary, paragraphs, examples
+}
+
+func renderRefMarkdown(ref scriptRef) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n", ref.name))
+	if ref.summary != "" {
+		sb.WriteString("\n" + ref.summary + "\n")
+	}
+	for _, p := range ref.body {
+		sb.WriteString("\n" + p + "\n")
+	}
+
+	sb.WriteString("\n## Usage\n\n```\n")
+	sb.WriteString(strings.Join(ref.synopsis, "\n") + "\n")
+	sb.WriteString("```\n")
+
+	if len(ref.args) > 0 {
+		sb.WriteString("\n## Arguments\n\n")
+		writeMdArgs(&sb, ref.args)
+	}
+
+	if len(ref.commands) > 0 {
+		sb.WriteString("\n## Commands\n")
+		for _, cmd := range ref.commands {
+			sb.WriteString(fmt.Sprintf("\n### `%s`\n", cmd.path))
+			if cmd.isDefault {
+				sb.WriteString("\n*Default command.*\n")
+			}
+			if cmd.description != "" {
+				sb.WriteString("\n" + cmd.description + "\n")
+			}
+			if len(cmd.synopsis) > 0 {
+				sb.WriteString("\n```\n" + strings.Join(cmd.synopsis, "\n") + "\n```\n")
+			}
+			if len(cmd.args) > 0 {
+				sb.WriteString("\n")
+				writeMdArgs(&sb, cmd.args)
+			}
+		}
+	}
+
+	if len(ref.examples) > 0 {
+		sb.WriteString("\n## Examples\n")
+		for _, ex := range ref.examples {
+			sb.WriteString("\n```shell\n" + ex.cmd + "\n```\n")
+			if ex.description != "" {
+				sb.WriteString("\n" + ex.description + "\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func writeMdArgs(sb *strings.Builder, args []refArg) {
+	for _, arg := range args {
+		sb.WriteString(fmt.Sprintf("- `%s` (`%s`)", arg.flag, arg.typeName))
+		if arg.description != "" {
+			sb.WriteString(": " + arg.description)
+		}
+		sb.WriteString("\n")
+		for _, detail := range arg.details {
+			sb.WriteString("    - " + detail + "\n")
+		}
+	}
+}
+
+func renderRefMan(ref scriptRef) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf(".TH \"%s\" \"1\" \"\" \"\" \"User Commands\"\n", manEscape(strings.ToUpper(ref.name))))
+
+	sb.WriteString(".SH NAME\n")
+	if ref.summary != "" {
+		sb.WriteString(manEscape(ref.name) + " \\- " + manEscape(ref.summary) + "\n")
+	} else {
+		sb.WriteString(manEscape(ref.name) + "\n")
+	}
+
+	sb.WriteString(".SH SYNOPSIS\n")
+	writeManSynopsis(&sb, ref.synopsis)
+
+	if len(ref.body) > 0 {
+		sb.WriteString(".SH DESCRIPTION\n")
+		for i, p := range ref.body {
+			if i > 0 {
+				sb.WriteString(".PP\n")
+			}
+			sb.WriteString(manText(p) + "\n")
+		}
+	}
+
+	if len(ref.args) > 0 {
+		sb.WriteString(".SH OPTIONS\n")
+		writeManArgs(&sb, ref.args)
+	}
+
+	if len(ref.commands) > 0 {
+		sb.WriteString(".SH COMMANDS\n")
+		for _, cmd := range ref.commands {
+			sb.WriteString(fmt.Sprintf(".SS \"%s\"\n", manEscape(cmd.path)))
+			if cmd.isDefault {
+				sb.WriteString("Default command.\n")
+			}
+			if cmd.description != "" {
+				if cmd.isDefault {
+					sb.WriteString(".PP\n")
+				}
+				sb.WriteString(manText(cmd.description) + "\n")
+			}
+			if len(cmd.synopsis) > 0 {
+				writeManSynopsis(&sb, cmd.synopsis)
+			}
+			writeManArgs(&sb, cmd.args)
+		}
+	}
+
+	if len(ref.examples) > 0 {
+		sb.WriteString(".SH EXAMPLES\n")
+		for i, ex := range ref.examples {
+			if i > 0 {
+				sb.WriteString(".PP\n")
+			}
+			sb.WriteString(".nf\n")
+			sb.WriteString(manText(ex.cmd) + "\n")
+			sb.WriteString(".fi\n")
+			if ex.description != "" {
+				sb.WriteString(".RS\n" + manText(ex.description) + "\n.RE\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func writeManSynopsis(sb *strings.Builder, synopsis []string) {
+	sb.WriteString(".nf\n")
+	for _, line := range synopsis {
+		sb.WriteString(manText(line) + "\n")
+	}
+	sb.WriteString(".fi\n")
+}
+
+func writeManArgs(sb *strings.Builder, args []refArg) {
+	for _, arg := range args {
+		sb.WriteString(".TP\n")
+		sb.WriteString(fmt.Sprintf("\\fB%s\\fR \\fI%s\\fR\n", manEscape(arg.flag), manEscape(arg.typeName)))
+		if arg.description != "" {
+			sb.WriteString(manText(arg.description) + "\n")
+		}
+		for _, detail := range arg.details {
+			sb.WriteString(".br\n")
+			sb.WriteString(manText(detail) + "\n")
+		}
+	}
+}
+
+// manEscape escapes text for use inside a roff line. Hyphens are escaped so
+// flags survive copy-paste from the rendered page as ASCII hyphen-minus.
+func manEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	s = strings.ReplaceAll(s, "-", "\\-")
+	return s
+}
+
+// manText escapes a possibly multi-line block of text, additionally guarding
+// lines that roff would otherwise read as requests.
+func manText(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = manEscape(strings.TrimRight(line, " \t"))
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			line = "\\&" + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
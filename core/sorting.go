@@ -177,8 +177,19 @@ func compare(i *Interpreter, fieldNode rl.Node, a, b RadValue) int {
 		return 0
 	case RadString:
 		return aVal.Compare(b.RequireStr(i, fieldNode))
-	case *RadList, *RadMap:
-		return 0 // all arrays and maps are considered equal
+	case RadDatetime:
+		return aVal.Time.Compare(b.Val.(RadDatetime).Time)
+	case RadDuration:
+		bVal := b.Val.(RadDuration)
+		if aVal.Dur < bVal.Dur {
+			return -1
+		}
+		if aVal.Dur > bVal.Dur {
+			return 1
+		}
+		return 0
+	case *RadList, *RadMap, *RadSet, *RadIter:
+		return 0 // all arrays, maps, sets and iterators are considered equal
 	default:
 		i.emitError(rl.ErrInternalBug, fieldNode, "Bug: Unsupported type for sorting")
 		panic(UNREACHABLE)
@@ -201,6 +212,14 @@ func precedence(i *Interpreter, fieldNode rl.Node, v RadValue) int {
 		return 5
 	case rl.RadFnT:
 		return 6
+	case rl.RadDatetimeT:
+		return 7
+	case rl.RadDurationT:
+		return 8
+	case rl.RadSetT:
+		return 9
+	case rl.RadIterT:
+		return 10
 	default:
 		i.emitError(rl.ErrInternalBug, fieldNode, "Unsupported type precedence for sorting")
 		panic(UNREACHABLE)
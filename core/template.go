@@ -0,0 +1,495 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/rl"
+)
+
+// A radTemplate is text with `{{ }}` tags, compiled once and then rendered
+// against a map of variables:
+//
+//	{{ expr }}, {{ expr:fmt }}         the value, as a string's {expr:fmt} would give it
+//	{{ for x in xs }} ... {{ end }}    any for loop header, e.g. `for k, v in m with loop`
+//	{{ if c }} ... {{ elif c }} ... {{ else }} ... {{ end }}
+//	{{# comment }}
+//
+// `{{-` and `-}}` trim the whitespace before or after a tag. A block tag or
+// comment alone on its line takes the whole line with it, so they can sit on
+// lines of their own without leaving blank ones behind.
+//
+// Expressions are Rad, parsed by the Rad parser, so everything an
+// interpolation can do a template can too.
+type radTemplate struct {
+	nodes []tplNode
+}
+
+// tplFile names the source of a template's expressions in diagnostics.
+const tplFile = "<template>"
+
+type tplNode interface{}
+
+type tplText struct {
+	text string
+}
+
+// tplExpr is an interpolation. It's compiled to a one-segment string
+// literal, which is what gives it the format specifiers.
+type tplExpr struct {
+	src string
+	lit *rl.LitString
+}
+
+type tplFor struct {
+	src  string
+	loop *rl.ForLoop
+	body []tplNode
+}
+
+type tplIf struct {
+	branches []tplBranch
+}
+
+// tplBranch is one arm of an if; cond is nil for the else.
+type tplBranch struct {
+	src  string
+	cond rl.Node
+	body []tplNode
+}
+
+type tplTokenKind int
+
+const (
+	tplTokText tplTokenKind = iota
+	tplTokTag
+)
+
+type tplToken struct {
+	kind tplTokenKind
+	text string // the text, or the tag's trimmed contents
+	line int
+	// from `{{-` and `-}}`
+	trimBefore bool
+	trimAfter  bool
+}
+
+// isBlock reports whether a tag is a block tag or comment, rather than an
+// interpolation.
+func (t tplToken) isBlock() bool {
+	if t.kind != tplTokTag {
+		return false
+	}
+	word, _ := splitTplDirective(t.text)
+	switch word {
+	case "#", "for", "if", "elif", "else", "end":
+		return true
+	}
+	return false
+}
+
+// splitTplDirective splits a tag's contents into its directive and the
+// rest, e.g. "for x in xs" into "for" and "x in xs". An interpolation has
+// no directive.
+func splitTplDirective(tag string) (string, string) {
+	if strings.HasPrefix(tag, "#") {
+		return "#", ""
+	}
+	word, rest, _ := strings.Cut(tag, " ")
+	switch word {
+	case "for", "if", "elif":
+		return word, strings.TrimSpace(rest)
+	case "else", "end":
+		if strings.TrimSpace(rest) == "" {
+			return word, ""
+		}
+	}
+	return "", tag
+}
+
+// compileTemplate parses src into a template. Errors name the line of the
+// offending tag.
+func compileTemplate(src string) (*radTemplate, error) {
+	tokens, err := lexTemplate(src)
+	if err != nil {
+		return nil, err
+	}
+	trimTemplateWhitespace(tokens)
+
+	parser, err := rts.NewRadParser()
+	if err != nil {
+		return nil, err
+	}
+	c := &tplCompiler{parser: parser, tokens: tokens}
+	nodes, stop, err := c.compileUntil()
+	if err != nil {
+		return nil, err
+	}
+	if stop != nil {
+		word, _ := splitTplDirective(stop.text)
+		return nil, fmt.Errorf("line %d: '{{ %s }}' without an open block", stop.line, word)
+	}
+	return &radTemplate{nodes: nodes}, nil
+}
+
+// lexTemplate splits src into text and tags.
+func lexTemplate(src string) ([]tplToken, error) {
+	var tokens []tplToken
+	line := 1
+	pos := 0
+	for pos < len(src) {
+		start := strings.Index(src[pos:], "{{")
+		if start < 0 {
+			tokens = append(tokens, tplToken{kind: tplTokText, text: src[pos:], line: line})
+			break
+		}
+		start += pos
+		if start > pos {
+			tokens = append(tokens, tplToken{kind: tplTokText, text: src[pos:start], line: line})
+			line += strings.Count(src[pos:start], "\n")
+		}
+
+		end, ok := findTagEnd(src, start+2)
+		if !ok {
+			return nil, fmt.Errorf("line %d: '{{' is never closed with '}}'", line)
+		}
+		inner := src[start+2 : end]
+		tok := tplToken{kind: tplTokTag, line: line}
+		// the space is required, so `{{-1}}` stays a negative number
+		if strings.HasPrefix(inner, "- ") {
+			tok.trimBefore = true
+			inner = inner[1:]
+		}
+		if strings.HasSuffix(inner, " -") {
+			tok.trimAfter = true
+			inner = inner[:len(inner)-1]
+		}
+		tok.text = strings.TrimSpace(inner)
+		if tok.text == "" {
+			return nil, fmt.Errorf("line %d: empty '{{ }}'", line)
+		}
+		tokens = append(tokens, tok)
+		line += strings.Count(src[start:end+2], "\n")
+		pos = end + 2
+	}
+	return tokens, nil
+}
+
+// findTagEnd returns the index of the `}}` closing a tag whose contents
+// start at from. Braces and quotes in the expression are skipped over, so
+// `{{ {"a": 1} }}` closes at the end.
+func findTagEnd(src string, from int) (int, bool) {
+	depth := 0
+	var quote byte
+	for idx := from; idx < len(src); idx++ {
+		ch := src[idx]
+		if quote != 0 {
+			if ch == '\\' {
+				idx++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'', '`':
+			quote = ch
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				if idx+1 < len(src) && src[idx+1] == '}' {
+					return idx, true
+				}
+				continue
+			}
+			depth--
+		}
+	}
+	return 0, false
+}
+
+// trimTemplateWhitespace applies `{{-`/`-}}`, and removes the lines of
+// block tags that stand alone on them.
+func trimTemplateWhitespace(tokens []tplToken) {
+	standalone := make([]bool, len(tokens))
+	for idx, tok := range tokens {
+		standalone[idx] = tok.isBlock() && startsLine(tokens, idx) && endsLine(tokens, idx)
+	}
+
+	for idx, tok := range tokens {
+		if tok.kind != tplTokTag {
+			continue
+		}
+		if idx > 0 && tokens[idx-1].kind == tplTokText {
+			prev := &tokens[idx-1]
+			if tok.trimBefore {
+				prev.text = strings.TrimRight(prev.text, " \t\r\n")
+			} else if standalone[idx] {
+				prev.text = strings.TrimRight(prev.text, " \t")
+			}
+		}
+		if idx+1 < len(tokens) && tokens[idx+1].kind == tplTokText {
+			next := &tokens[idx+1]
+			if tok.trimAfter {
+				next.text = strings.TrimLeft(next.text, " \t\r\n")
+			} else if standalone[idx] {
+				rest := strings.TrimLeft(next.text, " \t")
+				rest = strings.TrimPrefix(rest, "\r")
+				next.text = strings.TrimPrefix(rest, "\n")
+			}
+		}
+	}
+}
+
+// startsLine reports whether only spaces separate the tag at idx from the
+// start of its line.
+func startsLine(tokens []tplToken, idx int) bool {
+	if idx == 0 {
+		return true
+	}
+	prev := tokens[idx-1]
+	if prev.kind != tplTokText {
+		return false
+	}
+	lastNewline := strings.LastIndex(prev.text, "\n")
+	if lastNewline < 0 && idx-1 > 0 {
+		return false
+	}
+	return strings.Trim(prev.text[lastNewline+1:], " \t") == ""
+}
+
+// endsLine reports whether only spaces separate the tag at idx from the
+// end of its line.
+func endsLine(tokens []tplToken, idx int) bool {
+	if idx == len(tokens)-1 {
+		return true
+	}
+	next := tokens[idx+1]
+	if next.kind != tplTokText {
+		return false
+	}
+	firstNewline := strings.Index(next.text, "\n")
+	if firstNewline < 0 {
+		return idx+1 == len(tokens)-1 && strings.Trim(next.text, " \t") == ""
+	}
+	return strings.Trim(next.text[:firstNewline], " \t\r") == ""
+}
+
+type tplCompiler struct {
+	parser *rts.RadParser
+	tokens []tplToken
+	pos    int
+}
+
+// compileUntil compiles nodes up to the end of the template, or up to a
+// tag that continues or closes the enclosing block (elif, else or end),
+// which it consumes and returns.
+func (c *tplCompiler) compileUntil() ([]tplNode, *tplToken, error) {
+	var nodes []tplNode
+	for c.pos < len(c.tokens) {
+		tok := c.tokens[c.pos]
+		c.pos++
+		if tok.kind == tplTokText {
+			if tok.text != "" {
+				nodes = append(nodes, tplText{text: tok.text})
+			}
+			continue
+		}
+
+		word, rest := splitTplDirective(tok.text)
+		switch word {
+		case "#":
+			// comment
+		case "elif", "else", "end":
+			return nodes, &tok, nil
+		case "for":
+			node, err := c.compileFor(tok, rest)
+			if err != nil {
+				return nil, nil, err
+			}
+			nodes = append(nodes, node)
+		case "if":
+			node, err := c.compileIf(tok, rest)
+			if err != nil {
+				return nil, nil, err
+			}
+			nodes = append(nodes, node)
+		default:
+			node, err := c.compileExpr(tok)
+			if err != nil {
+				return nil, nil, err
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil, nil
+}
+
+func (c *tplCompiler) compileFor(tok tplToken, header string) (tplNode, error) {
+	src := "for " + header + ":\n    pass\n"
+	stmt, ok := c.parseStmt(src)
+	loop, isLoop := stmt.(*rl.ForLoop)
+	if !ok || !isLoop {
+		return nil, fmt.Errorf("line %d: invalid for loop '{{ for %s }}'", tok.line, header)
+	}
+
+	body, stop, err := c.compileUntil()
+	if err != nil {
+		return nil, err
+	}
+	if stop == nil || stop.text != "end" {
+		return nil, fmt.Errorf("line %d: '{{ for }}' is never closed with '{{ end }}'", tok.line)
+	}
+	return tplFor{src: src, loop: loop, body: body}, nil
+}
+
+func (c *tplCompiler) compileIf(tok tplToken, cond string) (tplNode, error) {
+	var node tplIf
+	hasElse := false
+	for {
+		branch := tplBranch{}
+		if !hasElse {
+			expr, err := c.parseCond(tok, cond)
+			if err != nil {
+				return nil, err
+			}
+			branch.src = cond
+			branch.cond = expr
+		}
+
+		body, stop, err := c.compileUntil()
+		if err != nil {
+			return nil, err
+		}
+		branch.body = body
+		node.branches = append(node.branches, branch)
+
+		if stop == nil {
+			return nil, fmt.Errorf("line %d: '{{ if }}' is never closed with '{{ end }}'", tok.line)
+		}
+		word, rest := splitTplDirective(stop.text)
+		switch {
+		case word == "end":
+			return node, nil
+		case hasElse:
+			return nil, fmt.Errorf("line %d: '{{ %s }}' after '{{ else }}'", stop.line, word)
+		case word == "elif":
+			tok, cond = *stop, rest
+		case word == "else":
+			hasElse = true
+		}
+	}
+}
+
+func (c *tplCompiler) parseCond(tok tplToken, cond string) (rl.Node, error) {
+	stmt, ok := c.parseStmt(cond)
+	exprStmt, isExpr := stmt.(*rl.ExprStmt)
+	if !ok || !isExpr || exprStmt.Catch != nil {
+		return nil, fmt.Errorf("line %d: invalid condition '%s'", tok.line, cond)
+	}
+	return exprStmt.Expr, nil
+}
+
+func (c *tplCompiler) compileExpr(tok tplToken) (tplNode, error) {
+	// wrapped as the only interpolation in a string, quoted with whichever
+	// quote the expression doesn't use itself
+	var src string
+	for _, quote := range []string{`"`, `'`, "`"} {
+		if !strings.Contains(tok.text, quote) {
+			src = quote + "{" + tok.text + "}" + quote
+			break
+		}
+	}
+	if src == "" {
+		return nil, fmt.Errorf("line %d: '{{ %s }}' can't use all three kinds of quote", tok.line, tok.text)
+	}
+
+	stmt, ok := c.parseStmt(src)
+	exprStmt, isExpr := stmt.(*rl.ExprStmt)
+	if ok && isExpr {
+		if lit, isLit := exprStmt.Expr.(*rl.LitString); isLit && len(lit.Segments) == 1 && !lit.Segments[0].IsLiteral {
+			return tplExpr{src: src, lit: lit}, nil
+		}
+	}
+	return nil, fmt.Errorf("line %d: invalid expression '{{ %s }}'", tok.line, tok.text)
+}
+
+// parseStmt parses src as Rad, returning its only statement.
+func (c *tplCompiler) parseStmt(src string) (rl.Node, bool) {
+	tree := c.parser.Parse(src)
+	defer tree.Close()
+	if tree.HasInvalidNodes() {
+		return nil, false
+	}
+	ast := tryConvertAST(tree, src, tplFile)
+	if ast == nil || len(ast.Stmts) != 1 {
+		return nil, false
+	}
+	return ast.Stmts[0], true
+}
+
+// render runs the template with vars in scope, along with the builtins.
+// Nothing else of the script's is visible to it.
+func (t *radTemplate) render(i *Interpreter, vars *RadMap) (string, error) {
+	env := NewEnv(i)
+	for name, fn := range FunctionsByName {
+		env.SetVar(name, newRadValueFn(NewBuiltIn(fn)))
+	}
+	scope := env.NewChildEnv()
+	for _, key := range vars.Keys() {
+		name, ok := key.TryGetStr()
+		if !ok {
+			return "", fmt.Errorf("variable names must be strings, got %s", TypeAsString(key))
+		}
+		val, _ := vars.Get(key)
+		scope.SetVar(name.Plain(), val)
+	}
+
+	saved := i.env
+	i.env = &scope
+	defer func() { i.env = saved }()
+
+	var sb strings.Builder
+	renderTplNodes(i, &sb, t.nodes)
+	return sb.String(), nil
+}
+
+func renderTplNodes(i *Interpreter, sb *strings.Builder, nodes []tplNode) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case tplText:
+			sb.WriteString(n.text)
+		case tplExpr:
+			i.WithTmpSrc(n.src, func() {
+				sb.WriteString(i.evalString(n.lit).Plain())
+			})
+		case tplIf:
+			for _, branch := range n.branches {
+				matched := branch.cond == nil
+				if !matched {
+					i.WithTmpSrc(branch.src, func() {
+						matched = i.eval(branch.cond).Val.TruthyFalsy()
+					})
+				}
+				if matched {
+					renderTplNodes(i, sb, branch.body)
+					break
+				}
+			}
+		case tplFor:
+			// the loop's variables don't outlive it
+			saved := i.env
+			scope := saved.NewChildEnv()
+			i.env = &scope
+			i.WithTmpSrc(n.src, func() {
+				i.executeForLoop(n.loop, func() EvalResult {
+					renderTplNodes(i, sb, n.body)
+					return VoidNormal
+				})
+			})
+			i.env = saved
+		}
+	}
+}
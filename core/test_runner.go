@@ -0,0 +1,287 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/rl"
+)
+
+// testFileSuffix marks the files `rad test` looks for in a directory.
+const testFileSuffix = "_test.rad"
+
+// testFnPrefix marks the functions in a test file that are tests.
+const testFnPrefix = "test_"
+
+// radTestRun is one test under `rad test`: what it has mocked, and the calls
+// it has made. While a test runs it stands in for the shell, the network and
+// the clock, so nothing a test does reaches outside it.
+type radTestRun struct {
+	shellMocks []shellMock
+	calls      []string
+	requester  *Requester
+	clock      *FixedClock
+}
+
+type shellMock struct {
+	pattern *regexp.Regexp
+	stdout  string
+	stderr  string
+	code    int
+}
+
+// currentTestRun is the test running now, or nil outside of `rad test`,
+// which is how the mock functions know they've been called out of place.
+var currentTestRun *radTestRun
+
+func newRadTestRun() *radTestRun {
+	test := &radTestRun{
+		requester: NewRequester(),
+		// frozen at the real time the test starts, until mock_now says otherwise
+		clock: &FixedClock{NowTime: RClock.Now()},
+	}
+	test.requester.SetMockedOnly(true)
+	test.requester.SetCaptureCallback(func(req HttpRequest) {
+		test.calls = append(test.calls, req.RequestDef.Method+" "+req.RequestDef.Url)
+	})
+	return test
+}
+
+// shell is the test's ShellExecutor. It answers with the most recently added
+// mock that matches, and fails commands no mock matches as if they didn't
+// exist, rather than running them.
+func (t *radTestRun) shell(_ context.Context, invocation ShellInvocation) (string, string, int) {
+	cmd := invocation.Display()
+	t.calls = append(t.calls, cmd)
+	if !invocation.IsQuiet {
+		RP.RadStderrf("⚡️ %s\n", cmd)
+	}
+
+	stdout, stderr, code := "", fmt.Sprintf("No mock for command: %s\n", cmd), 127
+	for idx := len(t.shellMocks) - 1; idx >= 0; idx-- {
+		if mock := t.shellMocks[idx]; mock.pattern.MatchString(cmd) {
+			stdout, stderr, code = mock.stdout, mock.stderr, mock.code
+			break
+		}
+	}
+
+	if !invocation.CaptureStdout {
+		fmt.Fprint(RIo.StdOut, stdout)
+		stdout = ""
+	}
+	if !invocation.CaptureStderr {
+		fmt.Fprint(RIo.StdErr, stderr)
+		stderr = ""
+	}
+	return stdout, stderr, code
+}
+
+// sleep advances the test's clock instead of waiting.
+func (t *radTestRun) sleep(_ context.Context, duration time.Duration) {
+	t.clock.NowTime = t.clock.NowTime.Add(duration)
+}
+
+// isolated runs fn as part of the test in the file at path. Output is
+// captured rather than printed, the test's mocks replace the real shell,
+// network and clock, and an error unwinds back here instead of ending rad.
+// ok is false if fn ended in an error or a non-zero exit.
+func (t *radTestRun) isolated(path string, fn func()) (output string, ok bool) {
+	var out bytes.Buffer
+	savedIo, savedPrinter := RIo, RP
+	savedShell, savedReq, savedClock, savedSleep := RShell, RReq, RClock, RSleep
+	savedScriptPath := ScriptPath
+
+	RIo = RadIo{StdIn: NewBufferReader(&bytes.Buffer{}), StdOut: &out, StdErr: &out}
+	RP = NewPrinter(nil, false, false, FlagDebug.Value, FlagRadDebug.Value)
+	RShell, RReq, RClock, RSleep = t.shell, t.requester, t.clock, t.sleep
+	SetScriptPath(path)
+	currentTestRun = t
+
+	RExit.SetUnwinding(true)
+	RExit.ResetExiting()
+	RExit.SetExecuteDeferredStmtsFunc(func(code int) {})
+
+	defer func() {
+		r := recover()
+
+		currentTestRun = nil
+		SetScriptPath(savedScriptPath)
+		RShell, RReq, RClock, RSleep = savedShell, savedReq, savedClock, savedSleep
+		RIo, RP = savedIo, savedPrinter
+		RExit.SetUnwinding(false)
+		RExit.ResetExiting()
+
+		if r != nil {
+			abort, isAbort := r.(*RadAbort)
+			if !isAbort {
+				panic(r)
+			}
+			ok = abort.Code == 0
+			if !ok && abort.Reason == ExitExplicit {
+				fmt.Fprintf(&out, "Exited with code %d\n", abort.Code)
+			}
+		}
+		output = out.String()
+	}()
+
+	fn()
+	return "", true
+}
+
+// testOutcome is the result of one test, or of a test file that couldn't be
+// loaded, in which case name is empty.
+type testOutcome struct {
+	file   string
+	name   string
+	passed bool
+	output string
+}
+
+// runTestFile runs the tests in the file at path whose names match run, each
+// in an interpreter of its own that has run the file's top level afresh.
+func runTestFile(path string, run *regexp.Regexp) []testOutcome {
+	fileFailure := func(output string) []testOutcome {
+		return []testOutcome{{file: path, output: output}}
+	}
+
+	src, err := readSource(path)
+	if err != nil {
+		return fileFailure(err.Error() + "\n")
+	}
+	parser, err := rts.NewRadParser()
+	if err != nil {
+		return fileFailure(err.Error() + "\n")
+	}
+	tree := parser.Parse(src)
+
+	var ast *rl.SourceFile
+	output, ok := newRadTestRun().isolated(path, func() {
+		ast, _ = validateSyntaxOf(path, src, tree, parser, nil)
+	})
+	if !ok {
+		return fileFailure(output)
+	}
+	if ast.Args != nil || len(ast.Cmds) > 0 {
+		return fileFailure("A test file can't declare args or commands\n")
+	}
+
+	var outcomes []testOutcome
+	for _, stmt := range ast.Stmts {
+		fnDef, isFn := stmt.(*rl.FnDef)
+		if !isFn || !strings.HasPrefix(fnDef.Name, testFnPrefix) {
+			continue
+		}
+		if run != nil && !run.MatchString(fnDef.Name) {
+			continue
+		}
+		outcome := testOutcome{file: path, name: fnDef.Name}
+		if fnDef.Typing != nil && len(fnDef.Typing.Params) > 0 {
+			outcome.output = "A test function can't take parameters\n"
+			outcomes = append(outcomes, outcome)
+			continue
+		}
+
+		outcome.output, outcome.passed = newRadTestRun().isolated(path, func() {
+			interp := NewInterpreter(InterpreterInput{Src: src, Tree: tree, ScriptName: path})
+			interp.sd.Ast = ast
+			interp.InitBuiltIns()
+			RExit.SetExecuteDeferredStmtsFunc(interp.executeDeferBlocks)
+
+			interp.safelyExecuteTopLevel(ast)
+			interp.safelyExecuteTestFn(fnDef)
+			interp.executeDeferBlocks(0)
+		})
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+func (i *Interpreter) safelyExecuteTestFn(fnDef *rl.FnDef) {
+	defer func() {
+		i.handlePanicRecovery(recover(), fnDef)
+	}()
+
+	val, _ := i.env.GetVar(fnDef.Name)
+	fn, ok := val.TryGetFn()
+	if !ok {
+		i.emitErrorf(rl.ErrTypeMismatch, fnDef, "Cannot run test '%s': it was reassigned to a %s",
+			fnDef.Name, TypeAsString(val))
+	}
+	_ = fn.Execute(NewFnInvocation(i, nil, fnDef.Name, []PosArg{}, NO_NAMED_ARGS_INPUT, false))
+}
+
+// findTestFiles returns path if it's a file, or else the test files in the
+// directory tree under it, skipping hidden directories.
+func findTestFiles(path string) ([]string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), testFileSuffix) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// FuncInternalRunTests implements _rad_run_tests, which `rad test` reports on.
+var FuncInternalRunTests = BuiltInFunc{
+	Name: INTERNAL_FUNC_RUN_TESTS,
+	Execute: func(f FuncInvocation) RadValue {
+		var run *regexp.Regexp
+		if runArg := f.GetArg("_run"); !runArg.IsNull() {
+			pattern := runArg.RequireStr(f.i, f.callNode).Plain()
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				f.i.emitErrorf(rl.ErrInvalidRegex, f.callNode, "Invalid test filter %q: %v", pattern, err)
+			}
+			run = re
+		}
+
+		results := NewRadList()
+		for _, pathVal := range f.GetList("_paths").Values {
+			path := pathVal.RequireStr(f.i, f.callNode).Plain()
+			files, err := findTestFiles(path)
+			if err != nil {
+				f.i.emitErrorf(rl.ErrFileRead, f.callNode, "Cannot test '%s': %v", path, err)
+			}
+			for _, file := range files {
+				for _, outcome := range runTestFile(file, run) {
+					result := NewRadMap()
+					result.SetPrimitiveStr("file", outcome.file)
+					result.SetPrimitiveStr("name", outcome.name)
+					result.SetPrimitiveBool("passed", outcome.passed)
+					result.SetPrimitiveStr("output", outcome.output)
+					results.Append(newRadValueMap(result))
+				}
+			}
+		}
+		// the tests had the exit handler running their deferred blocks
+		f.i.RegisterWithExit()
+		return f.Return(results)
+	},
+}
@@ -109,6 +109,14 @@ var docSnippetTolerances = map[string]Tolerance{
 		ExpectedCodes: []string{"RAD20031"},
 		Reason:        "demo: continue outside a loop. (Old incidental RAD30002 gone now that numeric-union comparisons type-check.)",
 	},
+	"core/error_docs/20051.md#490c608f": {
+		ExpectedCodes: []string{"RAD20051"},
+		Reason:        "demo: record field spec naming a type that doesn't exist ('integer').",
+	},
+	"core/error_docs/20051.md#851de9ec": {
+		ExpectedCodes: []string{"RAD20051"},
+		Reason:        "demo: record field name that isn't an identifier ('due-date').",
+	},
 	"core/error_docs/30002.md#f81555f7": {
 		ExpectedCodes: []string{"RAD30002"},
 		Reason:        "demo: `str + int` invalid operand types.",
@@ -333,6 +341,7 @@ var docSnippetTolerances = map[string]Tolerance{
 	"core/error_docs/40026.md#f515e1ea":              {ExpectedCodes: []string{"RAD40026"}, Reason: "error_docs demo: shows the pre-v0.12 computed-command spelling"},
 	"core/error_docs/40026.md#86daec83":              {ExpectedCodes: []string{"RAD20028"}, Reason: "error_docs fix example: `parts` stands in for the reader's own variable"},
 	"core/error_docs/40026.md#ca1b628a":              {ExpectedCodes: []string{"RAD20028"}, Reason: "error_docs fix example: `cmds` stands in for the reader's own variable"},
+	"core/error_docs/40027.md#0928393e":              {ExpectedCodes: []string{"RAD40027"}, Reason: "error_docs demo: shows a rad-ignore on a line where its code doesn't fire"},
 	// ---- docs-web/docs/examples/* ----------------------------------
 	// Tutorial pages build up scripts incrementally. Intermediate
 	// snippets call into functions defined later, show fragments
@@ -0,0 +1,72 @@
+package testing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// renderDirFixture makes a template directory holding one file whose name is
+// a template, and returns the src and dest paths to render between.
+func renderDirFixture(t *testing.T) (root, src, dest string) {
+	root = filepath.ToSlash(t.TempDir())
+	src = root + "/src"
+	dest = root + "/out"
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", src, err)
+	}
+	if err := os.WriteFile(src+"/{{ name }}.txt", []byte("hi {{ name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+	return root, src, dest
+}
+
+func Test_Func_RenderDir_RendersNamesAndContents(t *testing.T) {
+	_, src, dest := renderDirFixture(t)
+	script := fmt.Sprintf(`
+written = render_dir("%s", "%s", { "name": "api" })
+print(len(written))
+`, src, dest)
+	setupAndRunCode(t, script, "--color=never")
+	assertOnlyOutput(t, stdOutBuffer, "1\n")
+	assertNoErrors(t)
+
+	content, err := os.ReadFile(dest + "/api.txt")
+	if err != nil || string(content) != "hi api" {
+		t.Fatalf("Expected %s/api.txt to hold %q, got %q (err=%v)", dest, "hi api", content, err)
+	}
+}
+
+func Test_Func_RenderDir_AllowsNamesStartingWithTwoDots(t *testing.T) {
+	_, src, dest := renderDirFixture(t)
+	script := fmt.Sprintf(`
+written = render_dir("%s", "%s", { "name": "..env" })
+print(len(written))
+`, src, dest)
+	setupAndRunCode(t, script, "--color=never")
+	assertOnlyOutput(t, stdOutBuffer, "1\n")
+	assertNoErrors(t)
+
+	if _, err := os.Stat(dest + "/..env.txt"); err != nil {
+		t.Fatalf("Expected %s/..env.txt to be written: %v", dest, err)
+	}
+}
+
+func Test_Func_RenderDir_RejectsNamesOutsideDest(t *testing.T) {
+	root, src, dest := renderDirFixture(t)
+	script := fmt.Sprintf(`
+out = render_dir("%s", "%s", { "name": "../escaped" }) catch:
+    print(out)
+`, src, dest)
+	setupAndRunCode(t, script, "--color=never")
+	expected := fmt.Sprintf("Cannot render %q to %q: it's outside %q\n", "{{ name }}.txt", root+"/escaped.txt", dest)
+	assertOnlyOutput(t, stdOutBuffer, expected)
+	assertNoErrors(t)
+
+	for _, path := range []string{root + "/escaped.txt", dest} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("Expected nothing at %s, got err=%v", path, err)
+		}
+	}
+}
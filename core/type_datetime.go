@@ -0,0 +1,210 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+// RadDatetime is a point in time, carrying the zone it's displayed in.
+type RadDatetime struct {
+	Time time.Time
+}
+
+// RadDuration is a signed span of time, with nanosecond precision.
+type RadDuration struct {
+	Dur time.Duration
+}
+
+const datetimeLayout = time.RFC3339Nano
+
+func newRadValueDatetime(t time.Time) RadValue {
+	return newRadValue(nil, nil, RadDatetime{Time: t})
+}
+
+func newRadValueDuration(d time.Duration) RadValue {
+	return newRadValue(nil, nil, RadDuration{Dur: d})
+}
+
+func (d RadDatetime) ToString() string {
+	return d.Time.Format(datetimeLayout)
+}
+
+// tzName is the zone the datetime is shown in. Go calls the local zone
+// "Local", which says nothing, so that's reported by its abbreviation.
+func (d RadDatetime) tzName() string {
+	name := d.Time.Location().String()
+	if name == "Local" {
+		name, _ = d.Time.Zone()
+	}
+	return name
+}
+
+// Field returns the named part of the datetime. The fields are those of the
+// map returned by now(), plus the zone.
+func (d RadDatetime) Field(name string) (RadValue, bool) {
+	if name == "tz" {
+		return newRadValueStr(d.tzName()), true
+	}
+	return NewTimeMap(d.Time).Get(newRadValueStr(name))
+}
+
+// ToString writes the duration in the form parse_duration reads, e.g.
+// "3d2h30m", "1.5s" or "250ms", largest unit first and zero units omitted.
+func (d RadDuration) ToString() string {
+	dur := d.Dur
+	if dur == 0 {
+		return "0s"
+	}
+
+	var sb strings.Builder
+	if dur < 0 {
+		sb.WriteString("-")
+		if dur == math.MinInt64 {
+			// can't be negated; Go's own form is as good as any
+			return dur.String()
+		}
+		dur = -dur
+	}
+
+	day := 24 * time.Hour
+	large := false
+	for _, unit := range []struct {
+		size   time.Duration
+		suffix string
+	}{{day, "d"}, {time.Hour, "h"}, {time.Minute, "m"}} {
+		if n := dur / unit.size; n > 0 {
+			sb.WriteString(strconv.FormatInt(int64(n), 10))
+			sb.WriteString(unit.suffix)
+			dur -= n * unit.size
+			large = true
+		}
+	}
+
+	if dur != 0 {
+		if large || dur >= time.Second {
+			sb.WriteString(strconv.FormatFloat(dur.Seconds(), 'f', -1, 64))
+			sb.WriteString("s")
+		} else {
+			// sub-second, e.g. "250ms" or "1.5µs"
+			sb.WriteString(dur.String())
+		}
+	}
+	return sb.String()
+}
+
+// Field returns the duration in the named unit, as parse_duration does.
+func (d RadDuration) Field(name string) (RadValue, bool) {
+	return NewDurationMap(int64(d.Dur)).Get(newRadValueStr(name))
+}
+
+// datetimeOp applies op to a datetime and some right operand. Returns false
+// if the combination isn't supported.
+func datetimeOp(op rl.Operator, left RadDatetime, right interface{}) (interface{}, bool) {
+	switch coerced := right.(type) {
+	case RadDatetime:
+		switch op {
+		case rl.OpSub:
+			return RadDuration{Dur: left.Time.Sub(coerced.Time)}, true
+		case rl.OpGt:
+			return left.Time.After(coerced.Time), true
+		case rl.OpGte:
+			return !left.Time.Before(coerced.Time), true
+		case rl.OpLt:
+			return left.Time.Before(coerced.Time), true
+		case rl.OpLte:
+			return !left.Time.After(coerced.Time), true
+		}
+	case RadDuration:
+		switch op {
+		case rl.OpAdd:
+			return RadDatetime{Time: left.Time.Add(coerced.Dur)}, true
+		case rl.OpSub:
+			return RadDatetime{Time: left.Time.Add(-coerced.Dur)}, true
+		}
+	}
+	return nil, false
+}
+
+// durationOp applies op to a duration and some right operand. Returns false
+// if the combination isn't supported.
+func (i *Interpreter) durationOp(node, rightNode rl.Node, op rl.Operator, left RadDuration, right interface{}) (interface{}, bool) {
+	switch coerced := right.(type) {
+	case RadDuration:
+		switch op {
+		case rl.OpAdd:
+			return RadDuration{Dur: i.checkedDuration(node, float64(left.Dur)+float64(coerced.Dur), left.Dur+coerced.Dur)}, true
+		case rl.OpSub:
+			return RadDuration{Dur: i.checkedDuration(node, float64(left.Dur)-float64(coerced.Dur), left.Dur-coerced.Dur)}, true
+		case rl.OpDiv:
+			if coerced.Dur == 0 {
+				i.emitError(rl.ErrDivisionByZero, rightNode, "Divisor was 0, cannot divide by 0")
+			}
+			return float64(left.Dur) / float64(coerced.Dur), true
+		case rl.OpGt:
+			return left.Dur > coerced.Dur, true
+		case rl.OpGte:
+			return left.Dur >= coerced.Dur, true
+		case rl.OpLt:
+			return left.Dur < coerced.Dur, true
+		case rl.OpLte:
+			return left.Dur <= coerced.Dur, true
+		}
+	case RadDatetime:
+		switch op {
+		case rl.OpAdd:
+			return RadDatetime{Time: coerced.Time.Add(left.Dur)}, true
+		}
+	case int64:
+		switch op {
+		case rl.OpMul:
+			return i.scaleDuration(node, left.Dur, coerced), true
+		case rl.OpDiv:
+			if coerced == 0 {
+				i.emitError(rl.ErrDivisionByZero, rightNode, "Divisor was 0, cannot divide by 0")
+			}
+			return RadDuration{Dur: left.Dur / time.Duration(coerced)}, true
+		}
+	case float64:
+		switch op {
+		case rl.OpMul:
+			return i.scaleDuration(node, left.Dur, coerced), true
+		case rl.OpDiv:
+			if coerced == 0 {
+				i.emitError(rl.ErrDivisionByZero, rightNode, "Divisor was 0, cannot divide by 0")
+			}
+			return i.roundedDuration(node, float64(left.Dur)/coerced), true
+		}
+	}
+	return nil, false
+}
+
+// scaleDuration multiplies d by an int or float factor, as in `3 * d`.
+func (i *Interpreter) scaleDuration(node rl.Node, d time.Duration, factor interface{}) RadDuration {
+	switch coerced := factor.(type) {
+	case int64:
+		return RadDuration{Dur: i.checkedDuration(node, float64(d)*float64(coerced), d*time.Duration(coerced))}
+	case float64:
+		return i.roundedDuration(node, float64(d)*coerced)
+	}
+	panic(fmt.Sprintf("Bug! Unhandled duration factor: %T", factor))
+}
+
+// roundedDuration rounds nanos, the result of float arithmetic, to a duration.
+func (i *Interpreter) roundedDuration(node rl.Node, nanos float64) RadDuration {
+	nanos = math.Round(nanos)
+	return RadDuration{Dur: i.checkedDuration(node, nanos, time.Duration(nanos))}
+}
+
+// checkedDuration returns result, unless approx (the same calculation done in
+// floats) shows it overflowed. Durations span roughly 292 years either way.
+func (i *Interpreter) checkedDuration(node rl.Node, approx float64, result time.Duration) time.Duration {
+	if math.IsNaN(approx) || approx >= math.MaxInt64 || approx < math.MinInt64 {
+		i.emitErrorf(rl.ErrNumInvalidRange, node, "Duration out of range, must be within about 292 years")
+	}
+	return result
+}
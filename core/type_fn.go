@@ -14,17 +14,25 @@ type RadFn struct {
 	Typing   *rl.TypingFnT
 	Stmts    []rl.Node
 	IsBlock  bool // if this is a block function or expr. Block functions can only return with a 'return' stmt.
-	Env      *Env // for closures
+	// if the body yields values; calling it then returns an iterator of them
+	IsGenerator bool
+	Env         *Env // for closures
+	// set if this is a matcher made by a pattern builtin, e.g. of_type()
+	Pattern *pattern
+	// set if this is a record's constructor, made by record(): the record's
+	// fields, as the constructor's signature
+	Record *rl.TypingFnT
 }
 
 // NewFnFromAST creates a RadFn from AST components (FnDef or Lambda).
 func NewFnFromAST(i *Interpreter, typing *rl.TypingFnT, body []rl.Node, isBlock bool, defSpan *rl.Span) RadFn {
 	return RadFn{
-		ReprSpan: defSpan,
-		Typing:   typing,
-		Stmts:    body,
-		IsBlock:  isBlock,
-		Env:      i.env,
+		ReprSpan:    defSpan,
+		Typing:      typing,
+		Stmts:       body,
+		IsBlock:     isBlock,
+		IsGenerator: isBlock && rl.IsGeneratorBody(body),
+		Env:         i.env,
 	}
 }
 
@@ -205,7 +213,15 @@ func (fn RadFn) Execute(f FuncInvocation) (out RadValue) {
 		}
 
 		// todo this should be more shared between the two branches?
-		if fn.BuiltInFunc == nil {
+		if fn.IsGenerator {
+			// the body runs as the iterator is consumed, in this call's env
+			var callSite *rl.Span
+			if f.callNode != nil {
+				cs := f.callNode.Span()
+				callSite = &cs
+			}
+			out = newRadValue(i, f.callNode, i.newGeneratorIter(fn, i.env, callSite))
+		} else if fn.BuiltInFunc == nil {
 			// Push call frame for user-defined functions
 			var callSite *rl.Span
 			if f.callNode != nil {
@@ -219,10 +235,16 @@ func (fn RadFn) Execute(f FuncInvocation) (out RadValue) {
 			i.pushCallFrame(fnName, callSite, fn.ReprSpan)
 			defer i.popCallFrame()
 
-			// break/continue don't cross function boundaries
+			// break/continue don't cross function boundaries, and nor
+			// does a generator's yield
 			savedLoopLevel := i.forWhileLoopLevel
+			savedGen := i.gen
 			i.forWhileLoopLevel = 0
-			defer func() { i.forWhileLoopLevel = savedLoopLevel }()
+			i.gen = nil
+			defer func() {
+				i.forWhileLoopLevel = savedLoopLevel
+				i.gen = savedGen
+			}()
 
 			res := i.runBlock(fn.Stmts)
 			typeCheck(i, typing.ReturnT, f.callNode, res.Val)
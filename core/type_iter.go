@@ -0,0 +1,119 @@
+package core
+
+import (
+	"github.com/amterp/rad/rts/rl"
+)
+
+// RadIter is a lazy sequence, such as the values of a generator function.
+// Values are produced only as they're consumed, and only once: a for loop
+// that breaks early stops the producer too, so an infinite generator or a
+// huge file never has to fit in memory.
+type RadIter struct {
+	// each passes the values in order to yield, until it returns false or
+	// the values run out.
+	each     func(yield func(RadValue) bool)
+	consumed bool
+}
+
+func NewRadIter(each func(yield func(RadValue) bool)) *RadIter {
+	return &RadIter{each: each}
+}
+
+// Each consumes the iterator, calling yield with each value until it
+// returns false.
+func (it *RadIter) Each(i *Interpreter, node rl.Node, yield func(RadValue) bool) {
+	if it.consumed {
+		i.emitErrorf(rl.ErrIteratorConsumed, node,
+			"Iterator has already been consumed, it can only be looped over once")
+	}
+	it.consumed = true
+	it.each(yield)
+}
+
+// Map returns an iterator of fn applied to each value, computed as it's read.
+func (it *RadIter) Map(i *Interpreter, node rl.Node, fn func(RadValue) RadValue) *RadIter {
+	return NewRadIter(func(yield func(RadValue) bool) {
+		it.Each(i, node, func(val RadValue) bool {
+			return yield(fn(val))
+		})
+	})
+}
+
+// Filter returns an iterator of the values keep accepts.
+func (it *RadIter) Filter(i *Interpreter, node rl.Node, keep func(RadValue) bool) *RadIter {
+	return NewRadIter(func(yield func(RadValue) bool) {
+		it.Each(i, node, func(val RadValue) bool {
+			if !keep(val) {
+				return true
+			}
+			return yield(val)
+		})
+	})
+}
+
+func (it *RadIter) ToString() string {
+	return "<iterator>"
+}
+
+// execState is the part of the interpreter's state that belongs to whichever
+// code is running: a generator's body, or the loop consuming it. The two
+// take turns, so the state is swapped at each yield.
+type execState struct {
+	env               *Env
+	forWhileLoopLevel int
+	callStack         []CallFrame
+	gen               *genContext
+}
+
+// genContext receives the values yielded by a running generator body.
+type genContext struct {
+	yield func(node rl.Node, val RadValue) bool
+}
+
+func (i *Interpreter) saveExecState() execState {
+	return execState{
+		env:               i.env,
+		forWhileLoopLevel: i.forWhileLoopLevel,
+		// copied, since whoever runs next appends over the frames beyond its own
+		callStack: append([]CallFrame(nil), i.callStack...),
+		gen:       i.gen,
+	}
+}
+
+func (i *Interpreter) restoreExecState(s execState) {
+	i.env = s.env
+	i.forWhileLoopLevel = s.forWhileLoopLevel
+	i.callStack = s.callStack
+	i.gen = s.gen
+}
+
+// newGeneratorIter returns the iterator a call to a generator function
+// gives. The body runs in env, which already has the call's arguments bound,
+// and only starts once the iterator is consumed.
+func (i *Interpreter) newGeneratorIter(fn RadFn, env *Env, callSite *rl.Span) *RadIter {
+	return NewRadIter(func(yield func(RadValue) bool) {
+		consumer := i.saveExecState()
+		defer i.restoreExecState(consumer)
+
+		i.env = env
+		i.forWhileLoopLevel = 0
+		fnName := fn.Name()
+		if fnName == "" {
+			fnName = "<anonymous>"
+		}
+		i.pushCallFrame(fnName, callSite, fn.ReprSpan)
+		i.gen = &genContext{
+			yield: func(node rl.Node, val RadValue) bool {
+				// a generator's declared return type is that of what it yields
+				if fn.Typing != nil {
+					typeCheck(i, fn.Typing.ReturnT, node, val)
+				}
+				producer := i.saveExecState()
+				defer i.restoreExecState(producer)
+				i.restoreExecState(consumer)
+				return yield(val)
+			},
+		}
+		i.runBlock(fn.Stmts)
+	})
+}
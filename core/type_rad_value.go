@@ -27,6 +27,9 @@ type RadValue struct {
 	// functions are RadFn
 	// nulls are RadNull
 	// errors are *RadError
+	// datetimes are RadDatetime, durations RadDuration
+	// sets are *RadSet
+	// iterators are *RadIter
 	Val interface{}
 }
 
@@ -50,6 +53,14 @@ func (v RadValue) Type() rl.RadType {
 		return rl.RadNullT
 	case *RadError:
 		return rl.RadErrorT
+	case RadDatetime:
+		return rl.RadDatetimeT
+	case RadDuration:
+		return rl.RadDurationT
+	case *RadSet:
+		return rl.RadSetT
+	case *RadIter:
+		return rl.RadIterT
 	default:
 		panic(fmt.Sprintf("Bug! Unhandled Rad type in Type: '%T'", v.Val))
 	}
@@ -83,6 +94,21 @@ func (v RadValue) Index(i *Interpreter, node rl.Node, key RadValue) RadValue {
 			NewErrorStrf("Cannot index into null").SetCode(rl.ErrCannotIndex))
 		i.NewRadPanic(node, errVal).Panic()
 		panic(UNREACHABLE)
+	case RadDatetime, RadDuration:
+		name := key.RequireStr(i, node).Plain()
+		var field RadValue
+		var exists bool
+		if dt, ok := coerced.(RadDatetime); ok {
+			field, exists = dt.Field(name)
+		} else {
+			field, exists = coerced.(RadDuration).Field(name)
+		}
+		if !exists {
+			errVal := newRadValue(i, node,
+				NewErrorStrf("No field '%s' on a %s", name, TypeAsString(v)).SetCode(rl.ErrKeyNotFound))
+			i.NewRadPanic(node, errVal).Panic()
+		}
+		return field
 	default:
 		i.emitErrorf(rl.ErrCannotIndex, node, "Indexing not supported for %s", TypeAsString(v))
 		panic(UNREACHABLE)
@@ -174,6 +200,20 @@ func (v RadValue) TryGetMap() (*RadMap, bool) {
 	return nil, false
 }
 
+func (v RadValue) TryGetIter() (*RadIter, bool) {
+	if it, ok := v.Val.(*RadIter); ok {
+		return it, true
+	}
+	return nil, false
+}
+
+func (v RadValue) TryGetSet() (*RadSet, bool) {
+	if s, ok := v.Val.(*RadSet); ok {
+		return s, true
+	}
+	return nil, false
+}
+
 func (v RadValue) RequireFn(i *Interpreter, node rl.Node) RadFn {
 	if fn, ok := v.TryGetFn(); ok {
 		return fn
@@ -277,6 +317,11 @@ func (v RadValue) Hash() string {
 		return "b:false"
 	case *RadError:
 		return "s:" + val.Hash()
+	case RadDatetime:
+		// The same instant in different zones is the same key, as with Equals.
+		return "t:" + strconv.FormatInt(val.Time.UnixNano(), 10)
+	case RadDuration:
+		return "d:" + strconv.FormatInt(int64(val.Dur), 10)
 	default:
 		panic(fmt.Sprintf("Cannot key on a %s", TypeAsString(v)))
 	}
@@ -301,6 +346,10 @@ func (v RadValue) GoMapKey() string {
 		return fmt.Sprintf("%v", val)
 	case *RadError:
 		return val.Hash()
+	case RadDatetime:
+		return val.ToString()
+	case RadDuration:
+		return val.ToString()
 	default:
 		panic(fmt.Sprintf("Cannot convert %s to Go map key", TypeAsString(v)))
 	}
@@ -342,12 +391,21 @@ func (left RadValue) Equals(right RadValue) bool {
 	case *RadMap:
 		coercedRight := right.Val.(*RadMap)
 		return coercedLeft.Equals(coercedRight)
+	case *RadSet:
+		return coercedLeft.Equals(right.Val.(*RadSet))
+	case *RadIter:
+		// only the same iterator, since comparing values would consume them
+		return coercedLeft == right.Val.(*RadIter)
 	case RadNull:
 		// we know they're both null, so true
 		return true
 	case *RadError:
 		coercedRight := right.Val.(*RadError)
 		return coercedLeft.Equals(coercedRight)
+	case RadDatetime:
+		return coercedLeft.Time.Equal(right.Val.(RadDatetime).Time)
+	case RadDuration:
+		return coercedLeft.Dur == right.Val.(RadDuration).Dur
 	default:
 		return false
 	}
@@ -403,6 +461,15 @@ func (v RadValue) TruthyFalsy() bool {
 		out = false
 	}).ForError(func(v RadValue, e *RadError) {
 		out = true
+	}).ForDatetime(func(v RadValue, d RadDatetime) {
+		out = true
+	}).ForDuration(func(v RadValue, d RadDuration) {
+		out = d.Dur != 0
+	}).ForSet(func(v RadValue, s *RadSet) {
+		out = s.Len() != 0
+	}).ForIter(func(v RadValue, it *RadIter) {
+		// can't tell if it's empty without consuming it
+		out = true
 	}).Visit(v)
 	return out
 }
@@ -454,6 +521,26 @@ func (v RadValue) Accept(visitor *RadTypeVisitor) {
 			visitor.visitError(v, coerced)
 			return
 		}
+	case RadDatetime:
+		if visitor.visitDt != nil {
+			visitor.visitDt(v, coerced)
+			return
+		}
+	case RadDuration:
+		if visitor.visitDur != nil {
+			visitor.visitDur(v, coerced)
+			return
+		}
+	case *RadSet:
+		if visitor.visitSet != nil {
+			visitor.visitSet(v, coerced)
+			return
+		}
+	case *RadIter:
+		if visitor.visitIter != nil {
+			visitor.visitIter(v, coerced)
+			return
+		}
 	}
 	if visitor.defaultVisit != nil {
 		visitor.defaultVisit(v)
@@ -496,6 +583,19 @@ func (v RadValue) ToGoValue() (out interface{}) {
 		}).
 		ForError(func(val RadValue, actual *RadError) {
 			out = actual.Msg().String()
+		}).
+		ForDatetime(func(val RadValue, actual RadDatetime) {
+			out = actual.Time
+		}).
+		ForDuration(func(val RadValue, actual RadDuration) {
+			out = actual.Dur
+		}).
+		ForSet(func(val RadValue, actual *RadSet) {
+			// Go has no set; a list of the elements serializes as expected.
+			out = actual.ToGoList()
+		}).
+		ForIter(func(val RadValue, actual *RadIter) {
+			out = rl.IterGoValue{}
 		}).Visit(v)
 	return
 }
@@ -534,6 +634,18 @@ func (v RadValue) ToCompatSubject() (out rl.TypingCompatVal) {
 		}).
 		ForError(func(RadValue, *RadError) {
 			out = rl.NewErrorSubject()
+		}).
+		ForDatetime(func(RadValue, RadDatetime) {
+			out = rl.NewDatetimeSubject()
+		}).
+		ForDuration(func(RadValue, RadDuration) {
+			out = rl.NewDurationSubject()
+		}).
+		ForSet(func(RadValue, *RadSet) {
+			out = rl.NewSetSubject()
+		}).
+		ForIter(func(RadValue, *RadIter) {
+			out = rl.NewIterSubject()
 		}).Visit(v)
 	return
 }
@@ -566,6 +678,10 @@ func newRadValue(i *Interpreter, node rl.Node, value interface{}) RadValue {
 		return RadValue{Val: coerced}
 	case *RadError:
 		return RadValue{Val: coerced}
+	case RadDatetime, RadDuration:
+		return RadValue{Val: coerced}
+	case *RadSet, *RadIter:
+		return RadValue{Val: coerced}
 	case map[string]interface{}:
 		radMap := NewRadMap()
 		for key, val := range coerced {
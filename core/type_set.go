@@ -0,0 +1,152 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+// RadSet is a collection of distinct values. Like RadMap, it keys on
+// RadValue.Hash, so membership is a lookup rather than a scan, and it keeps
+// insertion order so printing and iteration are stable.
+// Only values that can be map keys can be elements - see IsSetElem.
+type RadSet struct {
+	// hashes of the values
+	members map[string]struct{}
+	values  []RadValue
+}
+
+func NewRadSet() *RadSet {
+	return &RadSet{
+		members: make(map[string]struct{}),
+		values:  []RadValue{},
+	}
+}
+
+// IsSetElem reports whether val can be held in a set: strings, numbers,
+// bools, datetimes and durations. Collections are mutable, so their hash
+// wouldn't stay put, and null, errors and functions have no useful identity.
+func IsSetElem(val RadValue) bool {
+	switch val.Val.(type) {
+	case RadString, int64, float64, bool, RadDatetime, RadDuration:
+		return true
+	}
+	return false
+}
+
+// Add inserts val if it isn't already present. Callers check IsSetElem first.
+func (s *RadSet) Add(val RadValue) {
+	hash := val.Hash()
+	if _, exists := s.members[hash]; exists {
+		return
+	}
+	s.members[hash] = struct{}{}
+	s.values = append(s.values, val)
+}
+
+// AddChecked adds val, emitting an error if it can't be a set element.
+func (s *RadSet) AddChecked(i *Interpreter, node rl.Node, val RadValue) {
+	if !IsSetElem(val) {
+		i.emitErrorf(rl.ErrTypeMismatch, node, "Cannot put a %s in a set, only strings, numbers, bools, datetimes and durations",
+			TypeAsString(val))
+	}
+	s.Add(val)
+}
+
+func (s *RadSet) Contains(val RadValue) bool {
+	if !IsSetElem(val) {
+		return false
+	}
+	_, exists := s.members[val.Hash()]
+	return exists
+}
+
+func (s *RadSet) Values() []RadValue {
+	return s.values
+}
+
+func (s *RadSet) Len() int64 {
+	return int64(len(s.values))
+}
+
+func (s *RadSet) Union(other *RadSet) *RadSet {
+	out := NewRadSet()
+	for _, val := range s.values {
+		out.Add(val)
+	}
+	for _, val := range other.values {
+		out.Add(val)
+	}
+	return out
+}
+
+func (s *RadSet) Intersect(other *RadSet) *RadSet {
+	out := NewRadSet()
+	for _, val := range s.values {
+		if other.Contains(val) {
+			out.Add(val)
+		}
+	}
+	return out
+}
+
+func (s *RadSet) Difference(other *RadSet) *RadSet {
+	out := NewRadSet()
+	for _, val := range s.values {
+		if !other.Contains(val) {
+			out.Add(val)
+		}
+	}
+	return out
+}
+
+func (s *RadSet) IsSubsetOf(other *RadSet) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	for _, val := range s.values {
+		if !other.Contains(val) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equals ignores order: sets are equal when they hold the same values.
+func (s *RadSet) Equals(other *RadSet) bool {
+	return s.Len() == other.Len() && s.IsSubsetOf(other)
+}
+
+// ToList returns the elements, in insertion order, as a new list.
+func (s *RadSet) ToList() *RadList {
+	list := NewRadList()
+	list.Values = append(list.Values, s.values...)
+	return list
+}
+
+// ToString writes the set as `{ "a", "b" }`. An empty set is `set()`, since
+// `{ }` is how an empty map prints.
+func (s *RadSet) ToString() string {
+	if s.Len() == 0 {
+		return FUNC_SET + "()"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("{ ")
+	for i, val := range s.values {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(ToPrintable(val))
+	}
+	sb.WriteString(" }")
+	return sb.String()
+}
+
+func (s *RadSet) ToGoList() []interface{} {
+	out := make([]interface{}, len(s.values))
+	for idx, val := range s.values {
+		out[idx] = val.ToGoValue()
+	}
+	return out
+}
@@ -27,6 +27,10 @@ type RadTypeVisitor struct {
 	visitFn      func(RadValue, RadFn)
 	visitNull    func(RadValue, RadNull)
 	visitError   func(RadValue, *RadError)
+	visitDt      func(RadValue, RadDatetime)
+	visitDur     func(RadValue, RadDuration)
+	visitSet     func(RadValue, *RadSet)
+	visitIter    func(RadValue, *RadIter)
 	defaultVisit func(RadValue)
 }
 
@@ -93,6 +97,26 @@ func (v *RadTypeVisitor) ForError(handler func(RadValue, *RadError)) *RadTypeVis
 	return v
 }
 
+func (v *RadTypeVisitor) ForDatetime(handler func(RadValue, RadDatetime)) *RadTypeVisitor {
+	v.visitDt = handler
+	return v
+}
+
+func (v *RadTypeVisitor) ForDuration(handler func(RadValue, RadDuration)) *RadTypeVisitor {
+	v.visitDur = handler
+	return v
+}
+
+func (v *RadTypeVisitor) ForSet(handler func(RadValue, *RadSet)) *RadTypeVisitor {
+	v.visitSet = handler
+	return v
+}
+
+func (v *RadTypeVisitor) ForIter(handler func(RadValue, *RadIter)) *RadTypeVisitor {
+	v.visitIter = handler
+	return v
+}
+
 func (v *RadTypeVisitor) ForDefault(handler func(RadValue)) *RadTypeVisitor {
 	v.defaultVisit = handler
 	return v
@@ -3,6 +3,7 @@ package core
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"runtime/debug"
 	"strconv"
 	"strings"
@@ -61,6 +62,14 @@ func ToPrintableQuoteStr(val interface{}, quoteStrings bool) string {
 		return "null"
 	case *RadError:
 		return ToPrintableQuoteStr(coerced.Msg().String(), quoteStrings)
+	case RadDatetime:
+		return coerced.ToString()
+	case RadDuration:
+		return coerced.ToString()
+	case *RadSet:
+		return coerced.ToString()
+	case *RadIter:
+		return coerced.ToString()
 	case nil:
 		return "null"
 	default:
@@ -91,6 +100,14 @@ func TypeAsString(val interface{}) string {
 		return "null"
 	case *RadError:
 		return "error"
+	case RadDatetime:
+		return rl.T_DATETIME
+	case RadDuration:
+		return rl.T_DURATION
+	case *RadSet:
+		return rl.T_SET
+	case *RadIter:
+		return rl.T_ITERATOR
 	default:
 		RP.RadErrorExit(fmt.Sprintf("Bug! Unhandled type for TypeAsString: %T\n%s\n", val, debug.Stack()))
 		panic(UNREACHABLE)
@@ -116,6 +133,15 @@ func ConvertToNativeTypes(i *Interpreter, node rl.Node, val interface{}) RadValu
 	// strictly speaking, ints are unnecessary as Go unmarshalls them either as float64 or json.Number
 	case RadString, string, int64, float64, bool:
 		return newRadValue(i, node, coerced)
+	case int:
+		// YAML decodes ints that fit as int
+		return newRadValue(i, node, int64(coerced))
+	case uint64:
+		// ...and only larger ones as uint64
+		if coerced <= math.MaxInt64 {
+			return newRadValue(i, node, int64(coerced))
+		}
+		return newRadValue(i, node, float64(coerced))
 	case json.Number:
 		s := string(coerced)
 		if !strings.Contains(s, ".") {
@@ -143,6 +169,21 @@ func ConvertToNativeTypes(i *Interpreter, node rl.Node, val interface{}) RadValu
 			m.Set(newRadValue(i, node, key), ConvertToNativeTypes(i, node, coerced[key]))
 		}
 		return newRadValue(i, node, m)
+	case []map[string]interface{}:
+		// TOML arrays of tables
+		list := NewRadList()
+		for _, val := range coerced {
+			list.Append(ConvertToNativeTypes(i, node, val))
+		}
+		return newRadValue(i, node, list)
+	case map[interface{}]interface{}:
+		// YAML mappings with non-string keys, e.g. `1: one`. Rad keys them by
+		// their printed form, as JSON would.
+		byStr := make(map[string]interface{}, len(coerced))
+		for key, val := range coerced {
+			byStr[fmt.Sprint(key)] = val
+		}
+		return ConvertToNativeTypes(i, node, byStr)
 	case nil:
 		return newRadValue(i, node, nil)
 	default:
@@ -183,6 +224,18 @@ func RadToJsonType(arg RadValue) interface{} {
 		return nil
 	case *RadError:
 		return coerced.Msg().Plain()
+	case RadDatetime, RadDuration:
+		// JSON has neither; write them as they print
+		return ToPrintableQuoteStr(coerced, false)
+	case *RadSet:
+		slice := make([]interface{}, 0)
+		for _, elem := range coerced.Values() {
+			slice = append(slice, RadToJsonType(elem))
+		}
+		return slice
+	case *RadIter:
+		// serializing it would consume it; it's written as it prints
+		return coerced.ToString()
 	default:
 		RP.RadErrorExit(fmt.Sprintf("Bug! Unhandled type for RadToJsonType: %T\n%s\n", arg.Val, debug.Stack()))
 		panic(UNREACHABLE)
@@ -0,0 +1,28 @@
+package analysis
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/amterp/rad/radls/log"
+	"github.com/amterp/rad/rts/check"
+)
+
+// checkRulesFor resolves the rule levels for a document the way `rad check`
+// does: the [check] section of the rad config, overridden by the nearest
+// .radcheck.toml above the file. Both are read on every build, so editing
+// either takes effect on the next change without restarting the server.
+// Untitled documents only get the user's rules.
+func checkRulesFor(uri string) check.Rules {
+	configPath := filepath.Join(radHome(), "config.toml")
+	user, err := check.ReadUserRules(configPath)
+	if err != nil {
+		log.L.Warnw("Ignoring check rules; failed to read config", "path", configPath, "err", err)
+	}
+	loader := check.NewRuleLoader(user, configPath, func(path string, format string, args ...interface{}) {
+		log.L.Warnw("Ignoring check config entry", "path", path, "reason", strings.TrimSpace(fmt.Sprintf(format, args...)))
+	})
+	path, _ := uriToPath(uri)
+	return loader.RulesFor(path)
+}
@@ -18,8 +18,9 @@ var zeroSpan = rl.Span{}
 // identifier, the name didn't resolve, or the symbol is a builtin
 // (no source decl span to point at).
 //
-// Single-file today, single Location: Rad has no imports, so we
-// never need to return multiple Locations. The LSP spec allows
+// A call through an import's namespace (`helpers.greet()`) jumps into
+// the module file; everything else resolves within the document.
+// Either way there's one Location. The LSP spec allows
 // Location | Location[] | LocationLink[] | null; staying on Location
 // keeps the wire shape simple and trivially forward-compatible.
 //
@@ -34,6 +35,10 @@ func (s *State) Definition(snap *DocumentVersion, pos lsp.Pos) (*lsp.Location, e
 	}
 
 	bytePos := toBytePos(pos, snap)
+	if mf, _, _ := s.moduleFnAt(snap, bytePos); mf != nil {
+		return &lsp.Location{Uri: mf.uri, Range: mf.name}, nil
+	}
+
 	sym := symbolAtPos(snap, bytePos)
 	if sym == nil {
 		return nil, nil
@@ -1,6 +1,8 @@
 package analysis
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/amterp/rad/radls/lsp"
@@ -23,3 +25,33 @@ func TestDefinitionNoSnapshotReturnsNil(t *testing.T) {
 		t.Errorf("expected nil location for nil snapshot, got %+v", loc)
 	}
 }
+
+// The snapshot harness holds a single document, so following an import
+// into a module file on disk is covered here instead.
+func TestDefinitionFollowsImportIntoModule(t *testing.T) {
+	dir := t.TempDir()
+	module := "x = 1\n\nfn greet(name):\n    return \"hi {name}\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "helpers.rad"), []byte(module), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewState()
+	uri := pathToURI(filepath.Join(dir, "main.rad"))
+	s.AddDoc(uri, "h = import(\"./helpers\")\nprint(h.greet(\"bob\"))\n")
+	snap := s.Snapshot(uri)
+	defer snap.Release()
+
+	loc, err := s.Definition(snap, lsp.NewPos(1, 9))
+	if err != nil {
+		t.Fatalf("Definition: %v", err)
+	}
+	if loc == nil {
+		t.Fatal("expected a location in the module, got nil")
+	}
+	if want := pathToURI(filepath.Join(dir, "helpers.rad")); loc.Uri != want {
+		t.Errorf("uri = %q, expected %q", loc.Uri, want)
+	}
+	if want := lsp.NewRange(2, 3, 2, 8); loc.Range != want {
+		t.Errorf("range = %+v, expected %+v", loc.Range, want)
+	}
+}
@@ -1,6 +1,8 @@
 package analysis
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/amterp/rad/radls/lsp"
@@ -66,6 +68,48 @@ func TestRunCheckerEmpty(t *testing.T) {
 	}
 }
 
+// TestBuildVersionAppliesCheckRules verifies documents are checked with the
+// same rule config as `rad check`: the user's [check] section, overridden by
+// the nearest .radcheck.toml.
+func TestBuildVersionAppliesCheckRules(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("RAD_HOME", home)
+	userConfig := "[check.rules]\nRAD40017 = \"off\"\n"
+	if err := os.WriteFile(filepath.Join(home, "config.toml"), []byte(userConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	project := "[rules]\nRAD40017 = \"error\"\n"
+	if err := os.WriteFile(filepath.Join(dir, check.ProjectRulesFile), []byte(project), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewState()
+	text := `x = "{4}"`
+	outside := "file:///rules_test.rad"
+	inside := pathToURI(filepath.Join(dir, "scripts", "rules_test.rad"))
+	s.AddDoc(outside, text)
+	s.AddDoc(inside, text)
+
+	severity := func(uri string) (check.Severity, bool) {
+		snap := s.Snapshot(uri)
+		defer snap.Release()
+		for _, d := range snap.rawDiagnostics {
+			if d.Code != nil && *d.Code == rl.ErrConstantInterpolation {
+				return d.Severity, true
+			}
+		}
+		return 0, false
+	}
+	if _, ok := severity(outside); ok {
+		t.Errorf("expected the user config to turn RAD40017 off")
+	}
+	if got, ok := severity(inside); !ok || got != check.Error {
+		t.Errorf("expected .radcheck.toml to raise RAD40017 to an error, got %v (reported: %v)", got, ok)
+	}
+}
+
 func mkRange(sl, sc, el, ec int) check.Range {
 	return check.Range{
 		Start: check.Pos{Line: sl, Character: sc},
@@ -82,6 +126,7 @@ type stubChecker struct {
 func (s *stubChecker) UpdateSrc(string)                            {}
 func (s *stubChecker) Update(*rts.RadTree, string, *rl.SourceFile) {}
 func (s *stubChecker) SetStrict(bool)                              {}
+func (s *stubChecker) SetRules(check.Rules)                        {}
 func (s *stubChecker) Check() (check.Result, error)                { return s.result, nil }
 
 // Compile-time interface check. lsp import keeps the file honest if
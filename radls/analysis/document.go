@@ -204,6 +204,16 @@ func (d *Document) Update(produce func(prev *DocumentVersion) *DocumentVersion)
 	return next
 }
 
+// close drops the Document's reference to its current version. Readers
+// still holding a snapshot keep it alive until they Release.
+func (d *Document) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if v := d.snapshot.Swap(nil); v != nil {
+		v.Release()
+	}
+}
+
 // wireLastGood chains the last-good pointer so mid-edit versions
 // can fall back to a prior version's resolved/types indexes. Called
 // inside Update under the writer lock so the inherited acquire
@@ -250,6 +260,7 @@ func buildVersion(
 	lineIndex := NewLineIndex(text)
 
 	checker := check.NewCheckerWithTree(tree, parser, text, ast)
+	checker.SetRules(checkRulesFor(uri))
 	diags, rawDiags, resolved, typeInfo := runChecker(checker, lineIndex, encoding)
 
 	v := &DocumentVersion{
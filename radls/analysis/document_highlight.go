@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/amterp/rad/radls/lsp"
+
+	"github.com/amterp/rad/rts/check"
+	"github.com/amterp/rad/rts/rl"
+)
+
+// DocumentHighlights answers textDocument/documentHighlight: every
+// occurrence in this file of the symbol under the cursor, so the
+// editor can mark them as the cursor moves. Built from the same
+// resolved uses as References; the difference is that each site is
+// tagged Write (declarations and assignments) or Read (everything
+// else), which editors render in different colours.
+//
+// A call through an import (`h.greet()`) has no symbol in this file,
+// so it highlights the other calls to the same module function.
+//
+// Returns an empty slice (not nil) when there's nothing to highlight.
+func (s *State) DocumentHighlights(snap *DocumentVersion, pos lsp.Pos) ([]lsp.DocumentHighlight, error) {
+	if snap == nil || snap.ast == nil || snap.resolved == nil {
+		return []lsp.DocumentHighlight{}, nil
+	}
+
+	bytePos := toBytePos(pos, snap)
+	if ident := identifierAt(snap.ast, bytePos); ident != nil {
+		if ref := snap.resolved.ModuleRefs[ident]; ref != nil {
+			return moduleRefHighlights(snap, ref), nil
+		}
+	}
+	target := symbolAtPos(snap, bytePos)
+	if target == nil {
+		return []lsp.DocumentHighlight{}, nil
+	}
+
+	writes := writeSites(snap, target)
+	locs := collectReferences(snap, target, true)
+	out := make([]lsp.DocumentHighlight, 0, len(locs))
+	for _, loc := range locs {
+		kind := lsp.DocumentHighlightRead
+		if writes[loc.Range] {
+			kind = lsp.DocumentHighlightWrite
+		}
+		out = append(out, lsp.DocumentHighlight{Range: loc.Range, Kind: kind})
+	}
+	return out, nil
+}
+
+// writeSites collects the ranges where target is bound or assigned:
+// its declaration, plus every assignment to it - `x = 2` after the
+// first, `x += 1`, `x.field = v`. Ranges are in the negotiated
+// encoding, to match collectReferences.
+func writeSites(snap *DocumentVersion, target *check.Symbol) map[lsp.Range]bool {
+	writes := make(map[lsp.Range]bool)
+	if target.DeclSpan != zeroSpan {
+		writes[fromByteRange(spanToRange(target.DeclSpan), snap)] = true
+	}
+	rl.Walk(snap.ast, func(n rl.Node) {
+		assign, ok := n.(*rl.Assign)
+		if !ok {
+			return
+		}
+		for _, t := range assign.Targets {
+			if path, ok := t.(*rl.VarPath); ok {
+				t = path.Root
+			}
+			ident, ok := t.(*rl.Identifier)
+			if !ok || lookupSymbolForIdent(ident, snap.resolved) != target {
+				continue
+			}
+			writes[fromByteRange(spanToRange(ident.Span()), snap)] = true
+		}
+	})
+	return writes
+}
+
+// moduleRefHighlights marks every call in this file to the same
+// module function as ref, through the same import.
+func moduleRefHighlights(snap *DocumentVersion, ref *check.ModuleRef) []lsp.DocumentHighlight {
+	out := make([]lsp.DocumentHighlight, 0)
+	for ident, other := range snap.resolved.ModuleRefs {
+		if other.Module != ref.Module || other.Name != ref.Name {
+			continue
+		}
+		out = append(out, lsp.DocumentHighlight{
+			Range: fromByteRange(spanToRange(ident.Span()), snap),
+			Kind:  lsp.DocumentHighlightRead,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return posBefore(out[i].Range.Start, out[j].Range.Start) })
+	return out
+}
@@ -0,0 +1,145 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/amterp/rad/radls/lsp"
+
+	"github.com/amterp/rad/rts/rl"
+	ts "github.com/tree-sitter/go-tree-sitter"
+)
+
+// foldKinds are the CST node kinds that fold as a whole when they span
+// more than one line. if_stmt is absent on purpose: it folds per branch,
+// so collapsing the `if` doesn't also swallow its `else`.
+var foldKinds = map[string]bool{
+	rl.K_ARG_BLOCK:   true,
+	rl.K_CMD_BLOCK:   true,
+	rl.K_RAD_BLOCK:   true,
+	rl.K_FN_NAMED:    true,
+	rl.K_FN_LAMBDA:   true,
+	rl.K_FOR_LOOP:    true,
+	rl.K_WHILE_LOOP:  true,
+	rl.K_SWITCH_STMT: true,
+	rl.K_DEFER_BLOCK: true,
+	rl.K_CATCH_BLOCK: true,
+	rl.K_STRING:      true,
+	rl.K_LIST:        true,
+	rl.K_MAP:         true,
+	rl.K_CALL:        true,
+}
+
+// FoldingRanges answers textDocument/foldingRange. Blocks (args,
+// commands, rad blocks, functions, loops, each branch of an if or
+// switch), multi-line strings and literals fold as regions; the file
+// header and each run of whole-line comments fold as comments.
+//
+// Works off the tree-sitter tree rather than the AST: the CST survives
+// the syntax errors of a half-typed line, and folds shouldn't vanish
+// while the user is mid-edit. Ranges are line-only, so there's no
+// encoding to translate. Returns an empty slice (not nil) when there's
+// nothing to fold.
+func (s *State) FoldingRanges(snap *DocumentVersion) ([]lsp.FoldingRange, error) {
+	if snap == nil || snap.tree == nil {
+		return []lsp.FoldingRange{}, nil
+	}
+
+	// Keyed by start line: editors honour one fold per line, and the
+	// outermost is the one worth keeping (a function over its body's
+	// first statement, say).
+	byStart := make(map[int]lsp.FoldingRange)
+	add := func(start, end int, kind lsp.FoldingRangeKind) {
+		if end <= start {
+			return
+		}
+		if prev, ok := byStart[start]; ok && prev.EndLine >= end {
+			return
+		}
+		byStart[start] = lsp.FoldingRange{StartLine: start, EndLine: end, Kind: kind}
+	}
+
+	fold := func(n *ts.Node, kind lsp.FoldingRangeKind) {
+		start, end := foldLines(n)
+		add(start, end, kind)
+	}
+
+	var comments []*ts.Node
+	var visit func(n *ts.Node)
+	visit = func(n *ts.Node) {
+		switch kind := n.Kind(); {
+		case kind == rl.K_COMMENT:
+			node := *n
+			comments = append(comments, &node)
+		case kind == rl.K_FILE_HEADER:
+			fold(n, lsp.FoldingRangeComment)
+		case kind == rl.K_IF_STMT:
+			for _, alt := range rl.GetChildren(n, rl.F_ALT) {
+				fold(&alt, lsp.FoldingRangeRegion)
+			}
+		case kind == rl.K_SWITCH_STMT:
+			fold(n, lsp.FoldingRangeRegion)
+			for _, field := range []string{rl.F_CASE, rl.F_DEFAULT} {
+				for _, c := range rl.GetChildren(n, field) {
+					fold(&c, lsp.FoldingRangeRegion)
+				}
+			}
+		case foldKinds[kind]:
+			fold(n, lsp.FoldingRangeRegion)
+		}
+		for _, child := range n.Children(n.Walk()) {
+			visit(&child)
+		}
+	}
+	visit(snap.tree.Root())
+
+	for _, run := range commentRuns(snap, comments) {
+		add(run[0], run[1], lsp.FoldingRangeComment)
+	}
+
+	out := make([]lsp.FoldingRange, 0, len(byStart))
+	for _, f := range byStart {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartLine < out[j].StartLine })
+	return out, nil
+}
+
+// foldLines is the line range a node folds over. Indentation-delimited
+// blocks end at column 0 of the line after their last statement - the
+// newline belongs to the block - and folding that line too would hide
+// whatever comes next.
+func foldLines(n *ts.Node) (start, endLine int) {
+	start = int(n.StartPosition().Row)
+	end := n.EndPosition()
+	endLine = int(end.Row)
+	if end.Column == 0 && endLine > start {
+		endLine--
+	}
+	return start, endLine
+}
+
+// commentRuns groups whole-line comments on consecutive lines into
+// [start, end] line pairs. A comment trailing code doesn't join a run -
+// folding it would hide the code.
+func commentRuns(snap *DocumentVersion, comments []*ts.Node) [][2]int {
+	sort.Slice(comments, func(i, j int) bool { return comments[i].StartByte() < comments[j].StartByte() })
+
+	var runs [][2]int
+	for _, c := range comments {
+		row := int(c.StartPosition().Row)
+		before := snap.GetLine(row)
+		if col := int(c.StartPosition().Column); col <= len(before) {
+			before = before[:col]
+		}
+		if strings.TrimSpace(before) != "" {
+			continue
+		}
+		if n := len(runs); n > 0 && runs[n-1][1] == row-1 {
+			runs[n-1][1] = row
+			continue
+		}
+		runs = append(runs, [2]int{row, row})
+	}
+	return runs
+}
@@ -0,0 +1,120 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/amterp/rad/radls/log"
+	"github.com/amterp/rad/radls/lsp"
+
+	"github.com/amterp/rad/rts/radfmt"
+)
+
+// Format answers textDocument/formatting with the same canonical
+// output `rad fmt` writes. The client's FormattingOptions aren't
+// consulted: radfmt has one layout, and an editor that formatted
+// differently from the command line would just produce churn.
+//
+// A document radfmt declines (syntax errors, or a result that failed
+// its structural-equivalence guard) gets no edits rather than an
+// error - format-on-save shouldn't pop an error toast while the user
+// is mid-edit.
+func (s *State) Format(snap *DocumentVersion) ([]lsp.TextEdit, error) {
+	if snap == nil {
+		return nil, nil
+	}
+	out, _, ok := radfmt.Format(snap.text)
+	if !ok {
+		log.L.Infow("Document not formatted", "uri", snap.uri)
+		return []lsp.TextEdit{}, nil
+	}
+	return formattingEdits(snap, out), nil
+}
+
+// FormatRange answers textDocument/rangeFormatting. radfmt formats
+// whole top-level statements, so the edit can extend past the
+// selection to the edges of the statements it touches. A selection
+// ending at the start of a line doesn't include that line - that's
+// what selecting full lines in most editors sends.
+func (s *State) FormatRange(snap *DocumentVersion, r lsp.Range) ([]lsp.TextEdit, error) {
+	if snap == nil {
+		return nil, nil
+	}
+	endLine := r.End.Line
+	if r.End.Character == 0 && endLine > r.Start.Line {
+		endLine--
+	}
+	out, _, ok := radfmt.FormatRange(snap.text, r.Start.Line, endLine)
+	if !ok {
+		log.L.Infow("Range not formatted", "uri", snap.uri, "range", r)
+		return []lsp.TextEdit{}, nil
+	}
+	return formattingEdits(snap, out), nil
+}
+
+// FormatOnType answers textDocument/onTypeFormatting. On a newline
+// it formats the statement on the line just finished. It never edits
+// the line the cursor is now on: the client has usually put
+// indentation there for the user to type after, and formatting it
+// away would move the cursor. A statement that continues onto that
+// line - an `if` whose body is being written, say - is left alone
+// until it's done.
+func (s *State) FormatOnType(snap *DocumentVersion, pos lsp.Pos, ch string) ([]lsp.TextEdit, error) {
+	if snap == nil {
+		return nil, nil
+	}
+	if ch != "\n" || pos.Line < 1 {
+		return []lsp.TextEdit{}, nil
+	}
+	out, _, ok := radfmt.FormatRange(snap.text, pos.Line-1, pos.Line-1)
+	if !ok {
+		return []lsp.TextEdit{}, nil
+	}
+	edits := formattingEdits(snap, out)
+	for _, edit := range edits {
+		end := edit.Range.End
+		if end.Line > pos.Line || (end.Line == pos.Line && end.Character > 0) {
+			return []lsp.TextEdit{}, nil
+		}
+	}
+	return edits, nil
+}
+
+// formattingEdits turns formatted text into the edit that takes the
+// snapshot there: a single replacement of the lines that differ,
+// rather than the whole document, so clients keep the cursor,
+// folds, and undo history of everything the formatter didn't touch.
+// No change is an empty (not nil) list, which the wire needs as [].
+func formattingEdits(snap *DocumentVersion, formatted string) []lsp.TextEdit {
+	old := snap.text
+	if formatted == old {
+		return []lsp.TextEdit{}
+	}
+	oldLines := strings.SplitAfter(old, "\n")
+	newLines := strings.SplitAfter(formatted, "\n")
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	start := len(strings.Join(oldLines[:prefix], ""))
+	end := len(old) - len(strings.Join(oldLines[len(oldLines)-suffix:], ""))
+	newText := strings.Join(newLines[prefix:len(newLines)-suffix], "")
+
+	r := lsp.Range{Start: byteOffsetPos(snap, start), End: byteOffsetPos(snap, end)}
+	return []lsp.TextEdit{{Range: fromByteRange(r, snap), NewText: newText}}
+}
+
+// byteOffsetPos converts a byte offset into the snapshot's text into a
+// line plus utf-8 byte column.
+func byteOffsetPos(snap *DocumentVersion, offset int) lsp.Pos {
+	starts := snap.lineIndex.lineStarts
+	line := sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+	return lsp.Pos{Line: line, Character: offset - starts[line]}
+}
@@ -29,6 +29,16 @@ func (s *State) Hover(snap *DocumentVersion, pos lsp.Pos) (*lsp.Hover, error) {
 	}
 
 	bytePos := toBytePos(pos, snap)
+	if mf, ref, ident := s.moduleFnAt(snap, bytePos); mf != nil {
+		r := fromByteRange(spanToRange(ident.Span()), snap)
+		return &lsp.Hover{
+			Contents: lsp.MarkupContent{
+				Kind:  lsp.MarkupMarkdown,
+				Value: formatModuleFnHover(mf, ref),
+			},
+			Range: &r,
+		}, nil
+	}
 	if ident := identifierAt(snap.ast, bytePos); ident != nil {
 		contents := formatIdentHover(ident, snap.resolved, snap.types)
 		if contents == "" {
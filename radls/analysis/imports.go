@@ -0,0 +1,145 @@
+package analysis
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/amterp/rad/radls/lsp"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/check"
+	"github.com/amterp/rad/rts/rl"
+)
+
+// moduleFn is a function of an imported module, found by following a call
+// like `helpers.greet()` into the file the import names.
+type moduleFn struct {
+	uri  string
+	path string
+	fn   *rl.FnDef
+	// name is fn's name span, already in the negotiated encoding against the
+	// module's own text.
+	name lsp.Range
+}
+
+// moduleFnAt follows a call through an import's namespace into the module,
+// when the cursor is on the called function's name. Also returns the ref and
+// the identifier under the cursor. Returns nils when it isn't,
+// when the import's path is computed, or when the module or function can't be
+// found - the same nothing-to-say cases as an unresolved local.
+//
+// The module is read fresh per request: an open editor buffer if there is
+// one, else the file on disk. Modules are small and these requests are rare
+// next to keystrokes, so there's no cache to keep coherent.
+func (s *State) moduleFnAt(snap *DocumentVersion, bytePos lsp.Pos) (*moduleFn, *check.ModuleRef, *rl.Identifier) {
+	if snap.resolved == nil {
+		return nil, nil, nil
+	}
+	ident := identifierAt(snap.ast, bytePos)
+	if ident == nil {
+		return nil, nil, nil
+	}
+	ref := snap.resolved.ModuleRefs[ident]
+	if ref == nil || ref.Import.Path == "" {
+		return nil, nil, nil
+	}
+	mf := s.loadModuleFn(snap.uri, ref)
+	if mf == nil {
+		return nil, nil, nil
+	}
+	return mf, ref, ident
+}
+
+// loadModuleFn finds ref's function in the module its import names,
+// resolved against the importing document's directory.
+func (s *State) loadModuleFn(docURI string, ref *check.ModuleRef) *moduleFn {
+	docPath, ok := uriToPath(docURI)
+	if !ok {
+		return nil
+	}
+	path := rts.ResolveImport(ref.Import.Path, filepath.Dir(docPath), radHome())
+	uri := pathToURI(path)
+
+	var text string
+	if open := s.Snapshot(uri); open != nil {
+		text = open.text
+		open.Release()
+	} else {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		text = string(data)
+	}
+
+	s.parserMu.Lock()
+	tree := s.parser.Parse(text)
+	s.parserMu.Unlock()
+	defer tree.Close()
+	ast := safeConvertCST(tree, text, uri)
+	if ast == nil {
+		return nil
+	}
+
+	for _, stmt := range ast.Stmts {
+		fn, ok := stmt.(*rl.FnDef)
+		if !ok || fn.Name != ref.Name {
+			continue
+		}
+		idx := NewLineIndex(text)
+		r := spanToRange(fn.NameSpan)
+		return &moduleFn{
+			uri:  uri,
+			path: path,
+			fn:   fn,
+			name: lsp.Range{
+				Start: lsp.Pos{
+					Line:      r.Start.Line,
+					Character: idx.ByteColumnTo(r.Start.Line, r.Start.Character, s.encoding),
+				},
+				End: lsp.Pos{
+					Line:      r.End.Line,
+					Character: idx.ByteColumnTo(r.End.Line, r.End.Character, s.encoding),
+				},
+			},
+		}
+	}
+	return nil
+}
+
+// formatModuleFnHover renders a module function like a local one, plus the
+// file it comes from - the namespace in front of the call says which import,
+// not where that lands.
+func formatModuleFnHover(mf *moduleFn, ref *check.ModuleRef) string {
+	typeStr := "?"
+	if mf.fn.Typing != nil {
+		typeStr = rl.DisplayName(mf.fn.Typing)
+	}
+	return "```rad\n(fn) " + ref.Module.Name + "." + mf.fn.Name + ": " + typeStr + "\n```\n\n---\n\nFrom `" +
+		filepath.Base(mf.path) + "`"
+}
+
+// radHome mirrors how rad itself locates its home, which library imports
+// resolve under.
+func radHome() string {
+	if home := os.Getenv("RAD_HOME"); home != "" {
+		return home
+	}
+	if dir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(dir, ".rad")
+	}
+	return ""
+}
+
+func uriToPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
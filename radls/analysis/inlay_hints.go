@@ -0,0 +1,200 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/amterp/rad/radls/lsp"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/check"
+	"github.com/amterp/rad/rts/rl"
+)
+
+// maxTypeHintLen caps a type hint's label. Inferred struct types -
+// get_path's result, say - run to a hundred characters, which is a
+// line of noise; hover has the full type for anyone who wants it.
+const maxTypeHintLen = 40
+
+// InlayHints answers textDocument/inlayHint for the requested range.
+// Two kinds of hint:
+//
+//   - the inferred type after a variable's first, unannotated
+//     assignment (`x = parse_int(s)` reads `x: error|int = ...`),
+//     from the type checker's TypeInfo;
+//   - the parameter name before each positional argument of a call
+//     (`clamp(min: 0, max: 10, ...)`), for builtins and user fns.
+//
+// Both are held back where they'd only repeat what's on screen: no
+// type after a literal, no name for an argument that's a variable of
+// that name, and no names for a function with just one positional
+// parameter, whose meaning the function's name already gives away.
+//
+// Unlike signature help this needs the current AST: hints placed
+// from a stale one would land on the wrong characters. A document
+// that doesn't parse gets none until it does.
+func (s *State) InlayHints(snap *DocumentVersion, r lsp.Range) ([]lsp.InlayHint, error) {
+	if snap == nil || snap.ast == nil || snap.resolved == nil {
+		return []lsp.InlayHint{}, nil
+	}
+	byteRange := toByteRange(r, snap)
+
+	// A UFCS call's receiver is its first argument, but isn't in
+	// Call.Args - so the call's own args start at the second param.
+	ufcsCalls := make(map[*rl.Call]bool)
+	rl.Walk(snap.ast, func(n rl.Node) {
+		if path, ok := n.(*rl.VarPath); ok {
+			for _, seg := range path.Segments {
+				if call, ok := seg.Index.(*rl.Call); ok && seg.IsUFCS {
+					ufcsCalls[call] = true
+				}
+			}
+		}
+	})
+
+	var hints []lsp.InlayHint
+	rl.Walk(snap.ast, func(n rl.Node) {
+		switch n := n.(type) {
+		case *rl.Assign:
+			hints = append(hints, typeHints(snap, n)...)
+		case *rl.Call:
+			hints = append(hints, paramHints(snap, n, ufcsCalls[n])...)
+		}
+	})
+
+	result := make([]lsp.InlayHint, 0, len(hints))
+	for _, hint := range hints {
+		if posBefore(hint.Position, byteRange.Start) || posBefore(byteRange.End, hint.Position) {
+			continue
+		}
+		hint.Position = fromByteRange(lsp.Range{Start: hint.Position, End: hint.Position}, snap).Start
+		result = append(result, hint)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return posBefore(result[i].Position, result[j].Position)
+	})
+	return result, nil
+}
+
+// typeHints are the inferred-type hints for an assignment's targets,
+// at the assignment that declares each one. Later assignments to the
+// same name don't repeat it.
+func typeHints(snap *DocumentVersion, a *rl.Assign) []lsp.InlayHint {
+	if a.DeclaredType != nil || a.UpdateEnclosing || len(a.Targets) != len(a.Values) || snap.types == nil {
+		return nil
+	}
+	var hints []lsp.InlayHint
+	for i, target := range a.Targets {
+		ident, ok := target.(*rl.Identifier)
+		if !ok {
+			continue
+		}
+		sym := lookupSymbolForIdent(ident, snap.resolved)
+		if sym == nil || sym.DefNode != ident {
+			continue
+		}
+		value := a.Values[i]
+		if typeIsEvident(value) {
+			continue
+		}
+		t, ok := snap.types.ExprTypes[value]
+		if !ok || t == nil {
+			continue
+		}
+		label := rl.DisplayName(t)
+		if label == rl.T_ANY {
+			continue
+		}
+		if len(label) > maxTypeHintLen {
+			label = label[:maxTypeHintLen-3] + "..."
+		}
+		hints = append(hints, lsp.InlayHint{
+			Position: spanToRange(ident.Span()).End,
+			Label:    ": " + label,
+			Kind:     lsp.InlayHintKindType,
+		})
+	}
+	return hints
+}
+
+// typeIsEvident reports whether a value's type is plain from the
+// value itself: a scalar literal, or a lambda, whose `fn` says it.
+func typeIsEvident(value rl.Node) bool {
+	switch value.(type) {
+	case *rl.LitInt, *rl.LitFloat, *rl.LitBool, *rl.LitString, *rl.LitNull, *rl.Lambda:
+		return true
+	}
+	return false
+}
+
+// paramHints are the parameter-name hints for a call's positional
+// arguments. Arguments to a variadic parameter get its name once, on
+// the first.
+func paramHints(snap *DocumentVersion, call *rl.Call, ufcs bool) []lsp.InlayHint {
+	ident, ok := call.Func.(*rl.Identifier)
+	if !ok {
+		return nil
+	}
+	typing := callTyping(snap, ident)
+	if typing == nil {
+		return nil
+	}
+	positionalParams := 0
+	for _, p := range typing.Params {
+		if !p.NamedOnly {
+			positionalParams++
+		}
+	}
+	if positionalParams < 2 {
+		return nil
+	}
+
+	offset := 0
+	if ufcs {
+		offset = 1
+	}
+	var hints []lsp.InlayHint
+	prev := -1
+	for i, arg := range call.Args {
+		idx := activeParam(typing, i+offset, "")
+		if idx >= len(typing.Params) {
+			break
+		}
+		if idx == prev {
+			continue
+		}
+		prev = idx
+		name := strings.TrimPrefix(typing.Params[idx].Name, "_")
+		if argIdent, ok := arg.(*rl.Identifier); ok && strings.TrimPrefix(argIdent.Name, "_") == name {
+			continue
+		}
+		hints = append(hints, lsp.InlayHint{
+			Position:     spanToRange(arg.Span()).Start,
+			Label:        name + ":",
+			Kind:         lsp.InlayHintKindParameter,
+			PaddingRight: true,
+		})
+	}
+	return hints
+}
+
+// callTyping is the signature of the function a call names: what the
+// name resolves to, or the builtin of that name. A UFCS call's name
+// isn't a resolved use, so it goes straight to the builtins.
+func callTyping(snap *DocumentVersion, ident *rl.Identifier) *rl.TypingFnT {
+	if sym := lookupSymbolForIdent(ident, snap.resolved); sym != nil && sym.Kind != check.SymBuiltin {
+		return symbolFnTyping(sym, snap.types)
+	}
+	if sig, ok := rts.FnSignaturesByName[ident.Name]; ok && !sig.IsInternal {
+		return sig.Typing
+	}
+	return nil
+}
+
+// posBefore reports whether a comes strictly before b.
+func posBefore(a, b lsp.Pos) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Character < b.Character
+}
@@ -14,6 +14,12 @@ import (
 // the document. The LSP context flag IncludeDeclaration toggles
 // whether the declaration site itself is included.
 //
+// A top-level function can also be called from other scripts, through
+// an import (`helpers.greet()`). Those calls come from the workspace
+// index and follow the current file's own references. With the cursor
+// on such a call, the references are those of the function it names,
+// in its own file and in every file calling it.
+//
 // We compute on-demand from resolved.Uses rather than maintaining a
 // pre-built reverse index. References is a click-driven feature
 // (not background-streaming like diagnostics), so the cost of
@@ -30,12 +36,49 @@ func (s *State) References(snap *DocumentVersion, pos lsp.Pos, includeDecl bool)
 	}
 
 	bytePos := toBytePos(pos, snap)
+	if mf, _, _ := s.moduleFnAt(snap, bytePos); mf != nil {
+		return s.moduleFnReferences(mf, includeDecl), nil
+	}
 	target := symbolAtPos(snap, bytePos)
 	if target == nil {
 		return []lsp.Location{}, nil
 	}
 
-	return collectReferences(snap, target, includeDecl), nil
+	out := collectReferences(snap, target, includeDecl)
+	if target.Kind == check.SymHoistedFn {
+		out = append(out, s.callsInto(snap.uri, target.Name)...)
+	}
+	return out, nil
+}
+
+// moduleFnReferences collects the references to a function of another
+// file: its uses in that file, then the calls into it from the rest of
+// the workspace.
+func (s *State) moduleFnReferences(mf *moduleFn, includeDecl bool) []lsp.Location {
+	out := make([]lsp.Location, 0)
+	found := false
+	s.withVersion(mf.uri, mf.path, func(v *DocumentVersion) {
+		if sym := hoistedFnSymbol(v, mf.fn.Name); sym != nil {
+			out = collectReferences(v, sym, includeDecl)
+			found = true
+		}
+	})
+	if !found && includeDecl {
+		out = append(out, lsp.Location{Uri: mf.uri, Range: mf.name})
+	}
+	return append(out, s.callsInto(mf.uri, mf.fn.Name)...)
+}
+
+// hoistedFnSymbol finds the top-level function name declares in v, or nil.
+func hoistedFnSymbol(v *DocumentVersion, name string) *check.Symbol {
+	if v.resolved == nil || v.resolved.File == nil {
+		return nil
+	}
+	sym := v.resolved.File.Symbols[name]
+	if sym == nil || sym.Kind != check.SymHoistedFn {
+		return nil
+	}
+	return sym
 }
 
 // collectReferences walks the resolved indexes for every use of
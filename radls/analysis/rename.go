@@ -65,8 +65,10 @@ func errRenameCollision(name, where string) error {
 
 // Rename answers textDocument/rename: produce a WorkspaceEdit
 // covering every site that needs to change so the symbol under
-// the cursor becomes `newName`. Single-file scope - Rad has no
-// imports today, so cross-file renames don't apply.
+// the cursor becomes `newName`. A top-level function is renamed
+// across the workspace too: every call made to it through an import
+// (`helpers.greet()`) is edited along with the file declaring it, and
+// the rename can start from either end.
 //
 // Returns:
 //   - ErrInvalidRenameTarget when the cursor isn't on a
@@ -84,10 +86,26 @@ func (s *State) Rename(snap *DocumentVersion, pos lsp.Pos, newName string) (*lsp
 	}
 
 	bytePos := toBytePos(pos, snap)
+	if mf, _, _ := s.moduleFnAt(snap, bytePos); mf != nil {
+		var edit *lsp.WorkspaceEdit
+		err := ErrInvalidRenameTarget
+		s.withVersion(mf.uri, mf.path, func(v *DocumentVersion) {
+			if sym := hoistedFnSymbol(v, mf.fn.Name); sym != nil {
+				edit, err = s.renameSymbol(v, sym, newName)
+			}
+		})
+		return edit, err
+	}
 	target := symbolAtPos(snap, bytePos)
 	if target == nil {
 		return nil, ErrInvalidRenameTarget
 	}
+	return s.renameSymbol(snap, target, newName)
+}
+
+// renameSymbol builds the edit renaming target, which is declared in
+// snap, to newName.
+func (s *State) renameSymbol(snap *DocumentVersion, target *check.Symbol, newName string) (*lsp.WorkspaceEdit, error) {
 	// Builtins have no source decl span; renaming `print` to
 	// `say` would just silently produce an undefined-identifier
 	// error at runtime. Reject before the edit.
@@ -144,6 +162,13 @@ func (s *State) Rename(snap *DocumentVersion, pos lsp.Pos, newName string) (*lsp
 	for _, span := range spans {
 		edit.AddEdit(snap.uri, fromByteRange(spanToRange(span), snap), newName)
 	}
+	// Callers elsewhere reach the function through their import's
+	// namespace, so the new name can't collide with anything of theirs.
+	if target.Kind == check.SymHoistedFn {
+		for _, loc := range s.callsInto(snap.uri, target.Name) {
+			edit.AddEdit(loc.Uri, loc.Range, newName)
+		}
+	}
 	return &edit, nil
 }
 
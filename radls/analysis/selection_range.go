@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/amterp/rad/radls/lsp"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+// SelectionRanges answers textDocument/selectionRange, which drives
+// the editor's expand-selection. Each position gets the chain of AST
+// nodes enclosing it, innermost first - identifier, expression,
+// statement, block, function - ending with the whole document.
+//
+// Returns an empty slice (not nil) for a missing snapshot. When the
+// source didn't convert there's no tree to climb, so each position
+// gets just the whole document: a chain per position is what the
+// wire promises.
+func (s *State) SelectionRanges(snap *DocumentVersion, positions []lsp.Pos) ([]lsp.SelectionRange, error) {
+	if snap == nil {
+		return []lsp.SelectionRange{}, nil
+	}
+	out := make([]lsp.SelectionRange, 0, len(positions))
+	for _, pos := range positions {
+		out = append(out, selectionChain(snap, pos))
+	}
+	return out, nil
+}
+
+// selectionChain builds one position's chain. Nodes whose span merely
+// touches the cursor - the identifier ending where the next token
+// starts - aren't ancestors of the innermost node, so each step must
+// contain the one before it or it's dropped.
+func selectionChain(snap *DocumentVersion, pos lsp.Pos) lsp.SelectionRange {
+	bytePos := toBytePos(pos, snap)
+
+	var spans []rl.Span
+	if snap.ast != nil {
+		rl.Walk(snap.ast, func(n rl.Node) {
+			if span := n.Span(); spanContains(span, bytePos) {
+				spans = append(spans, span)
+			}
+			// A function's name is a span, not an identifier node,
+			// but selecting it is still the first step.
+			if fn, ok := n.(*rl.FnDef); ok && fn.Name != "" && spanContains(fn.NameSpan, bytePos) {
+				spans = append(spans, fn.NameSpan)
+			}
+		})
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spanSize(spans[i]) < spanSize(spans[j]) })
+
+	ranges := make([]lsp.Range, 0, len(spans)+1)
+	for _, span := range spans {
+		r := spanToRange(span)
+		if n := len(ranges); n > 0 && (r == ranges[n-1] || !rangeContains(r, ranges[n-1])) {
+			continue
+		}
+		ranges = append(ranges, r)
+	}
+	if doc := documentRange(snap); len(ranges) == 0 || ranges[len(ranges)-1] != doc {
+		ranges = append(ranges, doc)
+	}
+
+	// Link outermost-in, so each step's Parent is already built.
+	var chain *lsp.SelectionRange
+	for i := len(ranges) - 1; i >= 0; i-- {
+		chain = &lsp.SelectionRange{
+			Range:  fromByteRange(ranges[i], snap),
+			Parent: chain,
+		}
+	}
+	return *chain
+}
+
+// rangeContains reports whether outer covers inner, both in bytes.
+func rangeContains(outer, inner lsp.Range) bool {
+	return !posBefore(inner.Start, outer.Start) && !posBefore(outer.End, inner.End)
+}
+
+// documentRange spans the whole text, in bytes.
+func documentRange(snap *DocumentVersion) lsp.Range {
+	last := snap.lineIndex.LineCount() - 1
+	if last < 0 {
+		return lsp.Range{}
+	}
+	return lsp.Range{End: lsp.Pos{Line: last, Character: len(snap.GetLine(last))}}
+}
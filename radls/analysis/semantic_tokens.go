@@ -6,7 +6,6 @@ import (
 	"github.com/amterp/rad/radls/lsp"
 
 	"github.com/amterp/rad/rts/check"
-	"github.com/amterp/rad/rts/rl"
 	ts "github.com/tree-sitter/go-tree-sitter"
 )
 
@@ -83,186 +82,46 @@ func (s *State) SemanticTokens(snap *DocumentVersion) (*lsp.SemanticTokens, erro
 	return out, nil
 }
 
-// collectSemanticTokens walks the AST for identifiers and emits
-// a token for each. Three sources:
-//
-//  1. Identifier nodes that resolve through resolved.Uses. This
-//     catches call sites, var reads, and the dual-registered
-//     decl identifier on `x = 1`.
-//  2. FnDef name positions. The binder declares hoisted fns at
-//     the AST node (not at an Identifier), so the name token
-//     at `fn greet():` has no Uses entry; without this branch
-//     it would not be coloured at the decl site even though
-//     every call to `greet()` is.
-//  3. ArgDecl name positions inside the `args:` / cmd `args:`
-//     blocks. Same shape as FnDef: the args declaration sits in
-//     the AST as an ArgDecl node rather than an Identifier, so
-//     the decl-site name needs its own emit to match how every
-//     use of the arg is tagged via path 1.
-//
-// Fn param-name positions at the decl site are still uncoloured.
-// The AST's TypingFnParam carries no per-name span; emitting
-// would need a converter+AST extension. Param references inside
-// the body do get tokens via path 1.
-//
-// Unresolved identifiers are skipped - the editor's tree-sitter
-// highlighting handles them well enough as plain text, and
-// emitting "variable" here would mis-color typos as if they
-// were real bindings.
+// collectSemanticTokens takes the shared classification in
+// check.SemanticTokens - the same one the REPL colours with - and
+// maps it onto this server's legend.
 func collectSemanticTokens(snap *DocumentVersion) []rawToken {
-	tokens := make([]rawToken, 0)
-	rl.Walk(snap.ast, func(n rl.Node) {
-		switch nn := n.(type) {
-		case *rl.Identifier:
-			sym, ok := snap.resolved.Uses[nn]
-			if !ok || sym == nil {
-				return
-			}
-			ttype, has := tokenTypeForSymbol(sym)
-			if !has {
-				return
-			}
-			tokens = append(tokens, tokenFromSpan(nn.Span(), ttype))
-		case *rl.FnDef:
-			// Skip anonymous or zero-name forms; NameSpan would be
-			// the whole node and emitting that would over-paint.
-			if nn.Name == "" {
-				return
-			}
-			tokens = append(tokens, tokenFromSpan(nn.NameSpan, TokenTypeFunction))
-		case *rl.ArgDecl:
-			if nn.Name == "" || nn.NameSpan.EndByte == 0 {
-				return
-			}
-			tokens = append(tokens, tokenFromSpan(nn.NameSpan, TokenTypeParameter))
-		}
-	})
+	var root *ts.Node
 	if snap.tree != nil {
-		collectTypeTokens(snap.tree.Root(), &tokens)
+		root = snap.tree.Root()
 	}
-	return tokens
-}
+	classified := check.SemanticTokens(snap.ast, snap.resolved, root)
 
-// typeKeywordLengths maps a CST node kind to the byte length of the
-// leading keyword that should carry the `type` semantic token. For
-// nodes whose entire span IS the keyword (string_type = "str") the
-// length is the whole node. For composite forms (`int[]`) we only
-// want the leading word, not the brackets.
-var typeKeywordLengths = map[string]int{
-	"string_type":      -1, // whole span ("str")
-	"int_type":         -1, // ("int")
-	"float_type":       -1, // ("float")
-	"bool_type":        -1, // ("bool")
-	"error_type":       -1, // ("error")
-	"any_type":         -1, // ("any")
-	"void_type":        -1, // ("void")
-	"string_list_type": 3,  // "str" prefix of "str[]"
-	"int_list_type":    3,  // "int" prefix of "int[]"
-	"float_list_type":  5,  // "float" prefix
-	"bool_list_type":   4,  // "bool" prefix
-}
-
-// collectTypeTokens walks the CST and emits a `type` semantic token
-// for every type-annotation keyword. The CST is the right level for
-// this: the AST's TypingT shapes carry no source positions, but the
-// CST has byte-exact spans on each `string_type` / `int_type` /
-// etc. node. We deliberately limit emission to the keyword bytes
-// (not the whole composite) so the surrounding punctuation - `[]`,
-// `?`, `|`, `->` - stays uncoloured.
-//
-// For the bare `list` and `fn` keywords (inside the parameterised
-// list_type / fn_type rules), we recognise the first child token's
-// literal text and emit on that. Tuple / enum forms inside
-// list_type ("[T1, T2]", "[\"a\", \"b\"]") have no keyword and
-// we skip those.
-func collectTypeTokens(root *ts.Node, out *[]rawToken) {
-	if root == nil {
-		return
-	}
-	walkCSTNodes(root, func(n *ts.Node) {
-		kind := n.Kind()
-		if length, ok := typeKeywordLengths[kind]; ok {
-			emitTypeToken(n, length, out)
-			return
-		}
-		switch kind {
-		case "list_type":
-			// list_type covers `list` (the open-list keyword) and
-			// the bracketed tuple / enum forms. Only the bare-
-			// `list` shape has a keyword to paint - detect via
-			// the literal first 4 bytes.
-			if n.EndByte()-n.StartByte() >= 4 {
-				emitTypeToken(n, 4, out)
-			}
-		case "fn_type":
-			// fn_type starts with the literal `fn` token. Emit
-			// just the first 2 bytes.
-			if n.EndByte()-n.StartByte() >= 2 {
-				emitTypeToken(n, 2, out)
-			}
+	tokens := make([]rawToken, 0, len(classified))
+	for _, t := range classified {
+		ttype, has := tokenTypeForClass(t.Class)
+		if !has {
+			continue
 		}
-	})
-}
-
-func emitTypeToken(n *ts.Node, length int, out *[]rawToken) {
-	start := n.StartPosition()
-	span := int(n.EndByte() - n.StartByte())
-	if length < 0 || length > span {
-		length = span
-	}
-	if length == 0 {
-		return
-	}
-	*out = append(*out, rawToken{
-		line:   int(start.Row),
-		col:    int(start.Column),
-		length: length,
-		ttype:  TokenTypeType,
-	})
-}
-
-func walkCSTNodes(n *ts.Node, visit func(*ts.Node)) {
-	if n == nil {
-		return
-	}
-	visit(n)
-	count := n.ChildCount()
-	for i := uint(0); i < count; i++ {
-		child := n.Child(i)
-		walkCSTNodes(child, visit)
-	}
-}
-
-// tokenFromSpan packages a span into the rawToken shape. Length
-// is byte-distance; the encoder translates to the negotiated
-// character encoding later.
-func tokenFromSpan(s rl.Span, ttype SemanticTokenType) rawToken {
-	return rawToken{
-		line:   s.StartRow,
-		col:    s.StartCol,
-		length: s.EndByte - s.StartByte,
-		ttype:  ttype,
+		tokens = append(tokens, rawToken{
+			line:   t.StartRow,
+			col:    t.StartCol,
+			length: t.EndByte - t.StartByte,
+			ttype:  ttype,
+		})
 	}
+	return tokens
 }
 
-// tokenTypeForSymbol maps a SymbolKind to its semantic-token
-// type, or false when the symbol shouldn't get a token of its
-// own. We collapse builtins and hoisted user functions into the
-// same "function" bucket - the editor doesn't render them
-// distinctly today, and adding "builtin" as a separate type
-// would lock us in before we have UX feedback.
-func tokenTypeForSymbol(sym *check.Symbol) (SemanticTokenType, bool) {
-	switch sym.Kind {
-	case check.SymBuiltin, check.SymHoistedFn:
+// tokenTypeForClass maps a token class onto the legend. Keywords,
+// literals and comments have no entry: the editor's tree-sitter
+// highlighting already colours them, and semantic tokens override
+// it, so emitting them would only risk disagreeing with the theme.
+func tokenTypeForClass(class check.TokenClass) (SemanticTokenType, bool) {
+	switch class {
+	case check.TokenFunction:
 		return TokenTypeFunction, true
-	case check.SymParam, check.SymArg, check.SymCmdArg:
-		// Args / cmd-args are declared parameters of the script
-		// (the script's caller fills them in via CLI flags), so
-		// they belong with fn params under the `parameter` token
-		// type rather than the generic `variable`.
+	case check.TokenParameter:
 		return TokenTypeParameter, true
-	case check.SymLocal, check.SymLoopVar, check.SymWith:
+	case check.TokenVariable:
 		return TokenTypeVariable, true
+	case check.TokenType:
+		return TokenTypeType, true
 	}
 	return 0, false
 }
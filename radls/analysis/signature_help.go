@@ -0,0 +1,378 @@
+package analysis
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/amterp/rad/radls/lsp"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/check"
+	"github.com/amterp/rad/rts/rl"
+)
+
+// namedArgPrefix matches an argument the user has started writing as
+// `name=...`, so the named parameter is the active one rather than
+// whichever the argument's position would pick.
+var namedArgPrefix = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*=([^=]|$)`)
+
+// callKeywords precede a `(` without being a call: `if (a or b):`.
+var callKeywords = map[string]bool{
+	"if": true, "while": true, "for": true, "in": true, "switch": true,
+	"case": true, "return": true, "yield": true, "and": true, "or": true,
+	"not": true,
+}
+
+// SignatureHelp answers textDocument/signatureHelp: the signature of
+// the call the cursor is inside, with the parameter being written
+// highlighted. Returns nil (no popup) when the cursor isn't inside
+// the parens of a call to something we know the signature of.
+//
+// The call is found by scanning the text, not the AST. Signature
+// help is wanted precisely while a call is half-written -
+// `greet(name, ` - which is when the parse is at its worst. The
+// callee is then looked up in the resolved view (the last good one
+// if the current text doesn't resolve), falling back to builtins.
+func (s *State) SignatureHelp(snap *DocumentVersion, pos lsp.Pos) (*lsp.SignatureHelp, error) {
+	if snap == nil {
+		return nil, nil
+	}
+	bytePos := toBytePos(pos, snap)
+	offset, ok := byteOffsetFor(snap, bytePos)
+	if !ok {
+		return nil, nil
+	}
+	call, ok := openCallAt(snap.text, offset)
+	if !ok {
+		return nil, nil
+	}
+	callee := lookupCallee(snap, call.name, bytePos)
+	if callee == nil {
+		return nil, nil
+	}
+
+	info := lsp.SignatureInformation{
+		Label:      callee.label,
+		Parameters: make([]lsp.ParameterInformation, 0),
+	}
+	if callee.doc != nil && callee.doc.Description != "" {
+		info.Documentation = &lsp.MarkupContent{Kind: lsp.MarkupMarkdown, Value: callee.doc.Description}
+	}
+	ranges := paramLabelRanges(callee.label)
+	if len(ranges) == len(callee.typing.Params) {
+		labelIdx := NewLineIndex(callee.label)
+		for i, r := range ranges {
+			param := lsp.ParameterInformation{Label: [2]uint{
+				uint(labelIdx.ByteColumnTo(0, r[0], snap.encoding)),
+				uint(labelIdx.ByteColumnTo(0, r[1], snap.encoding)),
+			}}
+			if desc := callee.paramDoc(callee.typing.Params[i].Name); desc != "" {
+				param.Documentation = &lsp.MarkupContent{Kind: lsp.MarkupMarkdown, Value: desc}
+			}
+			info.Parameters = append(info.Parameters, param)
+		}
+	}
+
+	positional := call.positional
+	if call.ufcs {
+		// `xs.map(f)` passes xs as the first argument.
+		positional++
+	}
+	return &lsp.SignatureHelp{
+		Signatures:      []lsp.SignatureInformation{info},
+		ActiveParameter: uint(activeParam(callee.typing, positional, call.named)),
+	}, nil
+}
+
+// openCall is what the text says about the call the cursor is in.
+type openCall struct {
+	name       string
+	ufcs       bool   // called as `receiver.name(...)`
+	positional int    // positional arguments before the cursor's
+	named      string // the name, if the cursor's argument is `name=...`
+}
+
+// callFrame is one open bracket during openCallAt's scan.
+type callFrame struct {
+	open       byte
+	at         int // offset of the bracket itself
+	argStart   int // offset the current argument starts at
+	positional int
+}
+
+// openCallAt scans src up to offset, tracking brackets, strings and
+// comments, and reports the innermost unclosed `(` that follows a
+// function name. Brackets after it don't hide it: in `f([1, ` the
+// cursor is still in f's first argument.
+func openCallAt(src string, offset int) (openCall, bool) {
+	var frames []callFrame
+	var quote string // the open string's delimiter, if in one
+	for i := 0; i < offset; i++ {
+		c := src[i]
+		if quote != "" {
+			switch {
+			case c == '\\' && quote != "`":
+				i++
+			case strings.HasPrefix(src[i:], quote):
+				i += len(quote) - 1
+				quote = ""
+			case c == '\n' && len(quote) == 1:
+				// An unterminated string ends with its line, so a
+				// stray quote doesn't swallow the rest of the file.
+				quote = ""
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = string(c)
+			if strings.HasPrefix(src[i:], `"""`) {
+				quote = `"""`
+				i += 2
+			}
+		case '/':
+			if strings.HasPrefix(src[i:], "//") {
+				for i < offset && src[i] != '\n' {
+					i++
+				}
+			}
+		case '(', '[', '{':
+			frames = append(frames, callFrame{open: c, at: i, argStart: i + 1})
+		case ')', ']', '}':
+			if len(frames) > 0 {
+				frames = frames[:len(frames)-1]
+			}
+		case ',':
+			if len(frames) > 0 {
+				top := &frames[len(frames)-1]
+				if namedArgPrefix.FindStringSubmatch(src[top.argStart:i]) == nil {
+					top.positional++
+				}
+				top.argStart = i + 1
+			}
+		}
+	}
+	if quote != "" {
+		return openCall{}, false
+	}
+
+	for idx := len(frames) - 1; idx >= 0; idx-- {
+		frame := frames[idx]
+		if frame.open != '(' {
+			continue
+		}
+		name, ufcs, ok := calleeNameBefore(src, frame.at)
+		if !ok {
+			return openCall{}, false
+		}
+		call := openCall{name: name, ufcs: ufcs, positional: frame.positional}
+		// The cursor's own argument, as far as it's written.
+		argEnd := offset
+		if idx+1 < len(frames) {
+			argEnd = frames[idx+1].at
+		}
+		if m := namedArgPrefix.FindStringSubmatch(src[frame.argStart:argEnd]); m != nil {
+			call.named = m[1]
+		}
+		return call, true
+	}
+	return openCall{}, false
+}
+
+// calleeNameBefore reads the function name ending just before the `(`
+// at paren. A keyword there means a parenthesized expression, and a
+// preceding `fn` means a definition, neither of which is a call.
+func calleeNameBefore(src string, paren int) (name string, ufcs bool, ok bool) {
+	end := paren
+	start := end
+	for start > 0 && isIdentByte(src[start-1]) {
+		start--
+	}
+	if start == end || (src[start] >= '0' && src[start] <= '9') {
+		return "", false, false
+	}
+	name = src[start:end]
+	if callKeywords[name] {
+		return "", false, false
+	}
+	before := strings.TrimRight(src[:start], " \t")
+	if strings.HasSuffix(before, "fn") && (len(before) == 2 || !isIdentByte(before[len(before)-3])) {
+		return "", false, false
+	}
+	return name, start > 0 && src[start-1] == '.', true
+}
+
+// callee is a function whose signature we can show.
+type callee struct {
+	label  string // `name(params) -> ret`, as builtins are documented
+	typing *rl.TypingFnT
+	doc    *rts.FuncDoc // builtins only
+}
+
+func (c *callee) paramDoc(name string) string {
+	if c.doc == nil {
+		return ""
+	}
+	for _, p := range c.doc.Parameters {
+		if strings.Trim(p.Name, "`") == name {
+			return p.Description
+		}
+	}
+	return ""
+}
+
+// lookupCallee finds the function called `name` as seen from pos: a
+// user function or function-valued variable in scope, else a
+// builtin. A user definition wins, since it shadows the builtin.
+func lookupCallee(snap *DocumentVersion, name string, pos lsp.Pos) *callee {
+	if indexes := pickResolvedSnapshot(snap); indexes != nil {
+		if sym := resolveReceiverSymbol(indexes, name, pos); sym != nil && sym.Kind != check.SymBuiltin {
+			if typing := symbolFnTyping(sym, indexes.types); typing != nil {
+				return &callee{label: fnLabel(name, typing), typing: typing}
+			}
+		}
+	}
+	sig, ok := rts.FnSignaturesByName[name]
+	if !ok || sig.IsInternal || sig.Typing == nil {
+		return nil
+	}
+	return &callee{label: sig.Signature, typing: sig.Typing, doc: rts.GetFuncDoc(name)}
+}
+
+// symbolFnTyping is the signature a symbol can be called with: a
+// named fn's own, or the function type a variable holds.
+func symbolFnTyping(sym *check.Symbol, info *check.TypeInfo) *rl.TypingFnT {
+	if def, ok := sym.DefNode.(*rl.FnDef); ok && def.Typing != nil {
+		return def.Typing
+	}
+	if info != nil {
+		if t, ok := info.SymbolTypes[sym].(*rl.TypingFnT); ok {
+			return t
+		}
+	}
+	if t, ok := sym.Declared.(*rl.TypingFnT); ok {
+		return t
+	}
+	return nil
+}
+
+// fnLabel renders a user function's signature the way builtin
+// signatures are written - `name(a: int, *, b: str = "x") -> str` -
+// so signature help reads the same whichever is being called.
+func fnLabel(name string, typing *rl.TypingFnT) string {
+	var sb strings.Builder
+	sb.WriteString(name)
+	sb.WriteString("(")
+	for i, p := range typing.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if p.NamedOnly && (i == 0 || !typing.Params[i-1].NamedOnly) {
+			sb.WriteString("*, ")
+		}
+		if p.IsVariadic {
+			sb.WriteString("*")
+		}
+		sb.WriteString(p.Name)
+		if p.Type != nil {
+			sb.WriteString(": ")
+			sb.WriteString(rl.DisplayName(*p.Type))
+		}
+		if p.DefaultAST != nil {
+			sb.WriteString(" = ")
+			sb.WriteString(p.DefaultAST.Src)
+		}
+	}
+	sb.WriteString(")")
+	if typing.ReturnT != nil {
+		sb.WriteString(" -> ")
+		sb.WriteString(rl.DisplayName(*typing.ReturnT))
+	}
+	return sb.String()
+}
+
+// paramLabelRanges finds each parameter's byte range in a signature
+// label by splitting the parameter list on its top-level commas. The
+// bare `*` that starts the named-only parameters isn't a parameter,
+// so it's skipped.
+func paramLabelRanges(label string) [][2]int {
+	open := strings.Index(label, "(")
+	if open < 0 {
+		return nil
+	}
+	var ranges [][2]int
+	add := func(start, end int) {
+		for start < end && label[start] == ' ' {
+			start++
+		}
+		for end > start && label[end-1] == ' ' {
+			end--
+		}
+		if start < end && label[start:end] != "*" {
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+	depth := 0
+	inString := false
+	start := open + 1
+	for i := start; i < len(label); i++ {
+		c := label[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				add(start, i)
+				return ranges
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				add(start, i)
+				start = i + 1
+			}
+		}
+	}
+	return ranges
+}
+
+// activeParam is the index of the parameter an argument fills: the
+// one it names, else the next positional one. Arguments past the
+// last positional parameter belong to it if it's variadic. No match
+// returns len(Params), which clients take as "highlight nothing".
+func activeParam(typing *rl.TypingFnT, positional int, named string) int {
+	if named != "" {
+		for i, p := range typing.Params {
+			if p.Name == named {
+				return i
+			}
+		}
+		return len(typing.Params)
+	}
+	seen := 0
+	last := -1
+	for i, p := range typing.Params {
+		if p.NamedOnly {
+			continue
+		}
+		if seen == positional {
+			return i
+		}
+		seen++
+		last = i
+	}
+	if last >= 0 && typing.Params[last].IsVariadic {
+		return last
+	}
+	return len(typing.Params)
+}
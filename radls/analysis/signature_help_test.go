@@ -0,0 +1,42 @@
+package analysis
+
+import "testing"
+
+// openCallAt reads half-written calls, which is when signature help is
+// asked for and when there's no parse to lean on - so these run on
+// text alone. `|` marks the cursor.
+func TestOpenCallAt(t *testing.T) {
+	cases := []struct {
+		src  string
+		want openCall
+		ok   bool
+	}{
+		{src: `greet(|`, want: openCall{name: "greet"}, ok: true},
+		{src: `greet(a, |`, want: openCall{name: "greet", positional: 1}, ok: true},
+		{src: `greet(a, loud=|`, want: openCall{name: "greet", positional: 1, named: "loud"}, ok: true},
+		{src: `greet(loud=true, b|`, want: openCall{name: "greet"}, ok: true},
+		{src: `greet(a == |`, want: openCall{name: "greet"}, ok: true},
+		{src: `xs.map(|`, want: openCall{name: "map", ufcs: true}, ok: true},
+		{src: `f(g(1, 2), |`, want: openCall{name: "f", positional: 1}, ok: true},
+		{src: `f([1, 2, |`, want: openCall{name: "f"}, ok: true},
+		{src: `f("a, b", "(", |`, want: openCall{name: "f", positional: 2}, ok: true},
+		{src: "f(a, // b, c\n|", want: openCall{name: "f", positional: 1}, ok: true},
+		{src: `f(a)|`},
+		{src: `x = (1 + |`},
+		{src: `if (|`},
+		{src: `fn greet(|`},
+		{src: `f("abc|`},
+	}
+	for _, c := range cases {
+		offset := len(c.src) - 1
+		src := c.src[:offset]
+		got, ok := openCallAt(src, offset)
+		if ok != c.ok {
+			t.Errorf("%q: ok=%v, want %v", c.src, ok, c.ok)
+			continue
+		}
+		if ok && got != c.want {
+			t.Errorf("%q: got %+v, want %+v", c.src, got, c.want)
+		}
+	}
+}
@@ -36,6 +36,11 @@ type State struct {
 	// parsers are NOT safe to share across goroutines; until/unless we
 	// hand out per-document parsers, every Parse() call needs this lock.
 	parserMu sync.Mutex
+
+	// workspace indexes every script in the workspace folders, open or
+	// not, for workspace symbols and cross-file references. Lock order:
+	// workspace.mu before mu, never the reverse.
+	workspace workspaceIndex
 }
 
 func NewState() *State {
@@ -49,6 +54,9 @@ func NewState() *State {
 		encoding: EncodingUTF16,
 		docs:     make(map[string]*Document),
 		idToDoc:  make(map[FileID]*Document),
+		workspace: workspaceIndex{
+			files: make(map[string]*indexedFile),
+		},
 	}
 }
 
@@ -153,6 +161,7 @@ func (s *State) AddDoc(uri, text string) {
 	s.docs[uri] = doc
 	s.idToDoc[id] = doc
 	s.mu.Unlock()
+	s.indexOpenDoc(uri)
 }
 
 // UpdateDoc applies a sequence of content changes and produces a fresh
@@ -176,6 +185,7 @@ func (s *State) UpdateDoc(uri string, changes []lsp.TextDocumentContentChangeEve
 			return s.buildVersionLocked(uri, doc.FileID(), nextVer, change.Text)
 		})
 	}
+	s.indexOpenDoc(uri)
 }
 
 // buildVersionLocked builds a new DocumentVersion while holding
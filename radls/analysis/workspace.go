@@ -0,0 +1,477 @@
+package analysis
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/amterp/rad/radls/log"
+	"github.com/amterp/rad/radls/lsp"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/rl"
+)
+
+// maxIndexedFileSize skips files too large to plausibly be scripts - a
+// generated blob that happens to end in .rad shouldn't stall the index.
+const maxIndexedFileSize = 1 << 20
+
+// maxWorkspaceSymbols caps a workspace/symbol reply. An empty or
+// one-letter query matches nearly everything; past a few hundred entries
+// the picker is unusable anyway and the user types more.
+const maxWorkspaceSymbols = 256
+
+// workspaceIndex is what the server knows about every Rad script in the
+// workspace folders, open or not: enough to answer workspace/symbol and to
+// find the calls into a file from the files that import it.
+//
+// Entries hold facts extracted from a checked version, not the version
+// itself - a repo with dozens of scripts shouldn't pin a tree-sitter tree
+// per file. An open document's entry comes from its latest snapshot; every
+// other entry comes from disk and is refreshed by didClose and
+// workspace/didChangeWatchedFiles.
+type workspaceIndex struct {
+	mu    sync.RWMutex
+	roots []string
+	files map[string]*indexedFile // keyed by URI
+}
+
+type indexedFile struct {
+	uri  string
+	path string
+	// symbols are the file's top-level functions and its commands,
+	// already in the negotiated encoding.
+	symbols []lsp.SymbolInformation
+	// calls are the calls this file makes through an import's namespace.
+	calls []moduleCall
+}
+
+// moduleCall is a call like `helpers.greet()`, with the import already
+// resolved to a path, so finding callers doesn't depend on where each
+// caller lives.
+type moduleCall struct {
+	path string
+	name string
+	rng  lsp.Range // the function-name identifier, e.g. `greet`
+}
+
+// SetWorkspaceFolders records the roots IndexWorkspace scans. Non-file
+// URIs are dropped - there's no disk to scan behind them.
+func (s *State) SetWorkspaceFolders(uris []string) {
+	roots := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		if path, ok := uriToPath(uri); ok {
+			roots = append(roots, filepath.Clean(path))
+		}
+	}
+	s.workspace.mu.Lock()
+	s.workspace.roots = roots
+	s.workspace.mu.Unlock()
+}
+
+// IndexWorkspace scans every workspace folder for Rad scripts and indexes
+// them. Blocking; the server runs it in the background after initialize,
+// and requests answered before it finishes just see fewer files.
+func (s *State) IndexWorkspace() {
+	s.workspace.mu.RLock()
+	roots := append([]string(nil), s.workspace.roots...)
+	s.workspace.mu.RUnlock()
+
+	for _, root := range roots {
+		s.indexTree(root)
+	}
+	s.workspace.mu.RLock()
+	log.L.Infof("Indexed %d workspace files", len(s.workspace.files))
+	s.workspace.mu.RUnlock()
+}
+
+// indexTree indexes every script under dir. Hidden directories and
+// node_modules are skipped: they're either tooling state or someone
+// else's code, and both can be huge.
+func (s *State) indexTree(dir string) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isWorkspaceScript(path, d) {
+			s.indexFromDisk(pathToURI(path), path)
+		}
+		return nil
+	})
+}
+
+// isWorkspaceScript reports whether a file is one to index: a .rad file,
+// or an extensionless file with a rad shebang - the usual shape of a
+// script that's run directly.
+func isWorkspaceScript(path string, d fs.DirEntry) bool {
+	name := d.Name()
+	if strings.HasPrefix(name, ".") || !d.Type().IsRegular() {
+		return false
+	}
+	if info, err := d.Info(); err != nil || info.Size() > maxIndexedFileSize {
+		return false
+	}
+	if strings.HasSuffix(name, ".rad") {
+		return true
+	}
+	return filepath.Ext(name) == "" && hasRadShebang(path)
+}
+
+// hasRadShebang checks the first line for rad as the interpreter -
+// exactly, so `#!/usr/bin/env gradle` doesn't count.
+func hasRadShebang(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+	if !strings.HasPrefix(line, "#!") {
+		return false
+	}
+	for _, token := range strings.Fields(line[2:]) {
+		if filepath.Base(token) == "rad" {
+			return true
+		}
+	}
+	return false
+}
+
+// indexFromDisk (re)indexes a file from its contents on disk, or drops it
+// from the index if it can no longer be read. An open document is left
+// alone: its buffer, not the disk, is the source of truth.
+func (s *State) indexFromDisk(uri, path string) {
+	if s.document(uri) != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		s.unindex(uri)
+		return
+	}
+
+	v := s.buildVersionLocked(uri, InvalidFileID, 0, string(data))
+	entry := indexVersion(v, path)
+	v.Release()
+
+	s.workspace.mu.Lock()
+	defer s.workspace.mu.Unlock()
+	// The document may have been opened while we were parsing; its
+	// entry, written by AddDoc, is fresher than ours.
+	if s.document(uri) != nil {
+		return
+	}
+	s.workspace.files[uri] = entry
+}
+
+// indexOpenDoc refreshes an open document's entry from its current
+// snapshot. Mid-edit versions that didn't check fall back to the last good
+// one, so a syntax error doesn't make the file's symbols blink out of
+// workspace search.
+func (s *State) indexOpenDoc(uri string) {
+	snap := s.Snapshot(uri)
+	if snap == nil {
+		return
+	}
+	defer snap.Release()
+
+	v := snap
+	if v.resolved == nil && snap.LastGood() != nil {
+		v = snap.LastGood()
+	}
+	path, _ := uriToPath(uri)
+	entry := indexVersion(v, path)
+
+	s.workspace.mu.Lock()
+	s.workspace.files[uri] = entry
+	s.workspace.mu.Unlock()
+}
+
+func (s *State) unindex(uri string) {
+	s.workspace.mu.Lock()
+	delete(s.workspace.files, uri)
+	s.workspace.mu.Unlock()
+}
+
+// inWorkspace reports whether path lies under one of the workspace roots.
+func (s *State) inWorkspace(path string) bool {
+	s.workspace.mu.RLock()
+	defer s.workspace.mu.RUnlock()
+	for _, root := range s.workspace.roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexVersion extracts a file's index entry from a checked version. path
+// may be empty for a buffer with no file behind it; its imports can't be
+// resolved, so it records no calls.
+func indexVersion(v *DocumentVersion, path string) *indexedFile {
+	entry := &indexedFile{uri: v.uri, path: path}
+	if v.ast == nil {
+		return entry
+	}
+
+	script := filepath.Base(path)
+	if path == "" {
+		script = v.uri
+	}
+	for _, stmt := range v.ast.Stmts {
+		if fn, ok := stmt.(*rl.FnDef); ok && fn.Name != "" {
+			entry.symbols = append(entry.symbols, lsp.SymbolInformation{
+				Name:          fn.Name,
+				Kind:          lsp.SymbolKindFunction,
+				Location:      locationFromSpan(v.uri, fn.NameSpan, v),
+				ContainerName: script,
+			})
+		}
+	}
+	for _, cmd := range v.ast.Cmds {
+		entry.symbols = appendCmdSymbols(entry.symbols, v, cmd, "", script)
+	}
+
+	if v.resolved == nil || path == "" {
+		return entry
+	}
+	dir := filepath.Dir(path)
+	for ident, ref := range v.resolved.ModuleRefs {
+		if ref.Import == nil || ref.Import.Path == "" {
+			continue
+		}
+		entry.calls = append(entry.calls, moduleCall{
+			path: rts.ResolveImport(ref.Import.Path, dir, radHome()),
+			name: ref.Name,
+			rng:  fromByteRange(spanToRange(ident.Span()), v),
+		})
+	}
+	return entry
+}
+
+// appendCmdSymbols adds a command and its sub-commands, each named by its
+// full invocation (`db migrate`) so a search for either word finds it.
+func appendCmdSymbols(out []lsp.SymbolInformation, v *DocumentVersion, cmd *rl.CmdBlock, prefix, script string) []lsp.SymbolInformation {
+	name := prefix + cmd.Name
+	out = append(out, lsp.SymbolInformation{
+		Name:          name,
+		Kind:          lsp.SymbolKindModule,
+		Location:      locationFromSpan(v.uri, cmd.Span(), v),
+		ContainerName: script,
+	})
+	for _, sub := range cmd.SubCmds {
+		out = appendCmdSymbols(out, v, sub, name+" ", script)
+	}
+	return out
+}
+
+// CloseDoc forgets an open document. If the file is a workspace script it
+// stays indexed, now from disk - closing a buffer with unsaved edits must
+// not leave its unsaved symbols behind.
+func (s *State) CloseDoc(uri string) {
+	s.mu.Lock()
+	doc, ok := s.docs[uri]
+	if ok {
+		delete(s.docs, uri)
+		delete(s.idToDoc, doc.FileID())
+	}
+	s.mu.Unlock()
+	if !ok {
+		log.L.Warnw("CloseDoc on unopened URI - ignoring", "uri", uri)
+		return
+	}
+	doc.close()
+
+	path, isFile := uriToPath(uri)
+	if isFile && s.inWorkspace(path) {
+		s.indexFromDisk(uri, path)
+	} else {
+		s.unindex(uri)
+	}
+}
+
+// FilesChanged applies workspace/didChangeWatchedFiles events to the
+// index. Open documents are skipped - the editor tells us about those. A
+// created directory (a checkout, an unzip) is scanned as a whole.
+func (s *State) FilesChanged(events []lsp.FileEvent) {
+	for _, ev := range events {
+		if s.document(ev.Uri) != nil {
+			continue
+		}
+		path, ok := uriToPath(ev.Uri)
+		if !ok {
+			continue
+		}
+		if ev.Type == lsp.FileDeleted {
+			s.unindexUnder(path)
+			continue
+		}
+		if !s.inWorkspace(path) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			s.unindex(ev.Uri)
+			continue
+		}
+		if info.IsDir() {
+			s.indexTree(path)
+			continue
+		}
+		if isWorkspaceScript(path, fs.FileInfoToDirEntry(info)) {
+			s.indexFromDisk(ev.Uri, path)
+		} else {
+			s.unindex(ev.Uri)
+		}
+	}
+}
+
+// unindexUnder drops the file at path, or everything beneath it when path
+// was a directory - a deletion event doesn't say which.
+func (s *State) unindexUnder(path string) {
+	prefix := path + string(filepath.Separator)
+	s.workspace.mu.Lock()
+	defer s.workspace.mu.Unlock()
+	for uri, f := range s.workspace.files {
+		if f.path == "" || s.document(uri) != nil {
+			continue
+		}
+		if f.path == path || strings.HasPrefix(f.path, prefix) {
+			delete(s.workspace.files, uri)
+		}
+	}
+}
+
+// WorkspaceSymbols answers workspace/symbol: the top-level functions and
+// commands of every indexed script whose name matches query. Matching is
+// case-insensitive and fuzzy - "dpl" finds "deploy" - with exact, then
+// prefix, then substring matches ranked ahead of looser ones. Returns an
+// empty slice (not nil) when nothing matches.
+func (s *State) WorkspaceSymbols(query string) []lsp.SymbolInformation {
+	type match struct {
+		sym   lsp.SymbolInformation
+		score int
+	}
+	var matches []match
+	q := strings.ToLower(query)
+
+	s.workspace.mu.RLock()
+	for _, f := range s.workspace.files {
+		for _, sym := range f.symbols {
+			if score, ok := symbolMatch(strings.ToLower(sym.Name), q); ok {
+				matches = append(matches, match{sym, score})
+			}
+		}
+	}
+	s.workspace.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score < b.score
+		}
+		if a.sym.Name != b.sym.Name {
+			return a.sym.Name < b.sym.Name
+		}
+		return locationLess(a.sym.Location, b.sym.Location)
+	})
+	if len(matches) > maxWorkspaceSymbols {
+		matches = matches[:maxWorkspaceSymbols]
+	}
+
+	out := make([]lsp.SymbolInformation, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, m.sym)
+	}
+	return out
+}
+
+// symbolMatch scores name against query, both lower-cased; lower scores
+// rank first. ok is false when query's characters don't all appear in
+// name in order.
+func symbolMatch(name, query string) (score int, ok bool) {
+	switch {
+	case query == "" || name == query:
+		return 0, true
+	case strings.HasPrefix(name, query):
+		return 1, true
+	case strings.Contains(name, query):
+		return 2, true
+	}
+	rest := query
+	for i := 0; i < len(name) && rest != ""; i++ {
+		if name[i] == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return 3, rest == ""
+}
+
+// callsInto returns the location of every indexed call to the top-level
+// function name of the file at uri, made through an import of that file.
+// Sorted by file, then position.
+func (s *State) callsInto(uri, name string) []lsp.Location {
+	path, ok := uriToPath(uri)
+	if !ok {
+		return nil
+	}
+	path = filepath.Clean(path)
+
+	var out []lsp.Location
+	s.workspace.mu.RLock()
+	for _, f := range s.workspace.files {
+		for _, c := range f.calls {
+			if c.name == name && filepath.Clean(c.path) == path {
+				out = append(out, lsp.Location{Uri: f.uri, Range: c.rng})
+			}
+		}
+	}
+	s.workspace.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return locationLess(out[i], out[j]) })
+	return out
+}
+
+func locationLess(a, b lsp.Location) bool {
+	if a.Uri != b.Uri {
+		return a.Uri < b.Uri
+	}
+	if a.Range.Start.Line != b.Range.Start.Line {
+		return a.Range.Start.Line < b.Range.Start.Line
+	}
+	return a.Range.Start.Character < b.Range.Start.Character
+}
+
+// withVersion runs fn against a checked version of a file: the open
+// buffer's snapshot if there is one, else a throwaway build from disk.
+// Returns false, without calling fn, if the file can't be read.
+func (s *State) withVersion(uri, path string, fn func(v *DocumentVersion)) bool {
+	if snap := s.Snapshot(uri); snap != nil {
+		defer snap.Release()
+		fn(snap)
+		return true
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	v := s.buildVersionLocked(uri, InvalidFileID, 0, string(data))
+	defer v.Release()
+	fn(v)
+	return true
+}
@@ -0,0 +1,242 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/amterp/rad/radls/lsp"
+)
+
+// The snapshot harness holds a single document, so the workspace index -
+// which is about files the editor hasn't opened - is covered here.
+
+const workspaceHelpers = "fn greet(name):\n    return \"hi {name}\"\n\nprint(greet(\"me\"))\n"
+
+const workspaceMain = "h = import(\"./helpers\")\nprint(h.greet(\"bob\"))\n"
+
+const workspaceDeploy = "#!/usr/bin/env rad\ncommand deploy:\n    ---\n    Ship it.\n    ---\n    calls on_deploy\n\nfn on_deploy():\n    pass\n"
+
+func writeWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func indexedState(t *testing.T, dir string) *State {
+	t.Helper()
+	s := NewState()
+	s.SetWorkspaceFolders([]string{pathToURI(dir)})
+	s.IndexWorkspace()
+	return s
+}
+
+func symbolNames(syms []lsp.SymbolInformation) []string {
+	names := make([]string, 0, len(syms))
+	for _, sym := range syms {
+		names = append(names, sym.Name)
+	}
+	return names
+}
+
+func TestWorkspaceSymbolsFindsCommandInShebangScript(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{
+		"ops/deploy":             workspaceDeploy,
+		"helpers.rad":            workspaceHelpers,
+		"notes.txt":              "command deploy:\n",
+		".git/hooks/deploy.rad":  workspaceDeploy,
+		"node_modules/x/dep.rad": workspaceDeploy,
+	})
+	s := indexedState(t, dir)
+
+	syms := s.WorkspaceSymbols("deploy")
+	if len(syms) != 2 {
+		t.Fatalf("expected deploy and on_deploy, got %v", symbolNames(syms))
+	}
+	got := syms[0]
+	if got.Name != "deploy" || got.Kind != lsp.SymbolKindModule {
+		t.Errorf("first match = %q (kind %d), expected the deploy command", got.Name, got.Kind)
+	}
+	if want := pathToURI(filepath.Join(dir, "ops", "deploy")); got.Location.Uri != want {
+		t.Errorf("uri = %q, expected %q", got.Location.Uri, want)
+	}
+	if got.ContainerName != "deploy" {
+		t.Errorf("container = %q, expected the script name", got.ContainerName)
+	}
+	if syms[1].Name != "on_deploy" {
+		t.Errorf("second match = %q, expected on_deploy", syms[1].Name)
+	}
+}
+
+func TestWorkspaceSymbolsFuzzyMatch(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{"helpers.rad": workspaceHelpers})
+	s := indexedState(t, dir)
+
+	if syms := s.WorkspaceSymbols("GRT"); len(syms) != 1 || syms[0].Name != "greet" {
+		t.Errorf("expected greet, got %v", symbolNames(syms))
+	}
+	if syms := s.WorkspaceSymbols("xyz"); syms == nil || len(syms) != 0 {
+		t.Errorf("expected an empty slice, got %v", syms)
+	}
+}
+
+func TestSymbolMatchRanking(t *testing.T) {
+	cases := []struct {
+		name, query string
+		score       int
+		ok          bool
+	}{
+		{"deploy", "deploy", 0, true},
+		{"deploy", "", 0, true},
+		{"deploy_all", "deploy", 1, true},
+		{"on_deploy", "deploy", 2, true},
+		{"deploy", "dpl", 3, true},
+		{"deploy", "lpd", 0, false},
+	}
+	for _, c := range cases {
+		score, ok := symbolMatch(c.name, c.query)
+		if ok != c.ok || (ok && score != c.score) {
+			t.Errorf("symbolMatch(%q, %q) = %d, %v; expected %d, %v", c.name, c.query, score, ok, c.score, c.ok)
+		}
+	}
+}
+
+func TestReferencesIncludeCallsFromImporters(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{
+		"helpers.rad": workspaceHelpers,
+		"main.rad":    workspaceMain,
+	})
+	s := indexedState(t, dir)
+	helpersURI := pathToURI(filepath.Join(dir, "helpers.rad"))
+	mainURI := pathToURI(filepath.Join(dir, "main.rad"))
+
+	s.AddDoc(helpersURI, workspaceHelpers)
+	snap := s.Snapshot(helpersURI)
+	defer snap.Release()
+
+	locs, err := s.References(snap, lsp.NewPos(0, 4), true)
+	if err != nil {
+		t.Fatalf("References: %v", err)
+	}
+	want := []lsp.Location{
+		{Uri: helpersURI, Range: lsp.NewRange(0, 3, 0, 8)},
+		{Uri: helpersURI, Range: lsp.NewRange(3, 6, 3, 11)},
+		{Uri: mainURI, Range: lsp.NewRange(1, 8, 1, 13)},
+	}
+	assertLocations(t, locs, want)
+}
+
+func TestReferencesFromImporterCall(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{
+		"helpers.rad": workspaceHelpers,
+		"main.rad":    workspaceMain,
+	})
+	s := indexedState(t, dir)
+	helpersURI := pathToURI(filepath.Join(dir, "helpers.rad"))
+	mainURI := pathToURI(filepath.Join(dir, "main.rad"))
+
+	s.AddDoc(mainURI, workspaceMain)
+	snap := s.Snapshot(mainURI)
+	defer snap.Release()
+
+	locs, err := s.References(snap, lsp.NewPos(1, 9), false)
+	if err != nil {
+		t.Fatalf("References: %v", err)
+	}
+	want := []lsp.Location{
+		{Uri: helpersURI, Range: lsp.NewRange(3, 6, 3, 11)},
+		{Uri: mainURI, Range: lsp.NewRange(1, 8, 1, 13)},
+	}
+	assertLocations(t, locs, want)
+}
+
+func TestRenameEditsImporters(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{
+		"helpers.rad": workspaceHelpers,
+		"main.rad":    workspaceMain,
+	})
+	s := indexedState(t, dir)
+	helpersURI := pathToURI(filepath.Join(dir, "helpers.rad"))
+	mainURI := pathToURI(filepath.Join(dir, "main.rad"))
+
+	s.AddDoc(mainURI, workspaceMain)
+	snap := s.Snapshot(mainURI)
+	defer snap.Release()
+
+	edit, err := s.Rename(snap, lsp.NewPos(1, 9), "welcome")
+	if err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if n := len(edit.Changes[helpersURI]); n != 2 {
+		t.Errorf("expected 2 edits in helpers.rad, got %d", n)
+	}
+	if n := len(edit.Changes[mainURI]); n != 1 {
+		t.Errorf("expected 1 edit in main.rad, got %d", n)
+	}
+}
+
+func TestFilesChangedRefreshesIndex(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{"helpers.rad": workspaceHelpers})
+	s := indexedState(t, dir)
+	path := filepath.Join(dir, "tools.rad")
+	uri := pathToURI(path)
+
+	if err := os.WriteFile(path, []byte("fn tidy():\n    pass\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s.FilesChanged([]lsp.FileEvent{{Uri: uri, Type: lsp.FileCreated}})
+	if syms := s.WorkspaceSymbols("tidy"); len(syms) != 1 {
+		t.Fatalf("expected tidy after create, got %v", symbolNames(syms))
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	s.FilesChanged([]lsp.FileEvent{{Uri: uri, Type: lsp.FileDeleted}})
+	if syms := s.WorkspaceSymbols("tidy"); len(syms) != 0 {
+		t.Errorf("expected no tidy after delete, got %v", symbolNames(syms))
+	}
+}
+
+func TestCloseDocFallsBackToDisk(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{"helpers.rad": workspaceHelpers})
+	s := indexedState(t, dir)
+	uri := pathToURI(filepath.Join(dir, "helpers.rad"))
+
+	s.AddDoc(uri, "fn unsaved():\n    pass\n")
+	if syms := s.WorkspaceSymbols("unsaved"); len(syms) != 1 {
+		t.Fatalf("expected the open buffer's symbol, got %v", symbolNames(syms))
+	}
+
+	s.CloseDoc(uri)
+	if s.Snapshot(uri) != nil {
+		t.Error("expected no snapshot after close")
+	}
+	if syms := s.WorkspaceSymbols("unsaved"); len(syms) != 0 {
+		t.Errorf("expected the unsaved symbol to be gone, got %v", symbolNames(syms))
+	}
+	if syms := s.WorkspaceSymbols("greet"); len(syms) != 1 {
+		t.Errorf("expected greet from disk, got %v", symbolNames(syms))
+	}
+}
+
+func assertLocations(t *testing.T, got, want []lsp.Location) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d locations %+v, expected %d %+v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("location %d = %+v, expected %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -8,8 +8,10 @@ import (
 // decode only the fields we actually use; the rest of the spec is large
 // and most of it isn't yet load-bearing for radls.
 type InitializeParams struct {
-	ClientInfo   *ClientInfo        `json:"clientInfo"`
-	Capabilities ClientCapabilities `json:"capabilities"`
+	ClientInfo       *ClientInfo        `json:"clientInfo"`
+	Capabilities     ClientCapabilities `json:"capabilities"`
+	RootUri          string             `json:"rootUri"`
+	WorkspaceFolders []WorkspaceFolder  `json:"workspaceFolders"`
 }
 
 type ClientInfo struct {
@@ -39,16 +41,32 @@ type InitializeResult struct {
 // list; clients use it for every subsequent request/response that
 // contains line/character positions.
 type ServerCapabilities struct {
-	TextDocumentSync       int32                   `json:"textDocumentSync"`
-	HoverProvider          bool                    `json:"hoverProvider"`
-	DefinitionProvider     bool                    `json:"definitionProvider"`
-	DocumentSymbolProvider bool                    `json:"documentSymbolProvider"`
-	ReferencesProvider     bool                    `json:"referencesProvider"`
-	CodeActionProvider     bool                    `json:"codeActionProvider"`
-	RenameProvider         bool                    `json:"renameProvider"`
-	CompletionProvider     map[string]any          `json:"completionProvider"`
-	SemanticTokensProvider *SemanticTokensProvider `json:"semanticTokensProvider,omitempty"`
-	PositionEncoding       string                  `json:"positionEncoding,omitempty"`
+	TextDocumentSync                 int32                            `json:"textDocumentSync"`
+	HoverProvider                    bool                             `json:"hoverProvider"`
+	DefinitionProvider               bool                             `json:"definitionProvider"`
+	DocumentSymbolProvider           bool                             `json:"documentSymbolProvider"`
+	ReferencesProvider               bool                             `json:"referencesProvider"`
+	CodeActionProvider               bool                             `json:"codeActionProvider"`
+	RenameProvider                   bool                             `json:"renameProvider"`
+	DocumentFormattingProvider       bool                             `json:"documentFormattingProvider"`
+	DocumentRangeFormattingProvider  bool                             `json:"documentRangeFormattingProvider"`
+	DocumentOnTypeFormattingProvider *DocumentOnTypeFormattingOptions `json:"documentOnTypeFormattingProvider,omitempty"`
+	SignatureHelpProvider            *SignatureHelpOptions            `json:"signatureHelpProvider,omitempty"`
+	InlayHintProvider                bool                             `json:"inlayHintProvider"`
+	WorkspaceSymbolProvider          bool                             `json:"workspaceSymbolProvider"`
+	FoldingRangeProvider             bool                             `json:"foldingRangeProvider"`
+	SelectionRangeProvider           bool                             `json:"selectionRangeProvider"`
+	DocumentHighlightProvider        bool                             `json:"documentHighlightProvider"`
+	CompletionProvider               map[string]any                   `json:"completionProvider"`
+	SemanticTokensProvider           *SemanticTokensProvider          `json:"semanticTokensProvider,omitempty"`
+	PositionEncoding                 string                           `json:"positionEncoding,omitempty"`
+}
+
+// DocumentOnTypeFormattingOptions names the characters that trigger
+// on-type formatting. We only format once a line is finished, so a
+// newline is the only trigger.
+type DocumentOnTypeFormattingOptions struct {
+	FirstTriggerCharacter string `json:"firstTriggerCharacter"`
 }
 
 type ServerInfo struct {
@@ -64,13 +82,27 @@ type ServerInfo struct {
 func NewInitializeResult(positionEncoding string, semLegend SemanticTokensLegend) InitializeResult {
 	return InitializeResult{
 		Capabilities: ServerCapabilities{
-			TextDocumentSync:       1,
-			HoverProvider:          true,
-			DefinitionProvider:     true,
-			DocumentSymbolProvider: true,
-			ReferencesProvider:     true,
-			CodeActionProvider:     true,
-			RenameProvider:         true,
+			TextDocumentSync:                1,
+			HoverProvider:                   true,
+			DefinitionProvider:              true,
+			DocumentSymbolProvider:          true,
+			ReferencesProvider:              true,
+			CodeActionProvider:              true,
+			RenameProvider:                  true,
+			DocumentFormattingProvider:      true,
+			DocumentRangeFormattingProvider: true,
+			DocumentOnTypeFormattingProvider: &DocumentOnTypeFormattingOptions{
+				FirstTriggerCharacter: "\n",
+			},
+			SignatureHelpProvider: &SignatureHelpOptions{
+				TriggerCharacters:   []string{"(", ","},
+				RetriggerCharacters: []string{"="},
+			},
+			InlayHintProvider:         true,
+			WorkspaceSymbolProvider:   true,
+			FoldingRangeProvider:      true,
+			SelectionRangeProvider:    true,
+			DocumentHighlightProvider: true,
 			CompletionProvider: map[string]any{
 				"triggerCharacters": []string{".", "#", "$", "!", "/"},
 			},
@@ -4,9 +4,11 @@ import "encoding/json"
 
 const (
 	INITIALIZE             = "initialize"
+	INITIALIZED            = "initialized"
 	CANCEL_REQUEST         = "$/cancelRequest"
 	TD_DID_OPEN            = "textDocument/didOpen"
 	TD_DID_CHANGE          = "textDocument/didChange"
+	TD_DID_CLOSE           = "textDocument/didClose"
 	TD_COMPLETION          = "textDocument/completion"
 	TD_CODE_ACTION         = "textDocument/codeAction"
 	TD_HOVER               = "textDocument/hover"
@@ -16,6 +18,16 @@ const (
 	TD_SEMANTIC_TOKENS     = "textDocument/semanticTokens/full"
 	TD_PUBLISH_DIAGNOSTICS = "textDocument/publishDiagnostics"
 	TD_RENAME              = "textDocument/rename"
+	TD_FORMATTING          = "textDocument/formatting"
+	TD_RANGE_FORMATTING    = "textDocument/rangeFormatting"
+	TD_ON_TYPE_FORMATTING  = "textDocument/onTypeFormatting"
+	TD_SIGNATURE_HELP      = "textDocument/signatureHelp"
+	TD_INLAY_HINT          = "textDocument/inlayHint"
+	TD_FOLDING_RANGE       = "textDocument/foldingRange"
+	TD_SELECTION_RANGE     = "textDocument/selectionRange"
+	TD_DOCUMENT_HIGHLIGHT  = "textDocument/documentHighlight"
+	WS_SYMBOL              = "workspace/symbol"
+	WS_DID_CHANGE_WATCHED  = "workspace/didChangeWatchedFiles"
 )
 
 // CancelParams matches the LSP 3.17 $/cancelRequest payload. The
@@ -160,6 +160,153 @@ type RenameParams struct {
 	NewName string `json:"newName"`
 }
 
+// FormattingOptions are the client's indentation preferences. radfmt's
+// output is canonical, so they're decoded but don't change the result.
+type FormattingOptions struct {
+	TabSize      int  `json:"tabSize"`
+	InsertSpaces bool `json:"insertSpaces"`
+}
+
+// DocumentFormattingParams is the textDocument/formatting payload. The
+// response is the list of TextEdits that format the whole document.
+type DocumentFormattingParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Options      FormattingOptions      `json:"options"`
+}
+
+// DocumentRangeFormattingParams is the textDocument/rangeFormatting
+// payload: format only what the selection covers.
+type DocumentRangeFormattingParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Options      FormattingOptions      `json:"options"`
+}
+
+// DocumentOnTypeFormattingParams is the textDocument/onTypeFormatting
+// payload. Position is where the cursor is after Ch was typed.
+type DocumentOnTypeFormattingParams struct {
+	TextDocumentPositionParams
+	Ch      string            `json:"ch"`
+	Options FormattingOptions `json:"options"`
+}
+
+// SignatureHelpParams is the textDocument/signatureHelp payload. The
+// spec's optional `context` (trigger kind, previous help) isn't
+// decoded: we recompute from the text on every request anyway.
+type SignatureHelpParams struct {
+	TextDocumentPositionParams
+}
+
+// SignatureHelp is the response to textDocument/signatureHelp. Rad
+// has no overloads, so there's only ever one signature and
+// ActiveSignature is always 0.
+type SignatureHelp struct {
+	Signatures      []SignatureInformation `json:"signatures"`
+	ActiveSignature uint                   `json:"activeSignature"`
+	ActiveParameter uint                   `json:"activeParameter"`
+}
+
+type SignatureInformation struct {
+	Label         string                 `json:"label"`
+	Documentation *MarkupContent         `json:"documentation,omitempty"`
+	Parameters    []ParameterInformation `json:"parameters"`
+}
+
+// ParameterInformation's Label is the [start, end) of the parameter
+// within the signature label, counted in the negotiated position
+// encoding. That's the spec's offset form, which unlike the
+// substring form can't match the wrong occurrence of a repeated name.
+type ParameterInformation struct {
+	Label         [2]uint        `json:"label"`
+	Documentation *MarkupContent `json:"documentation,omitempty"`
+}
+
+// SignatureHelpOptions is the capability advertised at initialize.
+type SignatureHelpOptions struct {
+	TriggerCharacters   []string `json:"triggerCharacters"`
+	RetriggerCharacters []string `json:"retriggerCharacters,omitempty"`
+}
+
+// InlayHintParams is the textDocument/inlayHint payload: hints are
+// requested for the visible range only.
+type InlayHintParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+// InlayHintKind matches the LSP 3.17 InlayHintKind enum.
+type InlayHintKind int
+
+const (
+	InlayHintKindType      InlayHintKind = 1
+	InlayHintKindParameter InlayHintKind = 2
+)
+
+// InlayHint is one piece of virtual text the client renders at
+// Position. Padding asks the client for a space on that side, so
+// labels don't have to carry their own.
+type InlayHint struct {
+	Position     Pos           `json:"position"`
+	Label        string        `json:"label"`
+	Kind         InlayHintKind `json:"kind"`
+	PaddingLeft  bool          `json:"paddingLeft,omitempty"`
+	PaddingRight bool          `json:"paddingRight,omitempty"`
+}
+
+type FoldingRangeParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// FoldingRangeKind matches the LSP 3.17 FoldingRangeKind values.
+// Editors use it for "fold all comments" style commands.
+type FoldingRangeKind string
+
+const (
+	FoldingRangeComment FoldingRangeKind = "comment"
+	FoldingRangeRegion  FoldingRangeKind = "region"
+)
+
+// FoldingRange is line-based; we leave the optional start/end
+// characters out, which tells the client to fold whole lines.
+type FoldingRange struct {
+	StartLine int              `json:"startLine"`
+	EndLine   int              `json:"endLine"`
+	Kind      FoldingRangeKind `json:"kind,omitempty"`
+}
+
+// SelectionRangeParams asks for one expand-selection chain per
+// position - editors with multiple cursors send several.
+type SelectionRangeParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Positions    []Pos                  `json:"positions"`
+}
+
+// SelectionRange is one step of an expand-selection chain. Parent is
+// the next, strictly larger range; the outermost has none.
+type SelectionRange struct {
+	Range  Range           `json:"range"`
+	Parent *SelectionRange `json:"parent,omitempty"`
+}
+
+type DocumentHighlightParams struct {
+	TextDocumentPositionParams
+}
+
+// DocumentHighlightKind matches the LSP 3.17 enum. Write marks the
+// sites that bind or assign the symbol, Read every other use.
+type DocumentHighlightKind int
+
+const (
+	DocumentHighlightText  DocumentHighlightKind = 1
+	DocumentHighlightRead  DocumentHighlightKind = 2
+	DocumentHighlightWrite DocumentHighlightKind = 3
+)
+
+type DocumentHighlight struct {
+	Range Range                 `json:"range"`
+	Kind  DocumentHighlightKind `json:"kind"`
+}
+
 // SemanticTokensParams is the textDocument/semanticTokens/full
 // payload. Whole-document; no range provided. Range-mode is a
 // future opt-in for very large files where we want to scope work.
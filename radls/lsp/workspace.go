@@ -0,0 +1,50 @@
+package lsp
+
+// WorkspaceFolder is one root the client has open. A client that predates
+// workspace folders sends a single RootUri instead.
+type WorkspaceFolder struct {
+	Uri  string `json:"uri"`
+	Name string `json:"name"`
+}
+
+// DidCloseTextDocumentParams is sent when the editor closes a buffer. From
+// then on the file on disk is the source of truth again.
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// FileChangeType matches the LSP 3.17 FileChangeType enum.
+type FileChangeType int
+
+const (
+	FileCreated FileChangeType = 1
+	FileChanged FileChangeType = 2
+	FileDeleted FileChangeType = 3
+)
+
+type FileEvent struct {
+	Uri  string         `json:"uri"`
+	Type FileChangeType `json:"type"`
+}
+
+// DidChangeWatchedFilesParams reports files changed on disk by something
+// other than the editor - a git checkout, a generator, another tool.
+type DidChangeWatchedFilesParams struct {
+	Changes []FileEvent `json:"changes"`
+}
+
+// WorkspaceSymbolParams carries the user's search text. An empty query asks
+// for every symbol.
+type WorkspaceSymbolParams struct {
+	Query string `json:"query"`
+}
+
+// SymbolInformation is the flat symbol shape workspace/symbol answers with.
+// ContainerName is shown beside the name, so a command reads as belonging
+// to its script.
+type SymbolInformation struct {
+	Name          string     `json:"name"`
+	Kind          SymbolKind `json:"kind"`
+	Location      Location   `json:"location"`
+	ContainerName string     `json:"containerName,omitempty"`
+}
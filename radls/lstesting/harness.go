@@ -99,6 +99,30 @@ func Run(tc *SnapshotCase) (string, error) {
 		case ActionSemanticTokens:
 			err = sendSemanticTokens(bw, requestId)
 			requestId++
+		case ActionFormatting:
+			err = sendFormatting(bw, requestId)
+			requestId++
+		case ActionRangeFormatting:
+			err = sendRangeFormatting(bw, requestId, *action.Range)
+			requestId++
+		case ActionOnTypeFormatting:
+			err = sendOnTypeFormatting(bw, requestId, *action.Position)
+			requestId++
+		case ActionSignatureHelp:
+			err = sendSignatureHelp(bw, requestId, *action.Position)
+			requestId++
+		case ActionInlayHint:
+			err = sendInlayHint(bw, requestId, *action.Range)
+			requestId++
+		case ActionFoldingRange:
+			err = sendFoldingRange(bw, requestId)
+			requestId++
+		case ActionSelectionRange:
+			err = sendSelectionRange(bw, requestId, action.Positions)
+			requestId++
+		case ActionDocumentHighlight:
+			err = sendDocumentHighlight(bw, requestId, *action.Position)
+			requestId++
 		}
 		if err != nil {
 			clientWriter.Close()
@@ -311,6 +335,82 @@ func sendSemanticTokens(bw *bufio.Writer, id int) error {
 	return sendRequest(bw, id, lsp.TD_SEMANTIC_TOKENS, params)
 }
 
+// testFormattingOptions is what a typical client sends. radls ignores
+// it, but the harness sends it so requests look like real ones.
+var testFormattingOptions = lsp.FormattingOptions{TabSize: 4, InsertSpaces: true}
+
+func sendFormatting(bw *bufio.Writer, id int) error {
+	params := lsp.DocumentFormattingParams{
+		TextDocument: lsp.TextDocumentIdentifier{Uri: testURI},
+		Options:      testFormattingOptions,
+	}
+	return sendRequest(bw, id, lsp.TD_FORMATTING, params)
+}
+
+func sendRangeFormatting(bw *bufio.Writer, id int, r lsp.Range) error {
+	params := lsp.DocumentRangeFormattingParams{
+		TextDocument: lsp.TextDocumentIdentifier{Uri: testURI},
+		Range:        r,
+		Options:      testFormattingOptions,
+	}
+	return sendRequest(bw, id, lsp.TD_RANGE_FORMATTING, params)
+}
+
+func sendOnTypeFormatting(bw *bufio.Writer, id int, pos lsp.Pos) error {
+	params := lsp.DocumentOnTypeFormattingParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{Uri: testURI},
+			Position:     pos,
+		},
+		Ch:      "\n",
+		Options: testFormattingOptions,
+	}
+	return sendRequest(bw, id, lsp.TD_ON_TYPE_FORMATTING, params)
+}
+
+func sendSignatureHelp(bw *bufio.Writer, id int, pos lsp.Pos) error {
+	params := lsp.SignatureHelpParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{Uri: testURI},
+			Position:     pos,
+		},
+	}
+	return sendRequest(bw, id, lsp.TD_SIGNATURE_HELP, params)
+}
+
+func sendInlayHint(bw *bufio.Writer, id int, r lsp.Range) error {
+	params := lsp.InlayHintParams{
+		TextDocument: lsp.TextDocumentIdentifier{Uri: testURI},
+		Range:        r,
+	}
+	return sendRequest(bw, id, lsp.TD_INLAY_HINT, params)
+}
+
+func sendFoldingRange(bw *bufio.Writer, id int) error {
+	params := lsp.FoldingRangeParams{
+		TextDocument: lsp.TextDocumentIdentifier{Uri: testURI},
+	}
+	return sendRequest(bw, id, lsp.TD_FOLDING_RANGE, params)
+}
+
+func sendSelectionRange(bw *bufio.Writer, id int, positions []lsp.Pos) error {
+	params := lsp.SelectionRangeParams{
+		TextDocument: lsp.TextDocumentIdentifier{Uri: testURI},
+		Positions:    positions,
+	}
+	return sendRequest(bw, id, lsp.TD_SELECTION_RANGE, params)
+}
+
+func sendDocumentHighlight(bw *bufio.Writer, id int, pos lsp.Pos) error {
+	params := lsp.DocumentHighlightParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{Uri: testURI},
+			Position:     pos,
+		},
+	}
+	return sendRequest(bw, id, lsp.TD_DOCUMENT_HIGHLIGHT, params)
+}
+
 // marshalRaw marshals a value into a json.RawMessage.
 func marshalRaw(v any) json.RawMessage {
 	b, err := json.Marshal(v)
@@ -23,6 +23,14 @@ const (
 	ActionReferences
 	ActionRename
 	ActionSemanticTokens
+	ActionFormatting
+	ActionRangeFormatting
+	ActionOnTypeFormatting
+	ActionSignatureHelp
+	ActionInlayHint
+	ActionFoldingRange
+	ActionSelectionRange
+	ActionDocumentHighlight
 )
 
 // Action carries one snapshot action's parameters. Different ActionTypes use
@@ -32,8 +40,9 @@ const (
 type Action struct {
 	Type               ActionType
 	Content            string     // For CHANGE: new document text
-	Position           *lsp.Pos   // For COMPLETION / HOVER / DEFINITION / REFERENCES / RENAME
-	Range              *lsp.Range // For CODE_ACTION: selected range
+	Position           *lsp.Pos   // For COMPLETION / HOVER / DEFINITION / REFERENCES / RENAME / ON_TYPE_FORMATTING / SIGNATURE_HELP / DOCUMENT_HIGHLIGHT
+	Positions          []lsp.Pos  // For SELECTION_RANGE: one chain per position
+	Range              *lsp.Range // For CODE_ACTION / RANGE_FORMATTING / INLAY_HINT: selected range
 	IncludeDeclaration bool       // For REFERENCES: matches LSP context flag
 	NewName            string     // For RENAME: the rename target name
 }
@@ -66,6 +75,16 @@ var Suite = snap.Suite{
 		{Name: "REFERENCES", Args: -1},
 		{Name: "RENAME", Args: 2},
 		{Name: "SEMANTIC_TOKENS"},
+		{Name: "FORMATTING"},
+		{Name: "RANGE_FORMATTING", Args: 2},
+		// The cursor position just after a typed newline.
+		{Name: "ON_TYPE_FORMATTING", Args: 1},
+		{Name: "SIGNATURE_HELP", Args: 1},
+		{Name: "INLAY_HINT", Args: 2},
+		{Name: "FOLDING_RANGE"},
+		// One or more positions.
+		{Name: "SELECTION_RANGE", Args: -1},
+		{Name: "DOCUMENT_HIGHLIGHT", Args: 1},
 	},
 	Outputs:  []snap.Output{{Name: "STDOUT"}},
 	Parallel: true,
@@ -100,17 +119,24 @@ func actionFrom(step *snap.Step) (Action, error) {
 		return Action{Type: ActionDocumentSymbol}, nil
 	case "SEMANTIC_TOKENS":
 		return Action{Type: ActionSemanticTokens}, nil
-	case "COMPLETION", "HOVER", "DEFINITION":
+	case "FORMATTING":
+		return Action{Type: ActionFormatting}, nil
+	case "FOLDING_RANGE":
+		return Action{Type: ActionFoldingRange}, nil
+	case "COMPLETION", "HOVER", "DEFINITION", "ON_TYPE_FORMATTING", "SIGNATURE_HELP", "DOCUMENT_HIGHLIGHT":
 		p, err := pos(0)
 		if err != nil {
 			return Action{}, err
 		}
 		return Action{Type: map[string]ActionType{
-			"COMPLETION": ActionCompletion,
-			"HOVER":      ActionHover,
-			"DEFINITION": ActionDefinition,
+			"COMPLETION":         ActionCompletion,
+			"HOVER":              ActionHover,
+			"DEFINITION":         ActionDefinition,
+			"ON_TYPE_FORMATTING": ActionOnTypeFormatting,
+			"SIGNATURE_HELP":     ActionSignatureHelp,
+			"DOCUMENT_HIGHLIGHT": ActionDocumentHighlight,
 		}[step.Name], Position: p}, nil
-	case "CODE_ACTION":
+	case "CODE_ACTION", "RANGE_FORMATTING", "INLAY_HINT":
 		start, err := pos(0)
 		if err != nil {
 			return Action{}, err
@@ -120,7 +146,11 @@ func actionFrom(step *snap.Step) (Action, error) {
 			return Action{}, err
 		}
 		r := lsp.Range{Start: *start, End: *end}
-		return Action{Type: ActionCodeAction, Range: &r}, nil
+		return Action{Type: map[string]ActionType{
+			"CODE_ACTION":      ActionCodeAction,
+			"RANGE_FORMATTING": ActionRangeFormatting,
+			"INLAY_HINT":       ActionInlayHint,
+		}[step.Name], Range: &r}, nil
 	case "REFERENCES":
 		if len(step.Args) == 0 {
 			return Action{}, fmt.Errorf("REFERENCES needs a position")
@@ -136,6 +166,19 @@ func actionFrom(step *snap.Step) (Action, error) {
 			}
 		}
 		return Action{Type: ActionReferences, Position: p, IncludeDeclaration: includeDecl}, nil
+	case "SELECTION_RANGE":
+		if len(step.Args) == 0 {
+			return Action{}, fmt.Errorf("SELECTION_RANGE needs a position")
+		}
+		var positions []lsp.Pos
+		for i := range step.Args {
+			p, err := pos(i)
+			if err != nil {
+				return Action{}, err
+			}
+			positions = append(positions, *p)
+		}
+		return Action{Type: ActionSelectionRange, Positions: positions}, nil
 	case "RENAME":
 		p, err := pos(0)
 		if err != nil {
@@ -13,7 +13,15 @@ import (
 
 func TestMain(m *testing.M) {
 	log.L = zap.NewNop().Sugar()
-	os.Exit(m.Run())
+	// Keep the developer's own check rules out of test diagnostics.
+	home, err := os.MkdirTemp("", "radls-home")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("RAD_HOME", home)
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
 }
 
 func TestSnapshots(t *testing.T) {
@@ -52,8 +52,11 @@ func NewServerWithDebounce(r io.Reader, w io.Writer, delay time.Duration) *Serve
 	}
 
 	m.AddRequestHandler(lsp.INITIALIZE, server.handleInitialize)
+	m.AddNotificationHandler(lsp.INITIALIZED, server.handleInitialized)
 	m.AddNotificationHandler(lsp.TD_DID_OPEN, server.handleDidOpen)
 	m.AddNotificationHandler(lsp.TD_DID_CHANGE, server.handleDidChange)
+	m.AddNotificationHandler(lsp.TD_DID_CLOSE, server.handleDidClose)
+	m.AddNotificationHandler(lsp.WS_DID_CHANGE_WATCHED, server.handleDidChangeWatchedFiles)
 	m.AddRequestHandler(lsp.TD_COMPLETION, server.handleCompletion)
 	m.AddRequestHandler(lsp.TD_CODE_ACTION, server.handleCodeAction)
 	m.AddRequestHandler(lsp.TD_HOVER, server.handleHover)
@@ -62,6 +65,15 @@ func NewServerWithDebounce(r io.Reader, w io.Writer, delay time.Duration) *Serve
 	m.AddRequestHandler(lsp.TD_REFERENCES, server.handleReferences)
 	m.AddRequestHandler(lsp.TD_SEMANTIC_TOKENS, server.handleSemanticTokens)
 	m.AddRequestHandler(lsp.TD_RENAME, server.handleRename)
+	m.AddRequestHandler(lsp.TD_FORMATTING, server.handleFormatting)
+	m.AddRequestHandler(lsp.TD_RANGE_FORMATTING, server.handleRangeFormatting)
+	m.AddRequestHandler(lsp.TD_ON_TYPE_FORMATTING, server.handleOnTypeFormatting)
+	m.AddRequestHandler(lsp.TD_SIGNATURE_HELP, server.handleSignatureHelp)
+	m.AddRequestHandler(lsp.TD_INLAY_HINT, server.handleInlayHint)
+	m.AddRequestHandler(lsp.TD_FOLDING_RANGE, server.handleFoldingRange)
+	m.AddRequestHandler(lsp.TD_SELECTION_RANGE, server.handleSelectionRange)
+	m.AddRequestHandler(lsp.TD_DOCUMENT_HIGHLIGHT, server.handleDocumentHighlight)
+	m.AddRequestHandler(lsp.WS_SYMBOL, server.handleWorkspaceSymbol)
 
 	return &server
 }
@@ -88,10 +100,29 @@ func (s *Server) handleInitialize(_ context.Context, params json.RawMessage) (re
 	s.s.SetEncoding(enc)
 	log.L.Infof("Negotiated position encoding: %s (client offered %v)", enc, offered)
 
+	// Clients that predate workspace folders send a single root instead.
+	var folders []string
+	for _, f := range initParams.WorkspaceFolders {
+		folders = append(folders, f.Uri)
+	}
+	if len(folders) == 0 && initParams.RootUri != "" {
+		folders = append(folders, initParams.RootUri)
+	}
+	s.s.SetWorkspaceFolders(folders)
+
 	result = lsp.NewInitializeResult(string(enc), analysis.SemanticTokensLegend())
 	return
 }
 
+// handleInitialized kicks off the workspace scan. It runs in the
+// background so the first didOpen isn't stuck behind parsing every script
+// in the repo; until it finishes, workspace-wide answers just cover fewer
+// files.
+func (s *Server) handleInitialized(_ context.Context, _ json.RawMessage) (err error) {
+	go s.s.IndexWorkspace()
+	return
+}
+
 func (s *Server) handleDidOpen(_ context.Context, params json.RawMessage) (err error) {
 	var didOpenParams lsp.DidOpenTextDocumentParams
 	if err = json.Unmarshal(params, &didOpenParams); err != nil {
@@ -131,6 +162,77 @@ func (s *Server) handleDidChange(_ context.Context, params json.RawMessage) (err
 	return
 }
 
+func (s *Server) handleDidClose(_ context.Context, params json.RawMessage) (err error) {
+	var didCloseParams lsp.DidCloseTextDocumentParams
+	if err = json.Unmarshal(params, &didCloseParams); err != nil {
+		return
+	}
+	uri := didCloseParams.TextDocument.Uri
+	s.s.CloseDoc(uri)
+	// Nothing republishes a closed file's diagnostics, so clear them
+	// rather than leave stale ones in the client's problems list. A
+	// debounced publish still pending finds no snapshot and does nothing.
+	s.notifyDiagnostics(uri, []lsp.Diagnostic{})
+	return
+}
+
+func (s *Server) handleDidChangeWatchedFiles(_ context.Context, params json.RawMessage) (err error) {
+	var watchedParams lsp.DidChangeWatchedFilesParams
+	if err = json.Unmarshal(params, &watchedParams); err != nil {
+		return
+	}
+	s.s.FilesChanged(watchedParams.Changes)
+	return
+}
+
+func (s *Server) handleFoldingRange(_ context.Context, params json.RawMessage) (result any, err error) {
+	var foldingParams lsp.FoldingRangeParams
+	if err = json.Unmarshal(params, &foldingParams); err != nil {
+		return
+	}
+	snap := s.s.Snapshot(foldingParams.TextDocument.Uri)
+	if snap != nil {
+		defer snap.Release()
+	}
+	result, err = s.s.FoldingRanges(snap)
+	return
+}
+
+func (s *Server) handleSelectionRange(_ context.Context, params json.RawMessage) (result any, err error) {
+	var selectionParams lsp.SelectionRangeParams
+	if err = json.Unmarshal(params, &selectionParams); err != nil {
+		return
+	}
+	snap := s.s.Snapshot(selectionParams.TextDocument.Uri)
+	if snap != nil {
+		defer snap.Release()
+	}
+	result, err = s.s.SelectionRanges(snap, selectionParams.Positions)
+	return
+}
+
+func (s *Server) handleDocumentHighlight(_ context.Context, params json.RawMessage) (result any, err error) {
+	var highlightParams lsp.DocumentHighlightParams
+	if err = json.Unmarshal(params, &highlightParams); err != nil {
+		return
+	}
+	snap := s.s.Snapshot(highlightParams.TextDocument.Uri)
+	if snap != nil {
+		defer snap.Release()
+	}
+	result, err = s.s.DocumentHighlights(snap, highlightParams.Position)
+	return
+}
+
+func (s *Server) handleWorkspaceSymbol(_ context.Context, params json.RawMessage) (result any, err error) {
+	var symbolParams lsp.WorkspaceSymbolParams
+	if err = json.Unmarshal(params, &symbolParams); err != nil {
+		return
+	}
+	result = s.s.WorkspaceSymbols(symbolParams.Query)
+	return
+}
+
 func (s *Server) handleCompletion(_ context.Context, params json.RawMessage) (result any, err error) {
 	var completionParams lsp.CompletionParams
 	if err = json.Unmarshal(params, &completionParams); err != nil {
@@ -235,6 +337,73 @@ func (s *Server) handleRename(_ context.Context, params json.RawMessage) (result
 	return
 }
 
+func (s *Server) handleFormatting(_ context.Context, params json.RawMessage) (result any, err error) {
+	var fp lsp.DocumentFormattingParams
+	if err = json.Unmarshal(params, &fp); err != nil {
+		return
+	}
+	snap := s.s.Snapshot(fp.TextDocument.Uri)
+	if snap != nil {
+		defer snap.Release()
+	}
+	result, err = s.s.Format(snap)
+	return
+}
+
+func (s *Server) handleRangeFormatting(_ context.Context, params json.RawMessage) (result any, err error) {
+	var fp lsp.DocumentRangeFormattingParams
+	if err = json.Unmarshal(params, &fp); err != nil {
+		return
+	}
+	snap := s.s.Snapshot(fp.TextDocument.Uri)
+	if snap != nil {
+		defer snap.Release()
+	}
+	result, err = s.s.FormatRange(snap, fp.Range)
+	return
+}
+
+func (s *Server) handleOnTypeFormatting(_ context.Context, params json.RawMessage) (result any, err error) {
+	var fp lsp.DocumentOnTypeFormattingParams
+	if err = json.Unmarshal(params, &fp); err != nil {
+		return
+	}
+	snap := s.s.Snapshot(fp.TextDocument.Uri)
+	if snap != nil {
+		defer snap.Release()
+	}
+	result, err = s.s.FormatOnType(snap, fp.Position, fp.Ch)
+	return
+}
+
+func (s *Server) handleSignatureHelp(_ context.Context, params json.RawMessage) (result any, err error) {
+	var shParams lsp.SignatureHelpParams
+	if err = json.Unmarshal(params, &shParams); err != nil {
+		return
+	}
+	snap := s.s.Snapshot(shParams.TextDocument.Uri)
+	if snap != nil {
+		defer snap.Release()
+	}
+	// nil encodes as null: "not in a call", so the client closes any
+	// open signature popup.
+	result, err = s.s.SignatureHelp(snap, shParams.Position)
+	return
+}
+
+func (s *Server) handleInlayHint(_ context.Context, params json.RawMessage) (result any, err error) {
+	var ihParams lsp.InlayHintParams
+	if err = json.Unmarshal(params, &ihParams); err != nil {
+		return
+	}
+	snap := s.s.Snapshot(ihParams.TextDocument.Uri)
+	if snap != nil {
+		defer snap.Release()
+	}
+	result, err = s.s.InlayHints(snap, ihParams.Range)
+	return
+}
+
 func (s *Server) handleSemanticTokens(_ context.Context, params json.RawMessage) (result any, err error) {
 	var stParams lsp.SemanticTokensParams
 	if err = json.Unmarshal(params, &stParams); err != nil {
@@ -1,6 +1,7 @@
 package check
 
 import (
+	"regexp"
 	"strconv"
 
 	"github.com/amterp/rad/rts"
@@ -32,6 +33,9 @@ func Resolve(file *rl.SourceFile) *Resolved {
 	return b.resolved
 }
 
+// importFuncName is the builtin whose literal-path calls the binder follows.
+const importFuncName = "import"
+
 // binder carries the mutable state required during a single resolution
 // pass. It is not safe for concurrent use; the public Resolve function
 // constructs a fresh one per call.
@@ -53,6 +57,8 @@ func newBinder() *binder {
 			Decls:        map[rl.Node]*Symbol{},
 			ForLoopVars:  map[*rl.ForLoop][]*Symbol{},
 			ParamSymbols: map[rl.Node][]*Symbol{},
+			Imports:      map[*Symbol]*Import{},
+			ModuleRefs:   map[*rl.Identifier]*ModuleRef{},
 		},
 		current:  builtinScope,
 		builtins: rts.GetBuiltInFunctions(),
@@ -309,6 +315,8 @@ func (b *binder) visit(n rl.Node) {
 		b.visitShell(v)
 	case *rl.RadBlock:
 		b.visitRadBlock(v)
+	case *rl.VarPath:
+		b.visitVarPath(v)
 	default:
 		// Generic descent for unhandled node kinds. Subsequent commits
 		// in this phase replace more of these with scope-aware cases
@@ -387,11 +395,82 @@ func (b *binder) visitAssign(a *rl.Assign) {
 			}
 		}
 	}
+	b.recordImport(a)
 	if a.Catch != nil {
 		b.visitCatch(a.Catch)
 	}
 }
 
+// recordImport notes `name = import(path)`, so calls through name are
+// known to go into a module. Only a plain literal path can be followed
+// there; a computed one is recorded with no path. Shadowing `import`
+// itself leaves the binding an ordinary local.
+func (b *binder) recordImport(a *rl.Assign) {
+	if len(a.Targets) != 1 || len(a.Values) != 1 {
+		return
+	}
+	target, ok := a.Targets[0].(*rl.Identifier)
+	if !ok {
+		return
+	}
+	call, ok := a.Values[0].(*rl.Call)
+	if !ok || len(call.Args) != 1 {
+		return
+	}
+	callee, ok := call.Func.(*rl.Identifier)
+	if !ok || callee.Name != importFuncName {
+		return
+	}
+	if sym := b.resolved.Uses[callee]; sym == nil || sym.Kind != SymBuiltin {
+		return
+	}
+	imp := &Import{Span: call.Args[0].Span()}
+	if path, ok := call.Args[0].(*rl.LitString); ok && path.Simple {
+		imp.Path = path.Value
+	}
+	if sym := b.resolved.Uses[target]; sym != nil {
+		b.resolved.Imports[sym] = imp
+	}
+}
+
+// visitVarPath resolves a path like any other expression, except for
+// a call through an import's namespace: `helpers.greet(x)` calls the
+// module's greet, so `greet` isn't looked up here at all - it would
+// otherwise be flagged undefined, or worse, bound to a builtin of the
+// same name.
+func (b *binder) visitVarPath(v *rl.VarPath) {
+	b.visit(v.Root)
+	var module *Symbol
+	if root, ok := v.Root.(*rl.Identifier); ok {
+		if sym := b.resolved.Uses[root]; sym != nil && b.resolved.Imports[sym] != nil {
+			module = sym
+		}
+	}
+	for idx, seg := range v.Segments {
+		if idx == 0 && module != nil && seg.IsUFCS {
+			if call, ok := seg.Index.(*rl.Call); ok {
+				if name, ok := call.Func.(*rl.Identifier); ok {
+					b.resolved.ModuleRefs[name] = &ModuleRef{
+						Module: module,
+						Import: b.resolved.Imports[module],
+						Name:   name.Name,
+					}
+					for _, arg := range call.Args {
+						b.visit(arg)
+					}
+					for _, na := range call.NamedArgs {
+						b.visit(na.Value)
+					}
+					continue
+				}
+			}
+		}
+		b.visit(seg.Index)
+		b.visit(seg.Start)
+		b.visit(seg.End)
+	}
+}
+
 // visitRadBlock walks a rad block, treating field-name identifiers
 // as declarations rather than references. Field names come from the
 // data source (e.g. CSV columns, JSON keys) and aren't variables in
@@ -695,6 +774,11 @@ func (b *binder) visitListComp(c *rl.ListComp) {
 func (b *binder) visitSwitch(s *rl.Switch) {
 	b.visit(s.Discriminant)
 	for _, c := range s.Cases {
+		// Names a case's patterns bind are declared before its keys are
+		// visited, so a where() guard in the same case can read them.
+		for _, k := range c.Keys {
+			b.declarePatternBindings(k)
+		}
 		for _, k := range c.Keys {
 			b.visit(k)
 		}
@@ -705,6 +789,45 @@ func (b *binder) visitSwitch(s *rl.Switch) {
 	}
 }
 
+// declarePatternBindings declares the variables a switch-case pattern
+// assigns when it matches: the names given to bind() and rest(), and the
+// named groups of a regex(). Only literal names are seen; the runtime binds
+// computed ones too, but they're opaque here. Lambdas are skipped - a guard
+// reads bindings, it doesn't make them.
+func (b *binder) declarePatternBindings(n rl.Node) {
+	if _, isLambda := n.(*rl.Lambda); isLambda {
+		return
+	}
+	if call, ok := n.(*rl.Call); ok && len(call.Args) > 0 {
+		if ident, ok := call.Func.(*rl.Identifier); ok && b.isUnshadowedBuiltin(ident.Name) {
+			if lit, ok := call.Args[0].(*rl.LitString); ok && lit.Simple {
+				switch ident.Name {
+				case "bind", "rest":
+					b.declare(lit.Value, SymLocal, lit.Span(), lit)
+				case "regex":
+					if re, err := regexp.Compile(lit.Value); err == nil {
+						for _, name := range re.SubexpNames() {
+							if name != "" {
+								b.declare(name, SymLocal, lit.Span(), nil)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	for _, child := range n.Children() {
+		b.declarePatternBindings(child)
+	}
+}
+
+func (b *binder) isUnshadowedBuiltin(name string) bool {
+	if sym := b.current.Lookup(name); sym != nil {
+		return sym.Kind == SymBuiltin
+	}
+	return b.builtins != nil && b.builtins.Contains(name)
+}
+
 // visitSwitchAlt visits one case's right-hand side. The case-block
 // form just walks its statements in the enclosing scope.
 func (b *binder) visitSwitchAlt(alt rl.Node) {
@@ -354,6 +354,31 @@ func TestResolve_SwitchDiscriminantInEnclosingScope(t *testing.T) {
 	assert.Same(t, fileX, r.Uses[discIdent])
 }
 
+func TestResolve_SwitchPatternBindingsDeclared(t *testing.T) {
+	// bind(), rest() and regex() named groups assign variables when their
+	// case matches, so the case body (and a where() guard in the same
+	// case) can read them without an undefined-identifier error.
+	src := `v = [1, 2, 3]
+switch v:
+    case [bind("first"), rest("others")]:
+        print(first, others)
+    case where(bind("n"), fn() n > 0):
+        print(n)
+    case regex("(?P<user>\\w+)@(?P<host>\\w+)"):
+        print(user, host)
+`
+	file := parseFile(t, src)
+	r := check.Resolve(file)
+	require.NotNil(t, r)
+
+	for _, name := range []string{"first", "others", "n", "user", "host"} {
+		assert.NotNil(t, r.File.Lookup(name), "expected %q to be declared", name)
+	}
+	for _, issue := range r.Issues {
+		assert.NotEqual(t, rl.ErrUndefinedVariable, issue.Code, issue.Message)
+	}
+}
+
 func TestResolve_DeferBodySharesEnclosingScope(t *testing.T) {
 	// A defer body runs later in the enclosing function's env (the
 	// interpreter uses runBlock, not a child env), so any local it
@@ -530,3 +555,43 @@ func TestResolve_RebindingDoesNotCreateNewSymbol(t *testing.T) {
 	firstAssign := file.Stmts[0].(*rl.Assign)
 	assert.Same(t, sym, r.Decls[firstAssign.Targets[0]])
 }
+
+func TestResolve_ImportNamespaceCallsAreModuleRefs(t *testing.T) {
+	// `h.len(x)` on an import's namespace calls the module's len, not
+	// the builtin: the callee must be a module ref and never a use.
+	src := "h = import(\"./helpers\")\nh.len(1)\nh.greet()\n"
+	file := parseFile(t, src)
+	r := check.Resolve(file)
+	require.NotNil(t, r)
+	assert.Empty(t, r.Issues)
+
+	sym := r.File.Lookup("h")
+	require.NotNil(t, sym)
+	imp := r.Imports[sym]
+	require.NotNil(t, imp)
+	assert.Equal(t, "./helpers", imp.Path)
+
+	for idx, name := range []string{"len", "greet"} {
+		call := file.Stmts[idx+1].(*rl.ExprStmt).Expr.(*rl.VarPath).Segments[0].Index.(*rl.Call)
+		ident := call.Func.(*rl.Identifier)
+		ref := r.ModuleRefs[ident]
+		require.NotNil(t, ref, name)
+		assert.Equal(t, name, ref.Name)
+		assert.Same(t, sym, ref.Module)
+		assert.NotContains(t, r.Uses, ident)
+	}
+}
+
+func TestResolve_ComputedImportHasNoPath(t *testing.T) {
+	// A computed path can't be followed, but calls through the
+	// namespace still aren't resolved here - greet isn't undefined.
+	src := "p = \"./helpers\"\nh = import(p)\nh.greet()\n"
+	r := check.Resolve(parseFile(t, src))
+	require.NotNil(t, r)
+	assert.Empty(t, r.Issues)
+
+	imp := r.Imports[r.File.Lookup("h")]
+	require.NotNil(t, imp)
+	assert.Equal(t, "", imp.Path)
+	assert.Len(t, r.ModuleRefs, 1)
+}
@@ -13,6 +13,7 @@ type RadChecker interface {
 	UpdateSrc(src string)
 	Update(tree *rts.RadTree, src string, ast *rl.SourceFile)
 	SetStrict(strict bool)
+	SetRules(rules Rules)
 	Check() (Result, error)
 }
 
@@ -24,6 +25,9 @@ type RadCheckerImpl struct {
 	// strict opts into advisory diagnostics that are suppressed by default
 	// (see strictOnlyCodes). Off unless the caller flips it on.
 	strict bool
+	// rules are the user's per-code levels (see Rules.apply). Nil unless
+	// the caller loaded some config.
+	rules Rules
 }
 
 func NewChecker() (RadChecker, error) {
@@ -75,6 +79,12 @@ func (c *RadCheckerImpl) SetStrict(strict bool) {
 	c.strict = strict
 }
 
+// SetRules configures per-code levels. A strict-only code configured at any
+// level other than off is surfaced without --strict.
+func (c *RadCheckerImpl) SetRules(rules Rules) {
+	c.rules = rules
+}
+
 func (c *RadCheckerImpl) Check() (Result, error) {
 	diagnostics := make([]Diagnostic, 0)
 	c.addInvalidNodes(&diagnostics)
@@ -106,6 +116,11 @@ func (c *RadCheckerImpl) Check() (Result, error) {
 	c.addConstantInterpolationWarnings(&diagnostics)
 	c.addMisleadingShellCaptureNameWarnings(&diagnostics)
 	c.addShellExprAccessorErrors(&diagnostics)
+
+	// Suppressions run before rules so a comment silencing a code that's
+	// configured off still counts as used.
+	diagnostics = c.applySuppressions(diagnostics)
+	diagnostics = c.rules.apply(diagnostics)
 	return Result{
 		Diagnostics: diagnostics,
 		Resolved:    resolved,
@@ -132,9 +147,10 @@ func (c *RadCheckerImpl) addTypeIssues(info *TypeInfo, d *[]Diagnostic) {
 	}
 	for _, issue := range info.Issues {
 		if strictOnlyCodes[issue.Code] {
-			// Suppressed by default; surfaced only under --strict, and then
-			// as a warning rather than its emit-time severity.
-			if !c.strict {
+			// Suppressed by default; surfaced only under --strict (or when
+			// configured on), and then as a warning rather than its
+			// emit-time severity.
+			if !c.strict && !c.rules.enables(issue.Code) {
 				continue
 			}
 			issue.Severity = IssueWarning
@@ -0,0 +1,317 @@
+package check
+
+import (
+	"strings"
+
+	"github.com/amterp/rad/rts/rl"
+	ts "github.com/tree-sitter/go-tree-sitter"
+)
+
+// TokenClass is what a stretch of source is, for the purpose of colouring it.
+// radls sends the first four to editors as semantic tokens, leaving the rest
+// to the editor's own grammar; the REPL has no grammar of its own, so it
+// colours all of them.
+type TokenClass int
+
+const (
+	TokenFunction TokenClass = iota
+	TokenParameter
+	TokenVariable
+	TokenType
+	TokenKeyword
+	TokenString
+	TokenNumber
+	TokenComment
+)
+
+// Token is one classified stretch of source. Positions are bytes, and the
+// start row/column is carried because LSP clients want it and can't cheaply
+// recover it.
+type Token struct {
+	StartByte int
+	EndByte   int
+	StartRow  int
+	StartCol  int
+	Class     TokenClass
+}
+
+// SemanticTokens tags each identifier with its kind (function vs parameter vs
+// variable), plus the keywords of type annotations. It's the part of
+// colouring that needs the resolver: tree-sitter alone can't tell a builtin
+// call from a local-variable read. Three sources of identifiers:
+//
+//  1. Identifier nodes that resolve through resolved.Uses. This
+//     catches call sites, var reads, and the dual-registered
+//     decl identifier on `x = 1`.
+//  2. FnDef name positions. The binder declares hoisted fns at
+//     the AST node (not at an Identifier), so the name token
+//     at `fn greet():` has no Uses entry; without this branch
+//     it would not be coloured at the decl site even though
+//     every call to `greet()` is.
+//  3. ArgDecl name positions inside the `args:` / cmd `args:`
+//     blocks. Same shape as FnDef: the args declaration sits in
+//     the AST as an ArgDecl node rather than an Identifier, so
+//     the decl-site name needs its own emit to match how every
+//     use of the arg is tagged via path 1.
+//
+// Fn param-name positions at the decl site are still uncoloured.
+// The AST's TypingFnParam carries no per-name span; emitting
+// would need a converter+AST extension. Param references inside
+// the body do get tokens via path 1.
+//
+// Unresolved identifiers are skipped - emitting "variable" for them
+// would colour typos as if they were real bindings.
+func SemanticTokens(ast *rl.SourceFile, resolved *Resolved, root *ts.Node) []Token {
+	tokens := make([]Token, 0)
+	if ast != nil && resolved != nil {
+		rl.Walk(ast, func(n rl.Node) {
+			switch nn := n.(type) {
+			case *rl.Identifier:
+				sym, ok := resolved.Uses[nn]
+				if !ok || sym == nil {
+					return
+				}
+				class, has := ClassifySymbol(sym)
+				if !has {
+					return
+				}
+				tokens = append(tokens, tokenFromSpan(nn.Span(), class))
+			case *rl.FnDef:
+				// Skip anonymous or zero-name forms; NameSpan would be
+				// the whole node and emitting that would over-paint.
+				if nn.Name == "" {
+					return
+				}
+				tokens = append(tokens, tokenFromSpan(nn.NameSpan, TokenFunction))
+			case *rl.ArgDecl:
+				if nn.Name == "" || nn.NameSpan.EndByte == 0 {
+					return
+				}
+				tokens = append(tokens, tokenFromSpan(nn.NameSpan, TokenParameter))
+			}
+		})
+	}
+	if root != nil {
+		collectTypeTokens(root, &tokens)
+	}
+	return tokens
+}
+
+// ClassifySymbol maps a SymbolKind to its token class, or false when the
+// symbol shouldn't get a token of its own. Builtins and hoisted user
+// functions share the function class - editors don't render them distinctly
+// today, and a separate "builtin" class would lock us in before we have UX
+// feedback.
+func ClassifySymbol(sym *Symbol) (TokenClass, bool) {
+	switch sym.Kind {
+	case SymBuiltin, SymHoistedFn:
+		return TokenFunction, true
+	case SymParam, SymArg, SymCmdArg:
+		// Args / cmd-args are declared parameters of the script
+		// (the script's caller fills them in via CLI flags), so
+		// they belong with fn params under the `parameter` class
+		// rather than the generic `variable`.
+		return TokenParameter, true
+	case SymLocal, SymLoopVar, SymWith:
+		return TokenVariable, true
+	}
+	return 0, false
+}
+
+// LexicalTokens classifies what the grammar alone can: keywords, literals and
+// comments. It's what an editor's tree-sitter highlighting would do, for
+// callers that have no editor doing it for them. Identifiers are left to
+// SemanticTokens and type annotations to its type pass, so the two sets never
+// overlap.
+func LexicalTokens(root *ts.Node) []Token {
+	tokens := make([]Token, 0)
+	if root != nil {
+		collectLexicalTokens(root, &tokens)
+	}
+	return tokens
+}
+
+func collectLexicalTokens(n *ts.Node, out *[]Token) {
+	kind := n.Kind()
+	switch {
+	case kind == rl.K_COMMENT, kind == rl.K_SHEBANG:
+		*out = append(*out, tokenFromNode(n, TokenComment))
+		return
+	case kind == rl.K_INT, kind == rl.K_FLOAT, kind == rl.K_SCIENTIFIC_NUMBER:
+		*out = append(*out, tokenFromNode(n, TokenNumber))
+		return
+	case kind == rl.K_BOOL, kind == rl.K_NULL:
+		*out = append(*out, tokenFromNode(n, TokenKeyword))
+		return
+	case kind == rl.K_STRING:
+		collectStringTokens(n, out)
+		return
+	case strings.HasSuffix(kind, "_type"):
+		// annotations belong to the type pass
+		return
+	case !n.IsNamed() && isKeywordKind(kind):
+		*out = append(*out, tokenFromNode(n, TokenKeyword))
+		return
+	}
+
+	count := n.ChildCount()
+	for i := uint(0); i < count; i++ {
+		collectLexicalTokens(n.Child(i), out)
+	}
+}
+
+// collectStringTokens colours a string literal around its interpolations,
+// whose expressions are code and are classified like any other.
+func collectStringTokens(n *ts.Node, out *[]Token) {
+	start := n.StartByte()
+	startPos := n.StartPosition()
+	emit := func(end uint) {
+		if end > start {
+			*out = append(*out, Token{
+				StartByte: int(start),
+				EndByte:   int(end),
+				StartRow:  int(startPos.Row),
+				StartCol:  int(startPos.Column),
+				Class:     TokenString,
+			})
+		}
+	}
+
+	// The interpolations sit among the literal parts under the contents
+	// field, not directly under the string.
+	contents := rl.GetChild(n, rl.F_CONTENTS)
+	var count uint
+	if contents != nil {
+		count = contents.ChildCount()
+	}
+	for i := uint(0); i < count; i++ {
+		child := contents.Child(i)
+		if child.Kind() != rl.K_INTERPOLATION {
+			continue
+		}
+		emit(child.StartByte())
+		collectLexicalTokens(child, out)
+		start = child.EndByte()
+		startPos = child.EndPosition()
+	}
+	emit(n.EndByte())
+}
+
+// isKeywordKind reports whether an anonymous node is a keyword rather than
+// punctuation. tree-sitter names a keyword token by its own text, so a word
+// is a keyword and anything else is an operator or bracket.
+func isKeywordKind(kind string) bool {
+	if len(kind) < 2 {
+		return false
+	}
+	for _, r := range kind {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// typeKeywordLengths maps a CST node kind to the byte length of the
+// leading keyword that should carry the type class. For nodes whose
+// entire span IS the keyword (string_type = "str") the length is the
+// whole node. For composite forms (`int[]`) we only want the leading
+// word, not the brackets.
+var typeKeywordLengths = map[string]int{
+	"string_type":      -1, // whole span ("str")
+	"int_type":         -1, // ("int")
+	"float_type":       -1, // ("float")
+	"bool_type":        -1, // ("bool")
+	"error_type":       -1, // ("error")
+	"any_type":         -1, // ("any")
+	"void_type":        -1, // ("void")
+	"string_list_type": 3,  // "str" prefix of "str[]"
+	"int_list_type":    3,  // "int" prefix of "int[]"
+	"float_list_type":  5,  // "float" prefix
+	"bool_list_type":   4,  // "bool" prefix
+}
+
+// collectTypeTokens walks the CST and emits a type token for every
+// type-annotation keyword. The CST is the right level for this: the
+// AST's TypingT shapes carry no source positions, but the CST has
+// byte-exact spans on each `string_type` / `int_type` / etc. node. We
+// deliberately limit emission to the keyword bytes (not the whole
+// composite) so the surrounding punctuation - `[]`, `?`, `|`, `->` -
+// stays uncoloured.
+//
+// For the bare `list` and `fn` keywords (inside the parameterised
+// list_type / fn_type rules), we recognise the first child token's
+// literal text and emit on that. Tuple / enum forms inside
+// list_type ("[T1, T2]", "[\"a\", \"b\"]") have no keyword and
+// we skip those.
+func collectTypeTokens(root *ts.Node, out *[]Token) {
+	walkCSTNodes(root, func(n *ts.Node) {
+		kind := n.Kind()
+		if length, ok := typeKeywordLengths[kind]; ok {
+			emitTypeToken(n, length, out)
+			return
+		}
+		switch kind {
+		case "list_type":
+			// list_type covers `list` (the open-list keyword) and
+			// the bracketed tuple / enum forms. Only the bare-
+			// `list` shape has a keyword to paint - detect via
+			// the literal first 4 bytes.
+			if n.EndByte()-n.StartByte() >= 4 {
+				emitTypeToken(n, 4, out)
+			}
+		case "fn_type":
+			// fn_type starts with the literal `fn` token. Emit
+			// just the first 2 bytes.
+			if n.EndByte()-n.StartByte() >= 2 {
+				emitTypeToken(n, 2, out)
+			}
+		}
+	})
+}
+
+func emitTypeToken(n *ts.Node, length int, out *[]Token) {
+	token := tokenFromNode(n, TokenType)
+	span := token.EndByte - token.StartByte
+	if length < 0 || length > span {
+		length = span
+	}
+	if length == 0 {
+		return
+	}
+	token.EndByte = token.StartByte + length
+	*out = append(*out, token)
+}
+
+func walkCSTNodes(n *ts.Node, visit func(*ts.Node)) {
+	if n == nil {
+		return
+	}
+	visit(n)
+	count := n.ChildCount()
+	for i := uint(0); i < count; i++ {
+		child := n.Child(i)
+		walkCSTNodes(child, visit)
+	}
+}
+
+func tokenFromNode(n *ts.Node, class TokenClass) Token {
+	start := n.StartPosition()
+	return Token{
+		StartByte: int(n.StartByte()),
+		EndByte:   int(n.EndByte()),
+		StartRow:  int(start.Row),
+		StartCol:  int(start.Column),
+		Class:     class,
+	}
+}
+
+func tokenFromSpan(s rl.Span, class TokenClass) Token {
+	return Token{
+		StartByte: s.StartByte,
+		EndByte:   s.EndByte,
+		StartRow:  s.StartRow,
+		StartCol:  s.StartCol,
+		Class:     class,
+	}
+}
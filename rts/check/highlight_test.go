@@ -0,0 +1,110 @@
+package check_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/check"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tokenClassNames = map[check.TokenClass]string{
+	check.TokenFunction:  "function",
+	check.TokenParameter: "parameter",
+	check.TokenVariable:  "variable",
+	check.TokenType:      "type",
+	check.TokenKeyword:   "keyword",
+	check.TokenString:    "string",
+	check.TokenNumber:    "number",
+	check.TokenComment:   "comment",
+}
+
+// classifyTokens runs both classifications over src.
+func classifyTokens(t *testing.T, src string) (lexical, semantic []check.Token) {
+	t.Helper()
+
+	parser, err := rts.NewRadParser()
+	require.NoError(t, err)
+	defer parser.Close()
+	tree := parser.Parse(src)
+	defer tree.Close()
+
+	root := tree.Root()
+	file := safeConvertCST(root, src)
+	require.NotNil(t, file)
+	return check.LexicalTokens(root), check.SemanticTokens(file, check.Resolve(file), root)
+}
+
+// highlightTokens renders each token of both classifications as
+// "text=class", in source order.
+func highlightTokens(t *testing.T, src string) (lexical, semantic []string) {
+	t.Helper()
+	render := func(tokens []check.Token) []string {
+		sort.SliceStable(tokens, func(i, j int) bool { return tokens[i].StartByte < tokens[j].StartByte })
+		out := []string{}
+		for _, token := range tokens {
+			out = append(out, src[token.StartByte:token.EndByte]+"="+tokenClassNames[token.Class])
+		}
+		return out
+	}
+	lexicalTokens, semanticTokens := classifyTokens(t, src)
+	return render(lexicalTokens), render(semanticTokens)
+}
+
+func TestLexicalTokens_KeywordsLiteralsAndComments(t *testing.T) {
+	src := "// note\nif true:\n    n = 42\n"
+	lexical, _ := highlightTokens(t, src)
+	assert.Equal(t, []string{"// note=comment", "if=keyword", "true=keyword", "42=number"}, lexical)
+}
+
+func TestLexicalTokens_StringSplitsAroundInterpolation(t *testing.T) {
+	src := "n = 4\ns = \"a{n + 1}b\"\n"
+	lexical, _ := highlightTokens(t, src)
+	assert.Equal(t, []string{"4=number", `"a=string`, "1=number", `b"=string`}, lexical)
+}
+
+func TestSemanticTokens_ClassifiesResolvedIdentifiers(t *testing.T) {
+	src := `fn greet(name: str) -> str:
+    return "hi {name}"
+
+msg = greet("bob")
+print(msg)
+`
+	_, semantic := highlightTokens(t, src)
+	assert.Equal(t, []string{
+		"greet=function",
+		"str=type",
+		"str=type",
+		"name=parameter",
+		"msg=variable",
+		"greet=function",
+		"print=function",
+		"msg=variable",
+	}, semantic)
+}
+
+func TestSemanticTokens_SkipUnresolvedNames(t *testing.T) {
+	_, semantic := highlightTokens(t, "print(nope)\n")
+	assert.Equal(t, []string{"print=function"}, semantic)
+}
+
+func TestTokens_NeverOverlap(t *testing.T) {
+	src := `fn add(a: int, b: int) -> int:
+    // sum them
+    return a + b
+
+total = add(1, 2)
+print("total {total}")
+`
+	lexical, semantic := classifyTokens(t, src)
+	assert.NotEmpty(t, lexical)
+	assert.NotEmpty(t, semantic)
+	for _, l := range lexical {
+		for _, s := range semantic {
+			overlap := l.StartByte < s.EndByte && s.StartByte < l.EndByte
+			assert.False(t, overlap, "%q overlaps %q", src[l.StartByte:l.EndByte], src[s.StartByte:s.EndByte])
+		}
+	}
+}
@@ -751,7 +751,7 @@ func (tc *typeChecker) typeOfSymbol(sym *Symbol, frame *Frame) rl.TypingT {
 // caller turns that into a Never truthy branch.
 func validTypeOfTarget(s string) bool {
 	switch s {
-	case "int", "str", "float", "bool", "list", "map", "null", "error", "function":
+	case "int", "str", "float", "bool", "list", "map", "null", "error", "function", "datetime", "duration", "set", "iterator":
 		return true
 	}
 	return false
@@ -796,6 +796,18 @@ func matchesTypeOf(t rl.TypingT, target string) bool {
 	case "function":
 		_, ok := t.(*rl.TypingFnT)
 		return ok
+	case "datetime":
+		_, ok := t.(*rl.TypingDatetimeT)
+		return ok
+	case "duration":
+		_, ok := t.(*rl.TypingDurationT)
+		return ok
+	case "set":
+		_, ok := t.(*rl.TypingSetT)
+		return ok
+	case "iterator":
+		_, ok := t.(*rl.TypingIterT)
+		return ok
 	case "null":
 		_, ok := t.(*rl.TypingNullT)
 		return ok
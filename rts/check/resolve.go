@@ -127,6 +127,14 @@ type Resolved struct {
 	// be in hand; this index lets LSP click-at-decl-site features
 	// reach the symbol without re-walking scope chains.
 	ParamSymbols map[rl.Node][]*Symbol
+	// Imports maps a symbol bound by `name = import(path)` to the
+	// import.
+	Imports map[*Symbol]*Import
+	// ModuleRefs maps the function-name identifier of a call through
+	// an import's namespace (`greet` in `helpers.greet(x)`) to the
+	// module function it names. These identifiers are absent from Uses:
+	// they name something in another file, not in this one's scopes.
+	ModuleRefs map[*rl.Identifier]*ModuleRef
 	// Issues are problems the binder detected during resolution
 	// (undefined references, duplicate parameters, etc.). Callers
 	// convert these to whatever diagnostic shape they need; the binder
@@ -134,6 +142,23 @@ type Resolved struct {
 	Issues []BindIssue
 }
 
+// Import is an `import(path)` bound to a name. Path is as written, or
+// empty when it's computed rather than a plain literal - such a module
+// can't be followed without running the script. Resolving Path to a
+// file (rts.ResolveImport) needs the importing file's location, which
+// the binder doesn't know.
+type Import struct {
+	Path string
+	Span rl.Span // span of the path argument
+}
+
+// ModuleRef is a call to a function of an imported module.
+type ModuleRef struct {
+	Module *Symbol // the namespace the call goes through
+	Import *Import
+	Name   string
+}
+
 // IssueSeverity is the binder's severity classification. Kept here
 // rather than on Diagnostic so resolve.go stays src-free.
 //
@@ -0,0 +1,139 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ProjectRulesFile configures checks for every script at or below the
+// directory it sits in. It's meant to be committed, so a project's CI, its
+// developers and their editors check with the same rules.
+const ProjectRulesFile = ".radcheck.toml"
+
+// RulesConfig is the [check] section of the rad config. A project's
+// .radcheck.toml has the same shape at its top level and overrides it.
+type RulesConfig struct {
+	Rules map[string]string `toml:"rules"` // code -> off, hint, info, warning or error
+}
+
+// RuleWarner reports a config entry that was ignored, naming the file it's in.
+type RuleWarner func(configPath string, format string, args ...interface{})
+
+// RuleLoader resolves the rule levels applied to a script: the user's, from
+// the rad config, overridden per code by the nearest .radcheck.toml above the
+// script. Both `rad check` and radls go through it, so a code configured off
+// is off in the editor too. Files are parsed once per loader, so a bulk check
+// reads - and warns about - each one only once.
+type RuleLoader struct {
+	user     Rules
+	warn     RuleWarner
+	projects map[string]Rules
+}
+
+// NewRuleLoader makes a loader over the user's configured rules, as read from
+// the rad config at userPath.
+func NewRuleLoader(user map[string]string, userPath string, warn RuleWarner) *RuleLoader {
+	l := &RuleLoader{warn: warn, projects: make(map[string]Rules)}
+	l.user = l.parse(user, userPath)
+	return l
+}
+
+// ReadUserRules reads the rules in the [check] section of the rad config at
+// path. A missing file configures nothing.
+func ReadUserRules(path string) (map[string]string, error) {
+	var config struct {
+		Check *RulesConfig `toml:"check"`
+	}
+	if _, err := toml.DecodeFile(path, &config); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if config.Check == nil {
+		return nil, nil
+	}
+	return config.Check.Rules, nil
+}
+
+// RulesFor returns the rules for the script at scriptPath. A script with no
+// path, e.g. an unsaved editor buffer, only gets the user's rules.
+func (l *RuleLoader) RulesFor(scriptPath string) Rules {
+	rules := make(Rules, len(l.user))
+	for code, level := range l.user {
+		rules[code] = level
+	}
+	if scriptPath == "" {
+		return rules
+	}
+	if path, ok := findProjectRulesFile(scriptPath); ok {
+		for code, level := range l.project(path) {
+			rules[code] = level
+		}
+	}
+	return rules
+}
+
+func (l *RuleLoader) project(path string) Rules {
+	if rules, ok := l.projects[path]; ok {
+		return rules
+	}
+	var config RulesConfig
+	var rules Rules
+	if meta, err := toml.DecodeFile(path, &config); err != nil {
+		l.warn(path, "Ignoring check config; failed to parse: %v\n", err)
+	} else if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		l.warn(path, "Ignoring check config; unknown key %q\n", undecoded[0].String())
+	} else {
+		rules = l.parse(config.Rules, path)
+	}
+	l.projects[path] = rules
+	return rules
+}
+
+// findProjectRulesFile walks up from the script's directory to the nearest
+// .radcheck.toml.
+func findProjectRulesFile(scriptPath string) (string, bool) {
+	abs, err := filepath.Abs(scriptPath)
+	if err != nil {
+		return "", false
+	}
+	for dir := filepath.Dir(abs); ; {
+		candidate := filepath.Join(dir, ProjectRulesFile)
+		if stat, err := os.Stat(candidate); err == nil && stat.Mode().IsRegular() {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// parse converts configured code -> level strings, warning about and
+// skipping any entry that doesn't parse rather than rejecting the file.
+func (l *RuleLoader) parse(raw map[string]string, configPath string) Rules {
+	codes := make([]string, 0, len(raw))
+	for code := range raw {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	rules := make(Rules, len(raw))
+	for _, key := range codes {
+		code, err := ParseRuleCode(key)
+		if err == nil {
+			var level RuleLevel
+			if level, err = ParseRuleLevel(raw[key]); err == nil {
+				rules[code] = level
+				continue
+			}
+		}
+		l.warn(configPath, "Ignoring check rule %s: %v\n", key, err)
+	}
+	return rules
+}
@@ -0,0 +1,97 @@
+package check
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+// RuleLevel is what a user has configured for one diagnostic code.
+// RuleDefault leaves the code as the checker emits it.
+type RuleLevel int
+
+const (
+	RuleDefault RuleLevel = iota
+	RuleOff
+	RuleHint
+	RuleInfo
+	RuleWarning
+	RuleError
+)
+
+// Rules maps diagnostic codes to their configured level. A nil Rules is
+// valid and configures nothing.
+type Rules map[rl.Error]RuleLevel
+
+var ruleCodePattern = regexp.MustCompile(`^(?i:RAD)?(\d{5})$`)
+
+// ParseRuleCode accepts a code as users write it - `RAD30011` as printed,
+// or bare `30011` - and returns the rl.Error it names.
+func ParseRuleCode(s string) (rl.Error, error) {
+	m := ruleCodePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", fmt.Errorf("invalid rule code %q, expected e.g. RAD30011", s)
+	}
+	return rl.Error(m[1]), nil
+}
+
+// ParseRuleLevel parses a configured level: off, hint, info, warning or error.
+func ParseRuleLevel(s string) (RuleLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "off":
+		return RuleOff, nil
+	case "hint":
+		return RuleHint, nil
+	case "info":
+		return RuleInfo, nil
+	case "warning", "warn":
+		return RuleWarning, nil
+	case "error":
+		return RuleError, nil
+	default:
+		return RuleDefault, fmt.Errorf("invalid rule level %q, expected one of off, hint, info, warning, error", s)
+	}
+}
+
+// enables reports whether code is configured on at some level, which is
+// what opts a strict-only code in without --strict.
+func (r Rules) enables(code rl.Error) bool {
+	level := r[code]
+	return level != RuleDefault && level != RuleOff
+}
+
+// apply rewrites diagnostics per the configured levels: RuleOff drops a
+// diagnostic, any other level replaces its severity.
+//
+// Errors are left alone. An error-level diagnostic means the script is
+// rejected or will fail at runtime, and configuring the checker quiet
+// doesn't change that - a rule can be raised to error, never lowered
+// from it.
+func (r Rules) apply(diags []Diagnostic) []Diagnostic {
+	if len(r) == 0 {
+		return diags
+	}
+	out := diags[:0]
+	for _, d := range diags {
+		if d.Code == nil || d.Severity == Error {
+			out = append(out, d)
+			continue
+		}
+		switch r[*d.Code] {
+		case RuleOff:
+			continue
+		case RuleHint:
+			d.Severity = Hint
+		case RuleInfo:
+			d.Severity = Info
+		case RuleWarning:
+			d.Severity = Warning
+		case RuleError:
+			d.Severity = Error
+		}
+		out = append(out, d)
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/amterp/rad/rts/check"
+	"github.com/amterp/rad/rts/rl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRuleCode(t *testing.T) {
+	for _, in := range []string{"RAD40017", "rad40017", "40017", " RAD40017 "} {
+		code, err := check.ParseRuleCode(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, rl.ErrConstantInterpolation, code, in)
+	}
+	for _, in := range []string{"", "RAD4001", "RAD400170", "W40017"} {
+		_, err := check.ParseRuleCode(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParseRuleLevel(t *testing.T) {
+	cases := map[string]check.RuleLevel{
+		"off":     check.RuleOff,
+		"hint":    check.RuleHint,
+		"info":    check.RuleInfo,
+		"warning": check.RuleWarning,
+		"warn":    check.RuleWarning,
+		"Error":   check.RuleError,
+	}
+	for in, want := range cases {
+		level, err := check.ParseRuleLevel(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, level, in)
+	}
+	_, err := check.ParseRuleLevel("loud")
+	assert.Error(t, err)
+}
+
+func TestRules_OffDropsDiagnostic(t *testing.T) {
+	rules := check.Rules{rl.ErrConstantInterpolation: check.RuleOff}
+	assert.Empty(t, checkWith(t, "print(\"{4}\")\n", false, rules))
+}
+
+func TestRules_LevelReplacesSeverity(t *testing.T) {
+	rules := check.Rules{rl.ErrConstantInterpolation: check.RuleError}
+	diags := checkWith(t, "print(\"{4}\")\n", false, rules)
+	require.Len(t, diags, 1)
+	assert.Equal(t, check.Error, diags[0].Severity)
+}
+
+func TestRules_ErrorsNeverLowered(t *testing.T) {
+	rules := check.Rules{rl.ErrReturnOutsideFunction: check.RuleOff}
+	diags := checkWith(t, "return 1\n", false, rules)
+	require.Len(t, diags, 1)
+	assert.Equal(t, check.Error, diags[0].Severity)
+}
+
+func TestRules_ConfiguredStrictOnlyCodeFiresWithoutStrict(t *testing.T) {
+	src := "a = parse_int(\"2\")\nprint(a)\n"
+	diags := checkWith(t, src, false, check.Rules{rl.ErrUnhandledFallibleCall: check.RuleHint})
+	require.Len(t, diags, 1)
+	assert.Equal(t, rl.ErrUnhandledFallibleCall, *diags[0].Code)
+	assert.Equal(t, check.Hint, diags[0].Severity)
+
+	assert.Empty(t, checkWith(t, src, true, check.Rules{rl.ErrUnhandledFallibleCall: check.RuleOff}))
+}
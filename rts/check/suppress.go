@@ -0,0 +1,174 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amterp/rad/rts/rl"
+	ts "github.com/tree-sitter/go-tree-sitter"
+)
+
+// suppressDirective is the comment prefix that silences named codes:
+//
+//	x = parse_int(s)  // rad-ignore RAD30011
+//
+//	// rad-ignore RAD30011, RAD40017 - validated upstream
+//	fn load(s):
+//	    ...
+//
+// A trailing comment covers its own line. A comment alone on its line
+// covers the whole statement after it, block and all. Anything after the
+// codes is free text, so the reason can live next to the suppression.
+const suppressDirective = "rad-ignore"
+
+// suppression is one code named by one rad-ignore comment.
+type suppression struct {
+	comment   *ts.Node
+	code      rl.Error // empty when the comment names no code at all
+	startLine int
+	endLine   int
+	used      bool
+}
+
+func (s *suppression) covers(d Diagnostic) bool {
+	return d.Code != nil && *d.Code == s.code &&
+		d.Range.Start.Line >= s.startLine && d.Range.Start.Line <= s.endLine
+}
+
+// applySuppressions drops diagnostics covered by a rad-ignore comment and
+// appends a RAD40027 for each suppression that covered nothing.
+//
+// Errors can't be suppressed, for the same reason Rules won't lower them.
+// A suppression whose code is off in this run - configured off, or a
+// strict-only code without --strict - isn't reported as unused: it may
+// well be earning its keep in another run, like CI's `--strict`.
+func (c *RadCheckerImpl) applySuppressions(diags []Diagnostic) []Diagnostic {
+	sups := c.findSuppressions()
+	if len(sups) == 0 {
+		return diags
+	}
+
+	out := diags[:0]
+	for _, d := range diags {
+		suppressed := false
+		if d.Severity != Error {
+			for _, s := range sups {
+				if s.covers(d) {
+					s.used = true
+					suppressed = true
+				}
+			}
+		}
+		if !suppressed {
+			out = append(out, d)
+		}
+	}
+
+	for _, s := range sups {
+		if s.used || (s.code != "" && !c.ruleActive(s.code)) {
+			continue
+		}
+		out = append(out, c.unusedSuppression(s))
+	}
+	return out
+}
+
+// ruleActive reports whether code can fire in this run at all.
+func (c *RadCheckerImpl) ruleActive(code rl.Error) bool {
+	if c.rules[code] == RuleOff {
+		return false
+	}
+	return !strictOnlyCodes[code] || c.strict || c.rules.enables(code)
+}
+
+func (c *RadCheckerImpl) unusedSuppression(s *suppression) Diagnostic {
+	code := rl.ErrUnusedSuppression
+	if s.code == "" {
+		return NewDiagnosticWarn(s.comment, c.src, "rad-ignore names no rule code, so it suppresses nothing", code).
+			WithSuggestion("Name the code to suppress, e.g. `// rad-ignore RAD30011`")
+	}
+	msg := fmt.Sprintf("Suppression of %s matched no diagnostic", s.code)
+	return NewDiagnosticWarn(s.comment, c.src, msg, code).
+		WithSuggestion(fmt.Sprintf("Remove %s from the comment", s.code))
+}
+
+// findSuppressions parses every rad-ignore comment in the tree into one
+// suppression per named code.
+func (c *RadCheckerImpl) findSuppressions() []*suppression {
+	var sups []*suppression
+	for _, comment := range c.tree.FindNodes(rl.K_COMMENT) {
+		codes, ok := parseSuppression(c.src[comment.StartByte():comment.EndByte()])
+		if !ok {
+			continue
+		}
+		start, end := c.suppressionScope(comment)
+		if len(codes) == 0 {
+			sups = append(sups, &suppression{comment: comment, startLine: start, endLine: end})
+			continue
+		}
+		for _, code := range codes {
+			sups = append(sups, &suppression{comment: comment, code: code, startLine: start, endLine: end})
+		}
+	}
+	return sups
+}
+
+// parseSuppression reads the codes out of a `// rad-ignore ...` comment.
+// ok is false for any other comment. Codes may be separated by commas or
+// spaces; the first word that isn't a code starts the free-text reason.
+func parseSuppression(text string) (codes []rl.Error, ok bool) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(strings.TrimPrefix(text, "//")), suppressDirective)
+	if !found || (rest != "" && rest[0] != ' ' && rest[0] != '\t' && rest[0] != ',') {
+		return nil, false
+	}
+	fields := strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	for _, f := range fields {
+		code, err := ParseRuleCode(f)
+		if err != nil {
+			break
+		}
+		codes = append(codes, code)
+	}
+	return codes, true
+}
+
+// suppressionScope is the inclusive line range a rad-ignore comment
+// covers. Trailing code: its own line. Alone on its line: the next
+// statement, found as the outermost node starting where that statement's
+// line does, so an `if` or `fn` brings its whole body along.
+func (c *RadCheckerImpl) suppressionScope(comment *ts.Node) (int, int) {
+	row := int(comment.StartPosition().Row)
+	lines := strings.Split(c.src, "\n")
+	if col := int(comment.StartPosition().Column); strings.TrimSpace(lines[row][:col]) != "" {
+		return row, row
+	}
+
+	for next := row + 1; next < len(lines); next++ {
+		trimmed := strings.TrimSpace(lines[next])
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		col := len(lines[next]) - len(strings.TrimLeft(lines[next], " \t"))
+		pt := ts.Point{Row: uint(next), Column: uint(col)}
+		node := c.tree.Root().DescendantForPointRange(pt, pt)
+		if node == nil {
+			return next, next
+		}
+		for {
+			parent := node.Parent()
+			if parent == nil || parent.Kind() == rl.K_SOURCE_FILE || parent.StartByte() != node.StartByte() {
+				break
+			}
+			node = parent
+		}
+		end := node.EndPosition()
+		endLine := int(end.Row)
+		if end.Column == 0 && endLine > next {
+			// Blocks end at column 0 of the line after their last
+			// statement; that line belongs to whatever comes next.
+			endLine--
+		}
+		return next, endLine
+	}
+	return row, row
+}
@@ -0,0 +1,107 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/amterp/rad/rts"
+	"github.com/amterp/rad/rts/check"
+	"github.com/amterp/rad/rts/rl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkWith runs the full Check() pipeline with the given strictness and
+// rules. Suppression and rule levels are applied at the end of Check, so
+// nothing short of the whole pipeline exercises them.
+func checkWith(t *testing.T, src string, strict bool, rules check.Rules) []check.Diagnostic {
+	t.Helper()
+
+	parser, err := rts.NewRadParser()
+	require.NoError(t, err)
+	defer parser.Close()
+
+	tree := parser.Parse(src)
+	file := safeConvertCST(tree.Root(), src)
+	checker := check.NewCheckerWithTree(tree, parser, src, file)
+	checker.SetStrict(strict)
+	checker.SetRules(rules)
+	result, err := checker.Check()
+	require.NoError(t, err)
+	return result.Diagnostics
+}
+
+// linesWithCode returns the 0-based start line of every diagnostic with code.
+func linesWithCode(diags []check.Diagnostic, code rl.Error) []int {
+	lines := []int{}
+	for _, d := range diags {
+		if d.Code != nil && *d.Code == code {
+			lines = append(lines, d.Range.Start.Line)
+		}
+	}
+	return lines
+}
+
+func TestSuppress_TrailingCommentCoversItsLine(t *testing.T) {
+	src := "print(\"{4}\")  // rad-ignore RAD40017\nprint(\"{4}\")\n"
+	diags := checkWith(t, src, false, nil)
+	assert.Equal(t, []int{1}, linesWithCode(diags, rl.ErrConstantInterpolation))
+	assert.Empty(t, linesWithCode(diags, rl.ErrUnusedSuppression))
+}
+
+func TestSuppress_OwnLineCommentCoversNextStatementBlock(t *testing.T) {
+	src := `// rad-ignore RAD40017 - literal on purpose
+fn show():
+    print("{4}")
+    print("{5}")
+
+print("{6}")
+`
+	diags := checkWith(t, src, false, nil)
+	assert.Equal(t, []int{5}, linesWithCode(diags, rl.ErrConstantInterpolation))
+	assert.Empty(t, linesWithCode(diags, rl.ErrUnusedSuppression))
+}
+
+func TestSuppress_EachCodeTrackedSeparately(t *testing.T) {
+	src := "print(\"{4}\")  // rad-ignore RAD40007,RAD40017\n"
+	diags := checkWith(t, src, false, nil)
+	assert.Empty(t, linesWithCode(diags, rl.ErrConstantInterpolation))
+	require.Len(t, diags, 1)
+	assert.Equal(t, rl.ErrUnusedSuppression, *diags[0].Code)
+	assert.Contains(t, diags[0].Message, "RAD40007")
+}
+
+func TestSuppress_UnusedIsReported(t *testing.T) {
+	diags := checkWith(t, "print(\"hi\")  // rad-ignore RAD40017\n", false, nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, rl.ErrUnusedSuppression, *diags[0].Code)
+	assert.Equal(t, check.Warning, diags[0].Severity)
+	assert.Equal(t, 0, diags[0].Range.Start.Line)
+}
+
+func TestSuppress_NoCodeIsReported(t *testing.T) {
+	diags := checkWith(t, "print(\"hi\")  // rad-ignore\n", false, nil)
+	assert.Equal(t, []int{0}, linesWithCode(diags, rl.ErrUnusedSuppression))
+}
+
+func TestSuppress_OtherCommentsIgnored(t *testing.T) {
+	diags := checkWith(t, "print(\"hi\")  // rad-ignored RAD40017\n", false, nil)
+	assert.Empty(t, diags)
+}
+
+func TestSuppress_InactiveCodeNotReportedUnused(t *testing.T) {
+	src := "a = parse_int(\"2\")  // rad-ignore RAD30011\nprint(a)\n"
+
+	// Without --strict RAD30011 can't fire, so the comment isn't "unused";
+	// it's waiting for the strict run.
+	assert.Empty(t, checkWith(t, src, false, nil))
+	// Under --strict it fires and is suppressed.
+	assert.Empty(t, checkWith(t, src, true, nil))
+	// A code configured off is just as inactive.
+	assert.Empty(t, checkWith(t, "print(1)  // rad-ignore RAD40017\n", false, check.Rules{rl.ErrConstantInterpolation: check.RuleOff}))
+}
+
+func TestSuppress_ErrorsCannotBeSuppressed(t *testing.T) {
+	diags := checkWith(t, "return 1  // rad-ignore RAD40004\n", false, nil)
+	assert.Equal(t, []int{0}, linesWithCode(diags, rl.ErrReturnOutsideFunction))
+	assert.Equal(t, []int{0}, linesWithCode(diags, rl.ErrUnusedSuppression))
+}
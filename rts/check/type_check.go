@@ -2,6 +2,7 @@ package check
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -120,6 +121,13 @@ type typeChecker struct {
 	// fn / lambda entry (save & swap), restored on exit. Empty
 	// map = no reassignments to worry about.
 	reassignedInScope map[*Symbol][]rl.Span
+	// inGenerator is set while walking a generator's body, where a
+	// `yield` hands a value to the consumer and carries on, rather
+	// than ending the block. Each yielded value is recorded in the
+	// return frame, so a declared return type checks what's yielded.
+	// Set on fn / lambda entry (save & swap) and cleared inside
+	// switch case blocks, whose yields give the case's value.
+	inGenerator bool
 	// guardedCalls marks every *rl.Call whose error-panic is caught by
 	// an enclosing guard (`??`, a `catch` expression, or a statement-
 	// level catch). Populated by markGuardedCalls before any synth, so
@@ -516,7 +524,9 @@ func tarjanSCC(nodes []*rl.FnDef, deps map[*rl.FnDef]map[*rl.FnDef]struct{}) [][
 // users.
 func (tc *typeChecker) processFnSCC(scc []*rl.FnDef, fnSym map[*rl.FnDef]*Symbol) {
 	// Step 1: plant placeholder return for every fn in the SCC
-	// that doesn't already have a declared return. Fns with
+	// that doesn't already have a declared return. Generators are
+	// known up front - their calls give an iterator whatever they
+	// yield - so they're planted final and skip inference. Fns with
 	// declared returns keep their declaration; the body walk
 	// uses it as `expected` and emits return-mismatch diagnostics.
 	placeholder := rl.NewNeverType()
@@ -526,6 +536,10 @@ func (tc *typeChecker) processFnSCC(scc []*rl.FnDef, fnSym map[*rl.FnDef]*Symbol
 		if sym == nil {
 			continue
 		}
+		if isGenerator(fn.IsBlock, fn.Body) {
+			tc.info.SymbolTypes[sym] = generatorFnType(fn.Name, fn.Typing)
+			continue
+		}
 		if fn.Typing == nil || fn.Typing.ReturnT == nil {
 			needsInference[fn] = true
 			// Replace SymbolTypes with a TypingFnT carrying the
@@ -566,6 +580,10 @@ func (tc *typeChecker) processFnSCC(scc []*rl.FnDef, fnSym map[*rl.FnDef]*Symbol
 	//   - Anything else: that's the union of concrete returns.
 	var all []rl.TypingT
 	for _, fn := range scc {
+		if isGenerator(fn.IsBlock, fn.Body) {
+			// what it yields, not what its calls give
+			continue
+		}
 		all = append(all, collected[fn]...)
 	}
 	var scope rl.TypingT
@@ -751,13 +769,37 @@ func (tc *typeChecker) walkFnDefForInference(n *rl.FnDef) []rl.TypingT {
 	tc.pushReturnFrame(declaredReturn)
 	prevReassigned := tc.reassignedInScope
 	tc.reassignedInScope = tc.scanReassignments(n.Body)
+	prevInGenerator := tc.inGenerator
+	tc.inGenerator = isGenerator(n.IsBlock, n.Body)
 	tc.walkStmts(n.Body)
+	tc.inGenerator = prevInGenerator
 	tc.reassignedInScope = prevReassigned
 	collected := tc.popReturnFrame()
 	tc.frame = saved
 	return collected
 }
 
+// isGenerator reports whether a fn or lambda body makes it a generator,
+// mirroring the runtime: a block body with a `yield` of its own.
+func isGenerator(isBlock bool, body []rl.Node) bool {
+	return isBlock && rl.IsGeneratorBody(body)
+}
+
+// generatorFnType is the signature of a generator: its params, and an
+// iterator return.
+func generatorFnType(name string, typing *rl.TypingFnT) *rl.TypingFnT {
+	params := []rl.TypingFnParam(nil)
+	if typing != nil {
+		params = typing.Params
+	}
+	var ret rl.TypingT = rl.NewIterType()
+	return &rl.TypingFnT{
+		FnName:  name,
+		Params:  params,
+		ReturnT: &ret,
+	}
+}
+
 // walkStmt dispatches on statement kind. Phase 2a recognizes only the
 // shapes it can do something with; everything else is descended-into
 // so identifier-uses still get their types recorded (for hover).
@@ -802,6 +844,18 @@ func (tc *typeChecker) walkStmt(n rl.Node) {
 			t = rl.NewTupleType(elems...)
 		}
 		tc.recordReturn(t, v, v.Values)
+	case *rl.Yield:
+		if !tc.inGenerator {
+			for _, child := range v.Children() {
+				tc.walkStmt(child)
+			}
+			return
+		}
+		// A generator yields one value at a time; it's checked like a
+		// return against the declared type.
+		for _, val := range v.Values {
+			tc.recordReturn(tc.synth(val), val, []rl.Node{val})
+		}
 	default:
 		// Generic descent. Later sub-commits replace these with
 		// kind-specific handlers (for loops, switch, return, etc.).
@@ -951,11 +1005,23 @@ func (tc *typeChecker) walkFnDef(n *rl.FnDef) {
 	// defined inside see the right enclosing-reassignments set.
 	prevReassigned := tc.reassignedInScope
 	tc.reassignedInScope = tc.scanReassignments(n.Body)
+	prevInGenerator := tc.inGenerator
+	tc.inGenerator = isGenerator(n.IsBlock, n.Body)
 	tc.walkStmts(n.Body)
+	tc.inGenerator = prevInGenerator
 	tc.reassignedInScope = prevReassigned
 	collected := tc.popReturnFrame()
 	tc.frame = saved
 
+	// A generator's calls give an iterator, declared return or not -
+	// the declaration types what it yields.
+	if isGenerator(n.IsBlock, n.Body) {
+		if sym, ok := tc.resolved.Decls[n]; ok && sym != nil {
+			tc.info.SymbolTypes[sym] = generatorFnType(n.Name, n.Typing)
+		}
+		return
+	}
+
 	// Inference for nested fns. Top-level fns are handled in the
 	// SCC pre-pass (inferHoistedFnReturns) which gets to walk
 	// bodies in dependency order; nested fns just get walked here
@@ -1341,6 +1407,10 @@ func (tc *typeChecker) walkSwitch(n *rl.Switch) {
 	// can never fire regardless of what other arms exist.
 	domainCheck := domainCheckerFor(discType, n.Discriminant)
 
+	// Set once a case matches with of_type(); the types no case takes are
+	// then worth a hint even on an open discriminant like a union.
+	sawTypePattern := false
+
 	for _, c := range n.Cases {
 		for _, k := range c.Keys {
 			key, ok := caseLiteralKey(k)
@@ -1360,6 +1430,10 @@ func (tc *typeChecker) walkSwitch(n *rl.Switch) {
 			seenKeys[key] = k.Span()
 		}
 		caseType := tc.matchTypeForCaseKeys(c.Keys)
+		if matched, _, ok := tc.typePatternCase(c.Keys, residual); ok {
+			caseType = matched
+			sawTypePattern = true
+		}
 		var branchFrame *Frame
 		if discSym != nil && caseType != nil && !isErrorType(caseType) {
 			branchFrame = initial.With(discSym, caseType)
@@ -1371,7 +1445,7 @@ func (tc *typeChecker) walkSwitch(n *rl.Switch) {
 		if !exitsEarly {
 			branchFrames = append(branchFrames, tc.frame)
 		}
-		residual = subtractEnumType(residual, caseType)
+		residual = tc.peelCase(residual, c.Keys)
 	}
 
 	if n.Default != nil {
@@ -1393,6 +1467,8 @@ func (tc *typeChecker) walkSwitch(n *rl.Switch) {
 		// value, and surfacing it statically is the whole point.
 		if !isNeverType(residual) && isClosedDiscriminant(discType) {
 			tc.emitNonExhaustiveSwitch(n, residual)
+		} else if sawTypePattern && residual != nil && !isNeverType(residual) {
+			tc.emitUnmatchedTypes(n, residual)
 		}
 		var fallFrame *Frame
 		if discSym != nil && residual != nil {
@@ -1416,6 +1492,9 @@ func (tc *typeChecker) walkSwitch(n *rl.Switch) {
 func (tc *typeChecker) walkSwitchAlt(alt rl.Node) bool {
 	switch a := alt.(type) {
 	case *rl.SwitchCaseBlock:
+		prevInGenerator := tc.inGenerator
+		tc.inGenerator = false
+		defer func() { tc.inGenerator = prevInGenerator }()
 		tc.walkStmts(a.Stmts)
 		return tc.branchExits(a.Stmts)
 	case *rl.SwitchCaseExpr:
@@ -1477,6 +1556,74 @@ func subtractEnumType(base, caseType rl.TypingT) rl.TypingT {
 	return rl.NewStrEnumType(remaining...)
 }
 
+// peelCase removes what a case's keys match from the discriminant's
+// residual type: string-enum values for literal keys, whole types for
+// of_type() patterns.
+func (tc *typeChecker) peelCase(residual rl.TypingT, keys []rl.Node) rl.TypingT {
+	if _, rest, ok := tc.typePatternCase(keys, residual); ok {
+		return rest
+	}
+	return subtractEnumType(residual, tc.matchTypeForCaseKeys(keys))
+}
+
+// typePatternCase narrows base by a case whose every key is a type
+// pattern - `of_type("int")`, or one wrapped in `bind("n", ...)` - naming
+// a type_of() target. Returns the type the discriminant takes in the case
+// and what's left for later cases, both as narrowByTypeOf splits them.
+// Guards, ranges and the like match only part of a type, so a case using
+// one isn't a type pattern and peels nothing.
+func (tc *typeChecker) typePatternCase(keys []rl.Node, base rl.TypingT) (matched, rest rl.TypingT, ok bool) {
+	if len(keys) == 0 || base == nil {
+		return nil, nil, false
+	}
+	var arms []rl.TypingT
+	rest = base
+	for _, k := range keys {
+		target, isType := tc.ofTypeTarget(k)
+		if !isType {
+			return nil, nil, false
+		}
+		truthy, falsy := narrowByTypeOf(rest, target)
+		if truthy == nil || falsy == nil {
+			// No static handle on the discriminant (any, dynamic).
+			return nil, nil, false
+		}
+		if !isNeverType(truthy) {
+			arms = append(arms, truthy)
+		}
+		rest = falsy
+	}
+	matched = joinNarrowArms(arms)
+	if matched == nil {
+		matched = rl.NewNeverType()
+	}
+	return matched, rest, true
+}
+
+// ofTypeTarget returns T for a case key `of_type("T")` or
+// `bind(name, of_type("T"))`, when T is something type_of() returns.
+func (tc *typeChecker) ofTypeTarget(n rl.Node) (string, bool) {
+	call, ok := n.(*rl.Call)
+	if !ok {
+		return "", false
+	}
+	ident, ok := call.Func.(*rl.Identifier)
+	if !ok {
+		return "", false
+	}
+	if sym, ok := tc.resolved.Uses[ident]; !ok || sym == nil || sym.Kind != SymBuiltin {
+		return "", false
+	}
+	switch {
+	case ident.Name == "of_type" && len(call.Args) == 1:
+		target, ok := simpleStringValue(call.Args[0])
+		return target, ok && validTypeOfTarget(target)
+	case ident.Name == "bind" && len(call.Args) == 2:
+		return tc.ofTypeTarget(call.Args[1])
+	}
+	return "", false
+}
+
 // isClosedDiscriminant reports whether a discriminant'\”s static type
 // is a closed set the checker can exhaustively analyze. Today: just
 // string-enums. Bool would be a near-term extension (true / false
@@ -1667,6 +1814,19 @@ func (tc *typeChecker) emitNonExhaustiveSwitch(n *rl.Switch, residual rl.TypingT
 	})
 }
 
+// emitUnmatchedTypes hints at the types a switch's of_type() cases leave
+// unmatched. Only a hint: other cases (a guard, a range) may well cover the
+// rest in ways the analyzer can't see, and a value that no case takes is a
+// runtime error the script may already be expecting.
+func (tc *typeChecker) emitUnmatchedTypes(n *rl.Switch, residual rl.TypingT) {
+	tc.info.Issues = append(tc.info.Issues, BindIssue{
+		Span:     n.Span(),
+		Severity: IssueHint,
+		Code:     rl.ErrNonExhaustiveSwitch,
+		Message:  fmt.Sprintf("Switch has no case for %s; add one or a default", rl.DisplayName(residual)),
+	})
+}
+
 // branchExitsEarly is a deep "does this body always diverge?" check
 // suitable for if/switch branch joins. Phase 4e'\”s "last statement
 // is a return/break/continue" version was too shallow: a common
@@ -1705,7 +1865,7 @@ func (tc *typeChecker) emitNonExhaustiveSwitch(n *rl.Switch, residual rl.TypingT
 // switch exhaustiveness) that the standalone helper can'\”t reach.
 // Callers from walkIf / walkSwitch use this method form.
 func (tc *typeChecker) branchExits(body []rl.Node) bool {
-	return bodyDiverges(body, divergeCtx{insideLoop: true}, tc)
+	return bodyDiverges(body, divergeCtx{insideLoop: true, yieldResumes: tc.inGenerator}, tc)
 }
 
 // divergeCtx threads context through the recursive divergence walk.
@@ -1713,6 +1873,9 @@ func (tc *typeChecker) branchExits(body []rl.Node) bool {
 // return-type) can fit alongside without rewiring the call sites.
 type divergeCtx struct {
 	insideLoop bool
+	// yieldResumes is set in a generator's body, where a yield
+	// carries on to the next statement.
+	yieldResumes bool
 }
 
 // bodyDiverges is the entry point: ANY divergent statement in the
@@ -1736,8 +1899,10 @@ func stmtDiverges(n rl.Node, ctx divergeCtx, tc *typeChecker) bool {
 		return false
 	}
 	switch s := n.(type) {
-	case *rl.Return, *rl.Yield:
+	case *rl.Return:
 		return true
+	case *rl.Yield:
+		return !ctx.yieldResumes
 	case *rl.Break, *rl.Continue:
 		return ctx.insideLoop
 	case *rl.ExprStmt:
@@ -1809,8 +1974,7 @@ func switchDiverges(n *rl.Switch, ctx divergeCtx, tc *typeChecker) bool {
 	}
 	residual := discType
 	for _, c := range n.Cases {
-		caseType := tc.matchTypeForCaseKeys(c.Keys)
-		residual = subtractEnumType(residual, caseType)
+		residual = tc.peelCase(residual, c.Keys)
 	}
 	return isNeverType(residual)
 }
@@ -1820,6 +1984,8 @@ func switchDiverges(n *rl.Switch, ctx divergeCtx, tc *typeChecker) bool {
 func altDiverges(alt rl.Node, ctx divergeCtx, tc *typeChecker) bool {
 	switch a := alt.(type) {
 	case *rl.SwitchCaseBlock:
+		// a yield in a case block gives the case's value
+		ctx.yieldResumes = false
 		return bodyDiverges(a.Stmts, ctx, tc)
 	case *rl.SwitchCaseExpr:
 		return false
@@ -2948,6 +3114,53 @@ func (tc *typeChecker) refineBuiltinReturn(
 		return refineReadFile(call, typing, ret)
 	case "index_of":
 		return tc.refineIndexOf(call, recv, ret)
+	case "record":
+		return tc.refineRecord(call, ret)
+	case "parse_json", "parse_toml":
+		return tc.refineParseInto(call, false, ret)
+	case "parse_yaml":
+		// all=true parses every document, so the record is per element
+		all := false
+		for _, na := range call.NamedArgs {
+			if na.Name != "all" {
+				continue
+			}
+			lit, ok := na.Value.(*rl.LitBool)
+			if !ok {
+				return ret
+			}
+			all = lit.Value
+		}
+		return tc.refineParseInto(call, all, ret)
+	case "parse_ndjson":
+		return tc.refineParseInto(call, true, ret)
+	case "find", "find_all":
+		return refineFind(call, ident.Name, ret)
+	case "datetime":
+		// Reading the clock in the local zone can't fail.
+		if len(call.Args) == 0 && len(call.NamedArgs) == 0 {
+			return rl.NewDatetimeType()
+		}
+		return rl.NewUnionType(rl.NewDatetimeType(), rl.NewErrorType())
+	case "in_tz":
+		return rl.NewUnionType(rl.NewDatetimeType(), rl.NewErrorType())
+	case "start_of":
+		return rl.NewDatetimeType()
+	case "duration":
+		return rl.NewUnionType(rl.NewDurationType(), rl.NewErrorType())
+	case "set", "intersect":
+		return rl.NewSetType()
+	case "read_lines":
+		return rl.NewUnionType(rl.NewIterType(), rl.NewErrorType())
+	case "take":
+		// a list keeps its element type; an iterator's are unknown
+		if len(args) > 0 {
+			switch args[0].(type) {
+			case *rl.TypingListT, *rl.TypingAnyListT:
+				return args[0]
+			}
+		}
+		return rl.NewAnyListType()
 	case "clamp", "min", "max", "abs":
 		// These select/transform among numeric inputs without changing
 		// int-ness, so all-int scalar args produce an int result. The
@@ -3062,12 +3275,12 @@ func refineSameShape(args []rl.TypingT, ret rl.TypingT) rl.TypingT {
 	return ret
 }
 
-// refineMap types `map`, whose signature can only say `map|list` because the
+// refineMap types `map`, whose signature can only say `any` because the
 // result kind follows the collection it was handed. A list maps to a list of
 // the callee's return type; a map maps to a map with its keys preserved and
-// its values replaced. When the callee's return isn't known (an unannotated
-// named fn, a dynamic value) we still pin the *kind*, which is what stops the
-// `map|list` union leaking into a `list`-typed parameter.
+// its values replaced; an iterator maps lazily to another iterator. When the
+// callee's return isn't known (an unannotated named fn, a dynamic value) we
+// still pin the *kind*, so the result checks like any other list or map.
 func refineMap(args []rl.TypingT, ret rl.TypingT) rl.TypingT {
 	if len(args) != 2 {
 		return ret
@@ -3086,11 +3299,14 @@ func refineMap(args []rl.TypingT, ret rl.TypingT) rl.TypingT {
 		return rl.NewMapType(coll.KeyT(), elemT)
 	case *rl.TypingAnyMapT:
 		return rl.NewAnyMapType()
+	case *rl.TypingIterT:
+		return rl.NewIterType()
 	}
 	return ret
 }
 
-// refineFilter types `filter`, which keeps a subset of its collection. Both
+// refineFilter types `filter`, which keeps a subset of its collection (or,
+// for an iterator, yields a subset of its values). Both
 // the kind and the element type survive untouched, so the collection's own
 // type is exact - only the length changes, which types don't track.
 func refineFilter(args []rl.TypingT, ret rl.TypingT) rl.TypingT {
@@ -3098,12 +3314,108 @@ func refineFilter(args []rl.TypingT, ret rl.TypingT) rl.TypingT {
 		return ret
 	}
 	switch args[0].(type) {
-	case *rl.TypingListT, *rl.TypingAnyListT, *rl.TypingMapT, *rl.TypingAnyMapT:
+	case *rl.TypingListT, *rl.TypingAnyListT, *rl.TypingMapT, *rl.TypingAnyMapT, *rl.TypingIterT:
 		return args[0]
 	}
 	return ret
 }
 
+// refineRecord turns record()'s `any` into the constructor it returns, when
+// the name and every field spec are literals. Bound to a name, the constructor
+// then checks like any other function: unknown or missing fields and
+// mistyped values at each `Person(...)`, and a struct result whose fields
+// resolve. Specs we can't read statically leave the wide type, and calls
+// through it go unchecked. A literal spec that doesn't parse is the same
+// error the call raises at runtime, so it's reported here.
+func (tc *typeChecker) refineRecord(call *rl.Call, ret rl.TypingT) rl.TypingT {
+	if len(call.Args) != 2 {
+		return ret
+	}
+	name, ok := simpleStringValue(call.Args[0])
+	if !ok {
+		return ret
+	}
+	lit, ok := call.Args[1].(*rl.LitMap)
+	if !ok {
+		return ret
+	}
+	fields := make([]rts.RecordField, 0, len(lit.Entries))
+	for _, entry := range lit.Entries {
+		fieldName, ok := simpleStringValue(entry.Key)
+		if !ok {
+			return ret
+		}
+		spec, ok := simpleStringValue(entry.Value)
+		if !ok {
+			return ret
+		}
+		fields = append(fields, rts.RecordField{Name: fieldName, Spec: spec})
+	}
+	typing, err := rts.NewRecordTyping(name, fields)
+	if err != nil {
+		tc.addCallIssue(call.Span(), rl.ErrInvalidRecord, fmt.Sprintf("Invalid record '%s': %v", name, err))
+		return ret
+	}
+	return typing
+}
+
+// refineParseInto narrows `parse_json(s, into=Person)`, and the other parse
+// functions taking `into`, to Person's struct, or a list of them if perList.
+// The error arm stays: data that doesn't match the record is an error the
+// caller still has to handle.
+func (tc *typeChecker) refineParseInto(call *rl.Call, perList bool, ret rl.TypingT) rl.TypingT {
+	for _, na := range call.NamedArgs {
+		if na.Name != "into" {
+			continue
+		}
+		fnT, ok := tc.info.ExprTypes[na.Value].(*rl.TypingFnT)
+		if !ok || fnT.ReturnT == nil {
+			return ret
+		}
+		if _, ok := (*fnT.ReturnT).(*rl.TypingStructT); !ok {
+			return ret
+		}
+		if perList {
+			return rl.NewUnionType(rl.NewListType(*fnT.ReturnT), rl.NewErrorType())
+		}
+		return rl.NewUnionType(*fnT.ReturnT, rl.NewErrorType())
+	}
+	return ret
+}
+
+// refineFind gives find() and find_all() their match's shape. With a
+// literal pattern, "named" is a struct of exactly its named groups, so a
+// misspelled group is caught like any other missing key.
+func refineFind(call *rl.Call, name string, ret rl.TypingT) rl.TypingT {
+	var named rl.TypingT = rl.NewMapType(rl.NewStrType(), rl.NewOptionalType(rl.NewStrType()))
+	if len(call.Args) == 2 {
+		if pattern, ok := simpleStringValue(call.Args[1]); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return ret
+			}
+			groups := map[rl.MapNamedKey]rl.TypingT{}
+			for _, group := range re.SubexpNames() {
+				if group != "" {
+					groups[rl.NewMapNamedKey(group, false)] = rl.NewOptionalType(rl.NewStrType())
+				}
+			}
+			named = rl.NewStructType(groups)
+		}
+	}
+	match := rl.NewStructType(map[rl.MapNamedKey]rl.TypingT{
+		rl.NewMapNamedKey("match", false):  rl.NewStrType(),
+		rl.NewMapNamedKey("start", false):  rl.NewIntType(),
+		rl.NewMapNamedKey("end", false):    rl.NewIntType(),
+		rl.NewMapNamedKey("groups", false): rl.NewListType(rl.NewOptionalType(rl.NewStrType())),
+		rl.NewMapNamedKey("named", false):  named,
+	})
+	if name == "find_all" {
+		return rl.NewUnionType(rl.NewListType(match), rl.NewErrorType())
+	}
+	return rl.NewUnionType(match, rl.NewNullType(), rl.NewErrorType())
+}
+
 // refineRange collapses `float[]|int[]` to `int[]` when every written argument
 // is an int - the list counterpart of the clamp/min/max/abs rule. An omitted
 // `_step` defaults to the int literal 1, so absent args need no special care.
@@ -3399,10 +3711,46 @@ func (tc *typeChecker) synthMemberAccess(recv rl.TypingT, name string, span rl.S
 		// Uniform map: every key shares one type, so we can't prove
 		// any particular key is absent. Resolve to the value type.
 		return r.ValT()
+	case *rl.TypingDatetimeT:
+		return tc.synthMemberAccess(datetimeFieldsType, name, span, false)
+	case *rl.TypingDurationT:
+		return tc.synthMemberAccess(durationFieldsType, name, span, false)
 	}
 	return rl.NewDynamicType()
 }
 
+// datetimeFieldsType and durationFieldsType are the fields a datetime and a
+// duration expose (core/type_datetime.go): those of the maps now() and
+// parse_duration() return, plus a datetime's zone.
+var (
+	datetimeFieldsType = rl.NewStructType(map[rl.MapNamedKey]rl.TypingT{
+		rl.NewMapNamedKey("date", false):    rl.NewStrType(),
+		rl.NewMapNamedKey("year", false):    rl.NewIntType(),
+		rl.NewMapNamedKey("month", false):   rl.NewIntType(),
+		rl.NewMapNamedKey("day", false):     rl.NewIntType(),
+		rl.NewMapNamedKey("weekday", false): rl.NewIntType(),
+		rl.NewMapNamedKey("hour", false):    rl.NewIntType(),
+		rl.NewMapNamedKey("minute", false):  rl.NewIntType(),
+		rl.NewMapNamedKey("second", false):  rl.NewIntType(),
+		rl.NewMapNamedKey("time", false):    rl.NewStrType(),
+		rl.NewMapNamedKey("tz", false):      rl.NewStrType(),
+		rl.NewMapNamedKey("epoch", false): rl.NewStructType(map[rl.MapNamedKey]rl.TypingT{
+			rl.NewMapNamedKey("seconds", false): rl.NewIntType(),
+			rl.NewMapNamedKey("millis", false):  rl.NewIntType(),
+			rl.NewMapNamedKey("nanos", false):   rl.NewIntType(),
+		}),
+	})
+	durationFieldsType = rl.NewStructType(map[rl.MapNamedKey]rl.TypingT{
+		rl.NewMapNamedKey("nanos", false):   rl.NewIntType(),
+		rl.NewMapNamedKey("micros", false):  rl.NewFloatType(),
+		rl.NewMapNamedKey("millis", false):  rl.NewFloatType(),
+		rl.NewMapNamedKey("seconds", false): rl.NewFloatType(),
+		rl.NewMapNamedKey("minutes", false): rl.NewFloatType(),
+		rl.NewMapNamedKey("hours", false):   rl.NewFloatType(),
+		rl.NewMapNamedKey("days", false):    rl.NewFloatType(),
+	})
+)
+
 // synthIndexAccess resolves a bracket access (`recv[expr]`). A
 // static string-literal index against a struct shape is equivalent
 // to a dot access and gets the same existence check. Dynamic keys
@@ -3420,6 +3768,11 @@ func (tc *typeChecker) synthIndexAccess(recv rl.TypingT, index rl.Node, span rl.
 		return rl.NewDynamicType()
 	case *rl.TypingMapT:
 		return r.ValT()
+	case *rl.TypingDatetimeT, *rl.TypingDurationT:
+		if lit, ok := index.(*rl.LitString); ok && lit.Simple {
+			return tc.synthMemberAccess(recv, lit.Value, lit.Span(), isWriteTarget)
+		}
+		return rl.NewDynamicType()
 	}
 	return rl.NewDynamicType()
 }
@@ -3870,6 +4223,9 @@ func (tc *typeChecker) synthOpUnary(n *rl.OpUnary) rl.TypingT {
 		if isFloat(operand) {
 			return tc.record(n, rl.NewFloatType())
 		}
+		if isDuration(operand) {
+			return tc.record(n, rl.NewDurationType())
+		}
 		tc.addUnaryOpIssue(n.Span(), n.Op, operand)
 		return tc.record(n, rl.NewErrorTypeType())
 	}
@@ -3952,13 +4308,13 @@ func binaryOpResult(op rl.Operator, l, r rl.TypingT) (rl.TypingT, bool) {
 		return unionOf(l, r), true
 	case rl.OpIn, rl.OpNotIn:
 		// Result is always bool; the right side must be a container
-		// (str / list / map). Left can be anything. The runtime also
+		// (str / list / map / set). Left can be anything. The runtime also
 		// probes a bare error's details map (`x in err`, core/expr_ops.go),
 		// so a bare error right operand is valid too - it survives
 		// stripping when it comes straight from error() or out of a catch.
 		// An `error?` right operand falls through to the uncertain path (it
 		// could be null at runtime), so its rejection is a Hint not a gate.
-		if isStr(r) || isList(r) || isMap(r) {
+		if isStr(r) || isList(r) || isMap(r) || isSet(r) {
 			return rl.NewBoolType(), true
 		}
 		if _, isErr := r.(*rl.TypingErrorT); isErr {
@@ -3973,8 +4329,22 @@ func binaryOpResult(op rl.Operator, l, r rl.TypingT) (rl.TypingT, bool) {
 		if isStr(l) && isStr(r) {
 			return rl.NewBoolType(), true
 		}
+		if (isDatetime(l) && isDatetime(r)) || (isDuration(l) && isDuration(r)) {
+			return rl.NewBoolType(), true
+		}
+		// On sets, subset and superset.
+		if isSet(l) && isSet(r) {
+			return rl.NewBoolType(), true
+		}
 		return nil, false
 	case rl.OpAdd:
+		// datetime+duration, in either order, moves the datetime.
+		if (isDatetime(l) && isDuration(r)) || (isDuration(l) && isDatetime(r)) {
+			return rl.NewDatetimeType(), true
+		}
+		if isDuration(l) && isDuration(r) {
+			return rl.NewDurationType(), true
+		}
 		// int+int -> int, with int->float widening.
 		if isInt(l) && isInt(r) {
 			return rl.NewIntType(), true
@@ -3992,6 +4362,10 @@ func binaryOpResult(op rl.Operator, l, r rl.TypingT) (rl.TypingT, bool) {
 		if isList(l) && isList(r) {
 			return rl.NewAnyListType(), true
 		}
+		// set+set is the union.
+		if isSet(l) && isSet(r) {
+			return rl.NewSetType(), true
+		}
 		return nil, false
 	case rl.OpSub:
 		if isInt(l) && isInt(r) {
@@ -4000,6 +4374,20 @@ func binaryOpResult(op rl.Operator, l, r rl.TypingT) (rl.TypingT, bool) {
 		if isNumeric(l) && isNumeric(r) {
 			return rl.NewFloatType(), true
 		}
+		// datetime-datetime is the span between them.
+		if isDatetime(l) && isDatetime(r) {
+			return rl.NewDurationType(), true
+		}
+		if isDatetime(l) && isDuration(r) {
+			return rl.NewDatetimeType(), true
+		}
+		if isDuration(l) && isDuration(r) {
+			return rl.NewDurationType(), true
+		}
+		// set-set is the difference.
+		if isSet(l) && isSet(r) {
+			return rl.NewSetType(), true
+		}
 		return nil, false
 	case rl.OpMul:
 		// int*int -> int, mixed numeric -> float.
@@ -4013,6 +4401,9 @@ func binaryOpResult(op rl.Operator, l, r rl.TypingT) (rl.TypingT, bool) {
 		if (isStr(l) && isInt(r)) || (isInt(l) && isStr(r)) {
 			return rl.NewStrType(), true
 		}
+		if (isDuration(l) && isNumeric(r)) || (isNumeric(l) && isDuration(r)) {
+			return rl.NewDurationType(), true
+		}
 		return nil, false
 	case rl.OpDiv:
 		// Rad's `/` is true division: int/int yields float, not int.
@@ -4020,6 +4411,14 @@ func binaryOpResult(op rl.Operator, l, r rl.TypingT) (rl.TypingT, bool) {
 		if isNumeric(l) && isNumeric(r) {
 			return rl.NewFloatType(), true
 		}
+		// A duration divides into a smaller duration, or by another
+		// duration into their ratio.
+		if isDuration(l) && isNumeric(r) {
+			return rl.NewDurationType(), true
+		}
+		if isDuration(l) && isDuration(r) {
+			return rl.NewFloatType(), true
+		}
 		return nil, false
 	case rl.OpMod:
 		// int%int -> int; any other numeric mix widens to float
@@ -4062,6 +4461,21 @@ func isStr(t rl.TypingT) bool {
 	return false
 }
 
+func isDatetime(t rl.TypingT) bool {
+	_, ok := t.(*rl.TypingDatetimeT)
+	return ok
+}
+
+func isDuration(t rl.TypingT) bool {
+	_, ok := t.(*rl.TypingDurationT)
+	return ok
+}
+
+func isSet(t rl.TypingT) bool {
+	_, ok := t.(*rl.TypingSetT)
+	return ok
+}
+
 func isError(t rl.TypingT) bool {
 	_, ok := t.(*rl.TypingErrorT)
 	return ok
@@ -4325,6 +4739,8 @@ func (tc *typeChecker) synthLambda(n *rl.Lambda) rl.TypingT {
 	// sees this body's reassignments as its enclosing scope.
 	prevReassigned := tc.reassignedInScope
 	tc.reassignedInScope = tc.scanReassignments(n.Body)
+	prevInGenerator := tc.inGenerator
+	tc.inGenerator = isGenerator(n.IsBlock, n.Body)
 
 	// Expression-form lambdas (`fn(x) x + 1`) put the expression
 	// node directly in Body (verified against the converter output -
@@ -4345,6 +4761,7 @@ func (tc *typeChecker) synthLambda(n *rl.Lambda) rl.TypingT {
 		tc.walkStmts(n.Body)
 	}
 
+	tc.inGenerator = prevInGenerator
 	tc.reassignedInScope = prevReassigned
 	collected := tc.popReturnFrame()
 	tc.frame = enclosing
@@ -4352,8 +4769,11 @@ func (tc *typeChecker) synthLambda(n *rl.Lambda) rl.TypingT {
 	// Honor an explicit return annotation; otherwise infer. Same
 	// shape as hoisted-fn inference: empty returns → void, all-
 	// dropped (purely recursive) → Dynamic, else the union.
+	// A generator gives an iterator either way.
 	var returnT rl.TypingT
-	if n.Typing != nil && n.Typing.ReturnT != nil {
+	if isGenerator(n.IsBlock, n.Body) {
+		returnT = rl.NewIterType()
+	} else if n.Typing != nil && n.Typing.ReturnT != nil {
 		returnT = *n.Typing.ReturnT
 	} else if len(collected) == 0 {
 		returnT = rl.NewVoidType()
@@ -1270,6 +1270,66 @@ func TestTypeCheck_SwitchNonEnumDiscriminantSkipsExhaustiveness(t *testing.T) {
 	}
 }
 
+func TestTypeCheck_SwitchTypePatternNarrowsDiscriminant(t *testing.T) {
+	src := `fn f(v: int|str|bool):
+    switch v:
+        case of_type("int"):
+            a = v
+        case bind("s", of_type("str")):
+            b = v
+        default:
+            c = v
+`
+	file, info, _ := typeInfoFromSrc(t, src)
+	fn := file.Stmts[0].(*rl.FnDef)
+	sw := fn.Body[0].(*rl.Switch)
+
+	aUse := sw.Cases[0].Alt.(*rl.SwitchCaseBlock).Stmts[0].(*rl.Assign).Values[0].(*rl.Identifier)
+	bUse := sw.Cases[1].Alt.(*rl.SwitchCaseBlock).Stmts[0].(*rl.Assign).Values[0].(*rl.Identifier)
+	cUse := sw.Default.Alt.(*rl.SwitchCaseBlock).Stmts[0].(*rl.Assign).Values[0].(*rl.Identifier)
+
+	assert.Equal(t, rl.T_INT, info.ExprTypes[aUse].Name())
+	assert.Equal(t, rl.T_STR, info.ExprTypes[bUse].Name())
+	assert.Equal(t, rl.T_BOOL, info.ExprTypes[cUse].Name())
+}
+
+func TestTypeCheck_SwitchTypePatternsHintAtUnmatchedTypes(t *testing.T) {
+	src := `fn f(v: int|str|bool):
+    switch v:
+        case of_type("int"):
+            x = 1
+        case of_type("str"):
+            x = 2
+`
+	_, info, _ := typeInfoFromSrc(t, src)
+	found := false
+	for _, i := range info.Issues {
+		if i.Code == rl.ErrNonExhaustiveSwitch {
+			found = true
+			assert.Equal(t, check.IssueHint, i.Severity)
+			assert.Contains(t, i.Message, "bool")
+		}
+	}
+	assert.True(t, found, "type patterns leaving bool unmatched should hint")
+}
+
+func TestTypeCheck_SwitchTypePatternsCoveringEveryTypeNoHint(t *testing.T) {
+	src := `fn f(v: int|str):
+    switch v:
+        case of_type("int"):
+            x = 1
+        case of_type("str"), between(1, 2):
+            x = 2
+        case of_type("str"):
+            x = 3
+`
+	_, info, _ := typeInfoFromSrc(t, src)
+	for _, i := range info.Issues {
+		assert.NotEqual(t, rl.ErrNonExhaustiveSwitch, i.Code,
+			"int and str are both matched by a type pattern")
+	}
+}
+
 func TestTypeCheck_IfDoesNotLeakNarrowingAfter(t *testing.T) {
 	// After a non-exiting if, the narrowing should not persist into
 	// subsequent statements at the same scope. Use a fn param so the
@@ -1392,3 +1452,168 @@ func TestTypeCheck_ShellTargetsSurviveTheCatchBlock(t *testing.T) {
 	_, info, _ := typeInfoFromSrc(t, "out = $`cmd` catch:\n    pass\nprint(trim(out))\n")
 	assert.Empty(t, info.Issues)
 }
+
+func TestTypeCheck_RecordConstructorChecksFields(t *testing.T) {
+	// A record() with literal specs binds its constructor's signature, so
+	// a mistyped field at the call site is a provable mismatch.
+	src := "Person = record(\"Person\", { \"name\": \"str\", \"age\": \"int = 0\" })\n" +
+		"p = Person(name=\"bob\", age=\"old\")\n"
+	_, info, _ := typeInfoFromSrc(t, src)
+	assert.True(t, hasIssue(info, rl.ErrTypeMismatch),
+		"expected ErrTypeMismatch for a mistyped record field")
+}
+
+func TestTypeCheck_RecordConstructorFlagsUnknownAndMissingFields(t *testing.T) {
+	src := "Person = record(\"Person\", { \"name\": \"str\" })\n" +
+		"p = Person(nmae=\"bob\")\n"
+	_, info, _ := typeInfoFromSrc(t, src)
+	assert.True(t, hasIssue(info, rl.ErrInvalidArgType), "expected the typo'd field to be flagged")
+	assert.True(t, hasIssue(info, rl.ErrWrongArgCount), "expected the missing field to be flagged")
+}
+
+func TestTypeCheck_RecordConstructorReturnsStruct(t *testing.T) {
+	src := "Person = record(\"Person\", { \"name\": \"str\", \"email\": \"str?\" })\n" +
+		"p = Person(name=\"bob\")\n"
+	file, info, _ := typeInfoFromSrc(t, src)
+	call := file.Stmts[1].(*rl.Assign).Values[0].(*rl.Call)
+	got := info.ExprTypes[call]
+	require.NotNil(t, got)
+	assert.Equal(t, `{ "email": str?, "name": str }`, got.Name())
+	assert.Empty(t, info.Issues)
+}
+
+func TestTypeCheck_ParseJsonIntoRecordKeepsErrorArm(t *testing.T) {
+	// The result narrows to the record, but data that doesn't match is
+	// still an error, so an uncaught call keeps its fallible hint.
+	src := "Person = record(\"Person\", { \"name\": \"str\" })\n" +
+		"p = parse_json(\"{}\", into=Person)\n"
+	file, info, _ := typeInfoFromSrc(t, src)
+	call := file.Stmts[1].(*rl.Assign).Values[0].(*rl.Call)
+	got := info.ExprTypes[call]
+	require.NotNil(t, got)
+	assert.Equal(t, `{ "name": str }`, got.Name())
+	assert.True(t, hasIssue(info, rl.ErrUnhandledFallibleCall))
+}
+
+func TestTypeCheck_PerLineParseIntoRecordIsAList(t *testing.T) {
+	src := "Person = record(\"Person\", { \"name\": \"str\" })\n" +
+		"a = parse_ndjson(\"\", into=Person)\n" +
+		"b = parse_yaml(\"\", into=Person, all=true)\n" +
+		"c = parse_toml(\"\", into=Person)\n"
+	file, info, _ := typeInfoFromSrc(t, src)
+	for idx, want := range []string{`{ "name": str }[]`, `{ "name": str }[]`, `{ "name": str }`} {
+		call := file.Stmts[idx+1].(*rl.Assign).Values[0].(*rl.Call)
+		got := info.ExprTypes[call]
+		require.NotNil(t, got)
+		assert.Equal(t, want, got.Name())
+	}
+}
+
+func TestTypeCheck_InvalidLiteralRecordSpecIsFlagged(t *testing.T) {
+	src := "Person = record(\"Person\", { \"name\": \"string\" })\n"
+	_, info, _ := typeInfoFromSrc(t, src)
+	assert.True(t, hasIssue(info, rl.ErrInvalidRecord), "expected the unparseable spec to be flagged")
+}
+
+func TestTypeCheck_ComputedRecordSpecsStayUnchecked(t *testing.T) {
+	src := "spec = \"str\"\n" +
+		"Person = record(\"Person\", { \"name\": spec })\n" +
+		"p = Person(anything=1)\n"
+	_, info, _ := typeInfoFromSrc(t, src)
+	assert.False(t, hasIssue(info, rl.ErrInvalidArgType),
+		"a record we can't read statically shouldn't produce call diagnostics")
+}
+
+func TestTypeCheck_FindAllMatchesHaveLiteralPatternGroups(t *testing.T) {
+	src := "for m in find_all(\"a@x b@y\", \"(?P<user>\\\\w+)@(?P<host>\\\\w+)\"):\n" +
+		"    print(m.start, m.match, m.named.user, m.named.host)\n"
+	_, info, _ := typeInfoFromSrc(t, src)
+	assert.False(t, hasIssue(info, rl.ErrUnknownMapKey))
+
+	src = "for m in find_all(\"a@x b@y\", \"(?P<user>\\\\w+)@(?P<host>\\\\w+)\"):\n" +
+		"    print(m.named.usr)\n"
+	_, info, _ = typeInfoFromSrc(t, src)
+	assert.True(t, hasIssue(info, rl.ErrUnknownMapKey), "expected the misspelled group to be flagged")
+}
+
+func TestTypeCheck_FindAllComputedPatternKeepsNamedOpen(t *testing.T) {
+	src := "pattern = \"(?P<user>\\\\w+)@\"\n" +
+		"for m in find_all(\"a@x\", pattern):\n" +
+		"    print(m.named.anything)\n"
+	_, info, _ := typeInfoFromSrc(t, src)
+	assert.False(t, hasIssue(info, rl.ErrUnknownMapKey))
+}
+
+func TestTypeCheck_DatetimeDurationArithmetic(t *testing.T) {
+	src := "start = datetime(\"2026-03-22\")\n" +
+		"wait = duration(\"1h\")\n" +
+		"a = start + wait\n" +
+		"b = start - start\n" +
+		"c = wait * 2\n" +
+		"d = wait / wait\n" +
+		"e = start < start\n" +
+		"f = start.year\n" +
+		"g = wait.minutes\n"
+	file, info, _ := typeInfoFromSrc(t, src)
+	for idx, want := range []string{"datetime", "duration", "duration", "float", "bool", "int", "float"} {
+		got := info.ExprTypes[file.Stmts[idx+2].(*rl.Assign).Values[0]]
+		require.NotNil(t, got)
+		assert.Equal(t, want, got.Name())
+	}
+	assert.False(t, hasIssue(info, rl.ErrInvalidTypeForOp))
+	assert.False(t, hasIssue(info, rl.ErrUnknownMapKey))
+}
+
+func TestTypeCheck_DatetimeRejectsUnsupportedOps(t *testing.T) {
+	_, info, _ := typeInfoFromSrc(t, "x = datetime() + datetime()\n")
+	assert.True(t, hasOpIssue(info), "adding two datetimes should be flagged")
+
+	_, info, _ = typeInfoFromSrc(t, "x = datetime().yaer\n")
+	assert.True(t, hasIssue(info, rl.ErrUnknownMapKey), "expected the misspelled field to be flagged")
+}
+
+func TestTypeCheck_SetOperators(t *testing.T) {
+	src := "a = set([\"x\", \"y\"])\n" +
+		"b = set([\"y\"])\n" +
+		"c = a + b\n" +
+		"d = a - b\n" +
+		"e = intersect(a, b)\n" +
+		"f = b <= a\n" +
+		"g = \"x\" in a\n"
+	file, info, _ := typeInfoFromSrc(t, src)
+	for idx, want := range []string{"set", "set", "set", "bool", "bool"} {
+		got := info.ExprTypes[file.Stmts[idx+2].(*rl.Assign).Values[0]]
+		require.NotNil(t, got)
+		assert.Equal(t, want, got.Name())
+	}
+	assert.False(t, hasOpIssue(info))
+}
+
+func TestTypeCheck_SetRejectsListOperands(t *testing.T) {
+	_, info, _ := typeInfoFromSrc(t, "x = set([1]) + [2]\n")
+	assert.True(t, hasOpIssue(info), "adding a list to a set should be flagged")
+}
+
+func TestTypeCheck_GeneratorReturnsIterator(t *testing.T) {
+	src := "xs = count_up(3)\n" +
+		"ys = map(xs, fn(x) x * 2)\n" +
+		"zs = take(ys, 2)\n" +
+		"fn count_up(n: int) -> int:\n" +
+		"\tfor i in range(n):\n" +
+		"\t\tyield i\n"
+	file, info, _ := typeInfoFromSrc(t, src)
+	for idx, want := range []string{"iterator", "iterator", "list"} {
+		got := info.ExprTypes[file.Stmts[idx].(*rl.Assign).Values[0]]
+		require.NotNil(t, got)
+		assert.Equal(t, want, got.Name())
+	}
+	assert.False(t, hasIssue(info, rl.ErrTypeMismatch))
+}
+
+func TestTypeCheck_GeneratorYieldCheckedAgainstDeclaredReturn(t *testing.T) {
+	src := "fn names() -> str:\n" +
+		"\tyield \"a\"\n" +
+		"\tyield 2\n"
+	_, info, _ := typeInfoFromSrc(t, src)
+	assert.True(t, hasIssue(info, rl.ErrTypeMismatch), "yielding an int from a str generator should be flagged")
+}
@@ -0,0 +1,40 @@
+package rts
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ImportLibDir is the directory under rad home that library imports - those
+// naming a module rather than a path - resolve in.
+const ImportLibDir = "lib"
+
+// ImportExt is the extension an import may leave off.
+const ImportExt = ".rad"
+
+// IsLibraryImport reports whether an import names a library module, e.g.
+// import("strings_ext"), rather than a file path like import("./helpers").
+func IsLibraryImport(spec string) bool {
+	return !strings.HasPrefix(spec, "./") &&
+		!strings.HasPrefix(spec, "../") &&
+		!filepath.IsAbs(spec)
+}
+
+// ResolveImport returns the file an import names. A path resolves against
+// fromDir, the directory of the file doing the importing, and a library name
+// against radHome's lib directory. Either may omit the .rad extension.
+//
+// Shared by the interpreter and the language server so that a module a script
+// would load at runtime is also the one editors jump to.
+func ResolveImport(spec, fromDir, radHome string) string {
+	path := spec
+	if IsLibraryImport(spec) {
+		path = filepath.Join(radHome, ImportLibDir, spec)
+	} else if !filepath.IsAbs(spec) {
+		path = filepath.Join(fromDir, spec)
+	}
+	if filepath.Ext(path) == "" {
+		path += ImportExt
+	}
+	return filepath.Clean(path)
+}
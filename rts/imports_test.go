@@ -0,0 +1,29 @@
+package rts
+
+import "testing"
+
+func TestResolveImport(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+		desc     string
+	}{
+		{"./helpers.rad", "/scripts/helpers.rad", "relative path"},
+		{"./helpers", "/scripts/helpers.rad", "relative path without extension"},
+		{"../shared/util", "/shared/util.rad", "parent-relative path"},
+		{"/opt/rad/util.rad", "/opt/rad/util.rad", "absolute path"},
+		{"strings_ext", "/home/me/.rad/lib/strings_ext.rad", "library name"},
+		{"net/http_ext", "/home/me/.rad/lib/net/http_ext.rad", "nested library name"},
+		{"./data.txt", "/scripts/data.txt", "explicit extension kept"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			result := ResolveImport(test.spec, "/scripts", "/home/me/.rad")
+			if result != test.expected {
+				t.Errorf("ResolveImport(%q) = %q, expected %q",
+					test.spec, result, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package radfmt
+
+import (
+	"strings"
+
+	"github.com/amterp/rad/rts"
+	ts "github.com/tree-sitter/go-tree-sitter"
+)
+
+// FormatRange formats only the top-level statements that overlap the 0-based
+// lines startLine..endLine, leaving the rest of the script byte-for-byte as it
+// was. It's what an editor's "format selection" asks for.
+//
+// The unit of formatting is a whole top-level statement: a range that touches
+// one line of an `if` formats the entire `if`, since its layout can't be
+// decided a line at a time. Anything sharing a line with the selection's first
+// or last statement (such as a trailing comment) comes along too, so the
+// spliced-in text always covers whole lines.
+//
+// Failure handling matches Format: the result must parse to the same structure
+// as the original, with the same comments, or the original comes back with
+// ok=false. A source with CR line endings is also declined - fixing those is a
+// whole-document change, which is Format's job.
+func FormatRange(src string, startLine, endLine int) (out string, changed bool, ok bool) {
+	original := src
+
+	defer func() {
+		if r := recover(); r != nil {
+			out, changed, ok = original, false, false
+		}
+	}()
+
+	if strings.Contains(src, "\r") {
+		return original, false, false
+	}
+
+	parser, err := rts.NewRadParser()
+	if err != nil {
+		return original, false, false
+	}
+	defer parser.Close()
+
+	tree := parser.Parse(src)
+	if tree.HasInvalidNodes() {
+		return original, false, false
+	}
+	root := tree.Root()
+	if root == nil {
+		return original, false, false
+	}
+
+	items := childPtrs(root)
+	first, last := -1, -1
+	for i, item := range items {
+		if int(lastRow(item)) >= startLine && int(startRow(item)) <= endLine {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		// Only blank lines selected: nothing to format.
+		return original, false, true
+	}
+	for first > 0 && lastRow(items[first-1]) >= startRow(items[first]) {
+		first--
+	}
+	for last+1 < len(items) && startRow(items[last+1]) <= lastRow(items[last]) {
+		last++
+	}
+
+	wantSig, wantComments := structuralSig(root)
+
+	p := &printer{src: src}
+	formatted := PrintDocToString(concat(p.formatSeq(items[first:last+1]), hardLine()), MaxWidth)
+
+	lo := lineOffset(src, startRow(items[first]))
+	hi := lineOffset(src, lastRow(items[last])+1)
+	out = src[:lo] + formatted + src[hi:]
+
+	if !structurallyEquivalent(out, wantSig, wantComments) {
+		return original, false, false
+	}
+	return out, out != original, true
+}
+
+// lastRow is the last row a node has text on. A node whose span ends at the
+// start of a row (having consumed the previous row's newline) doesn't occupy
+// that row.
+func lastRow(n *ts.Node) uint {
+	end := n.EndPosition()
+	if end.Column == 0 && end.Row > startRow(n) {
+		return end.Row - 1
+	}
+	return end.Row
+}
+
+// lineOffset is the byte offset row starts at, or len(src) past the last row.
+func lineOffset(src string, row uint) int {
+	off := 0
+	for ; row > 0; row-- {
+		idx := strings.IndexByte(src[off:], '\n')
+		if idx < 0 {
+			return len(src)
+		}
+		off += idx + 1
+	}
+	return off
+}
@@ -0,0 +1,54 @@
+package radfmt
+
+import "testing"
+
+// Only the statements a range touches are formatted; the lines around them are
+// left exactly as they were, however unformatted.
+func TestFormatRange_OnlyTouchesSelectedStatements(t *testing.T) {
+	src := "a=1\nb=2\nc=3\n"
+	out, changed, ok := FormatRange(src, 1, 1)
+	if !ok {
+		t.Fatal("ok=false for valid source")
+	}
+	if !changed {
+		t.Error("changed=false but the selected statement was unformatted")
+	}
+	if want := "a=1\nb = 2\nc=3\n"; out != want {
+		t.Errorf("range format:\n got: %q\nwant: %q", out, want)
+	}
+}
+
+// A range inside a compound statement formats the whole statement, since its
+// layout isn't decided a line at a time.
+func TestFormatRange_WidensToWholeStatement(t *testing.T) {
+	src := "x=1\nif x:\n    a=1\n    b=2\ny=2\n"
+	out, _, ok := FormatRange(src, 3, 3)
+	if !ok {
+		t.Fatal("ok=false for valid source")
+	}
+	if want := "x=1\nif x:\n    a = 1\n    b = 2\ny=2\n"; out != want {
+		t.Errorf("range format:\n got: %q\nwant: %q", out, want)
+	}
+}
+
+// Blank lines between statements are outside every statement, so selecting
+// only those changes nothing but still succeeds.
+func TestFormatRange_BlankSelectionIsNoOp(t *testing.T) {
+	src := "a=1\n\nb=2\n"
+	out, changed, ok := FormatRange(src, 1, 1)
+	if !ok || changed || out != src {
+		t.Errorf("blank selection should be an ok no-op: out=%q changed=%v ok=%v", out, changed, ok)
+	}
+}
+
+// Safety: the same guarantees as Format - invalid source is never touched.
+func TestFormatRange_InvalidSourceIsNoOp(t *testing.T) {
+	src := "a=1\nb = = 2\n"
+	out, changed, ok := FormatRange(src, 0, 0)
+	if ok {
+		t.Errorf("expected ok=false for invalid source: %q", src)
+	}
+	if out != src || changed {
+		t.Errorf("invalid source should be an unchanged no-op: out=%q changed=%v", out, changed)
+	}
+}
@@ -0,0 +1,118 @@
+package rts
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+var recordIdentRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// RecordField is one field of a record type, as given to record(). Spec is
+// written like a parameter's annotation, optionally with a default:
+// "str", "int?", "str[] = []".
+type RecordField struct {
+	Name string
+	Spec string
+}
+
+// NewRecordTyping builds the constructor signature of a record type: one
+// parameter per field, in order, returning a map holding every field.
+//
+// Each spec goes through the parser as a parameter of its own, the same way
+// builtin signatures do, so a record accepts exactly the types and defaults a
+// function signature does. Shared by the interpreter and the type checker, so
+// the constructor a script calls is the one its calls are checked against.
+//
+// A record is only ever its constructor: annotations have no syntax for naming
+// a type, so a record's name can't be used as one.
+func NewRecordTyping(name string, fields []RecordField) (*rl.TypingFnT, error) {
+	if !recordIdentRegex.MatchString(name) {
+		return nil, fmt.Errorf("name %q isn't a valid identifier", name)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("a record needs at least one field")
+	}
+
+	parser, err := NewRadParser()
+	if err != nil {
+		return nil, err
+	}
+	defer parser.Close()
+
+	params := make([]rl.TypingFnParam, 0, len(fields))
+	structFields := make(map[rl.MapNamedKey]rl.TypingT, len(fields))
+	for _, field := range fields {
+		if !recordIdentRegex.MatchString(field.Name) {
+			return nil, fmt.Errorf("field name %q isn't a valid identifier", field.Name)
+		}
+		param, err := parseRecordField(parser, field)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+		structFields[rl.NewMapNamedKey(field.Name, false)] = *param.Type
+	}
+
+	var ret rl.TypingT = rl.NewStructType(structFields)
+	return &rl.TypingFnT{FnName: name, Params: params, ReturnT: &ret}, nil
+}
+
+func parseRecordField(parser *RadParser, field RecordField) (rl.TypingFnParam, error) {
+	param, ok := parseParamSpec(parser, field.Name, field.Spec, "<record>")
+	if !ok {
+		return rl.TypingFnParam{}, fmt.Errorf("field '%s' has an invalid spec %q", field.Name, field.Spec)
+	}
+	return param, nil
+}
+
+// ParseTypeSpec parses a type written as in an annotation, e.g. "int",
+// "str[]" or "map?", the way of_type() patterns give it.
+func ParseTypeSpec(spec string) (rl.TypingT, error) {
+	parser, err := NewRadParser()
+	if err != nil {
+		return nil, err
+	}
+	defer parser.Close()
+
+	param, ok := parseParamSpec(parser, "_t", spec, "<type>")
+	if !ok || param.DefaultAST != nil {
+		return nil, fmt.Errorf("invalid type %q", spec)
+	}
+	return *param.Type, nil
+}
+
+// parseParamSpec parses "name: spec" as the sole parameter of a signature.
+// Reports false if spec isn't exactly a type, optionally with a default.
+func parseParamSpec(parser *RadParser, name, spec, file string) (rl.TypingFnParam, bool) {
+	// A newline in the spec could close the signature and smuggle in a body.
+	if strings.ContainsAny(spec, "\n\r") || strings.TrimSpace(spec) == "" {
+		return rl.TypingFnParam{}, false
+	}
+
+	src := fmt.Sprintf("fn _(%s: %s):\n    pass\n", name, spec)
+	tree := parser.Parse(src)
+	defer tree.Close()
+
+	if len(tree.FindInvalidNodes()) > 0 {
+		return rl.TypingFnParam{}, false
+	}
+	typing := rl.NewTypingFnT(tree.Root().Child(0), src)
+	if len(typing.Params) != 1 || typing.Params[0].Name != name || typing.Params[0].Type == nil {
+		return rl.TypingFnParam{}, false
+	}
+
+	param := typing.Params[0]
+	// Convert the default now, while the tree it points into is still open;
+	// the CST form isn't kept past this call.
+	if param.Default != nil {
+		param.DefaultAST = &rl.ASTDefault{
+			Node: ConvertExpr(param.Default.Node, param.Default.Src, file),
+			Src:  param.Default.Src,
+		}
+		param.Default = nil
+	}
+	return param, true
+}
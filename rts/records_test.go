@@ -0,0 +1,77 @@
+package rts
+
+import (
+	"testing"
+
+	"github.com/amterp/rad/rts/rl"
+)
+
+func TestNewRecordTyping(t *testing.T) {
+	typing, err := NewRecordTyping("Person", []RecordField{
+		{Name: "name", Spec: "str"},
+		{Name: "age", Spec: "int = 0"},
+		{Name: "email", Spec: "str?"},
+	})
+	if err != nil {
+		t.Fatalf("NewRecordTyping: %v", err)
+	}
+	if typing.FnName != "Person" || len(typing.Params) != 3 {
+		t.Fatalf("unexpected signature: %+v", typing)
+	}
+	if typing.Params[1].DefaultAST == nil {
+		t.Errorf("expected 'age' to carry its default")
+	}
+	if got, expected := (*typing.ReturnT).Name(), `{ "age": int, "email": str?, "name": str }`; got != expected {
+		t.Errorf("return type = %s, expected %s", got, expected)
+	}
+	if _, ok := (*typing.ReturnT).(*rl.TypingStructT); !ok {
+		t.Errorf("expected a struct return type")
+	}
+}
+
+func TestNewRecordTyping_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []RecordField
+		desc   string
+	}{
+		{"Person", []RecordField{{Name: "age", Spec: "integer"}}, "unknown type"},
+		{"Person", []RecordField{{Name: "due-date", Spec: "str"}}, "field name not an identifier"},
+		{"Person", []RecordField{{Name: "_id", Spec: "str"}}, "field name starting with underscore"},
+		{"my record", []RecordField{{Name: "id", Spec: "str"}}, "record name not an identifier"},
+		{"Person", nil, "no fields"},
+		{"Person", []RecordField{{Name: "a", Spec: "str):\n    pass\nfn b(x: int"}}, "spec spanning lines"},
+		{"Person", []RecordField{{Name: "a", Spec: "str, b: int"}}, "spec declaring a second field"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := NewRecordTyping(test.name, test.fields); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseTypeSpec(t *testing.T) {
+	for spec, expected := range map[string]string{
+		"int":       "int",
+		"str[]":     "str[]",
+		"int|float": "int|float",
+		"map?":      "map?",
+	} {
+		typ, err := ParseTypeSpec(spec)
+		if err != nil {
+			t.Errorf("ParseTypeSpec(%q): %v", spec, err)
+			continue
+		}
+		if got := typ.Name(); got != expected {
+			t.Errorf("ParseTypeSpec(%q) = %s, expected %s", spec, got, expected)
+		}
+	}
+	for _, spec := range []string{"", "integer", "int = 5", "int):\n    pass\nfn x(a: int"} {
+		if _, err := ParseTypeSpec(spec); err == nil {
+			t.Errorf("ParseTypeSpec(%q): expected an error", spec)
+		}
+	}
+}
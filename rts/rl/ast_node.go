@@ -29,6 +29,36 @@ func Walk(node Node, visit func(Node)) {
 	}
 }
 
+// IsGeneratorBody reports whether a function body yields values to its
+// caller, which makes the function a generator. A `yield` inside a switch
+// gives that case's value instead, and one inside a nested fn or lambda
+// belongs to that fn.
+func IsGeneratorBody(body []Node) bool {
+	var visit func(Node) bool
+	visit = func(n Node) bool {
+		switch n.(type) {
+		case nil:
+			return false
+		case *Yield:
+			return true
+		case *Switch, *FnDef, *Lambda:
+			return false
+		}
+		for _, child := range n.Children() {
+			if visit(child) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, stmt := range body {
+		if visit(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
 // NodeKind identifies the type of an AST node.
 type NodeKind uint16
 
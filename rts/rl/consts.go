@@ -229,6 +229,10 @@ const (
 	T_BOOL       = "bool"
 	T_BOOL_LIST  = "bool[]"
 	T_ERROR      = "error"
+	T_DATETIME   = "datetime"
+	T_DURATION   = "duration"
+	T_SET        = "set"
+	T_ITERATOR   = "iterator"
 	T_ANY        = "any"
 	T_DYNAMIC    = "dynamic"
 	T_NEVER      = "never"
@@ -96,7 +96,7 @@ const (
 	ErrNegativeIndex                  = "20037"
 	ErrVoidValue                      = "20038"
 	ErrUnsupportedOperation           = "20039"
-	ErrAssertionFailed                = "20040"
+	ErrAssertionFailed_retired        = "20040"
 	ErrKeyNotFound                    = "20041"
 	ErrInternalBug                    = "20042"
 	ErrParseDuration                  = "20043"
@@ -106,6 +106,16 @@ const (
 	ErrPromptUnanswerable             = "20047"
 	ErrShellNonZeroExit               = "20048"
 	ErrReplUnsupportedConstruct       = "20049"
+	ErrImportFailed                   = "20050"
+	ErrInvalidRecord                  = "20051"
+	ErrRecordMismatch                 = "20052"
+	ErrInvalidPattern                 = "20053"
+	ErrParseData                      = "20054"
+	ErrEncodeData                     = "20055"
+	ErrIteratorConsumed               = "20056"
+	ErrInvalidTemplate                = "20057"
+	ErrAssertionFailed                = "20058"
+	ErrTestOnly                       = "20059"
 
 	// 3xxxx Type Errors
 	ErrTypeMismatch              Error = "30001"
@@ -147,6 +157,7 @@ const (
 	ErrConstraintTypeMismatch           Error = "40024"
 	ErrShellExprNoAccessor              Error = "40025"
 	ErrShellPostfixNotAccessor          Error = "40026"
+	ErrUnusedSuppression                Error = "40027"
 )
 
 func (e Error) String() string {
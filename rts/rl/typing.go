@@ -19,6 +19,10 @@ const (
 	RadFnT
 	RadNullT
 	RadErrorT
+	RadDatetimeT
+	RadDurationT
+	RadSetT
+	RadIterT
 )
 
 func (r RadType) AsString() string {
@@ -41,6 +45,14 @@ func (r RadType) AsString() string {
 		return "null"
 	case RadErrorT:
 		return T_ERROR
+	case RadDatetimeT:
+		return T_DATETIME
+	case RadDurationT:
+		return T_DURATION
+	case RadSetT:
+		return T_SET
+	case RadIterT:
+		return T_ITERATOR
 	default:
 		panic(fmt.Sprintf("Bug! Unhandled Rad type in AsString: %v", r))
 	}
@@ -151,6 +163,10 @@ var (
 	_ TypingT = (*TypingFloatT)(nil)
 	_ TypingT = (*TypingBoolT)(nil)
 	_ TypingT = (*TypingErrorT)(nil)
+	_ TypingT = (*TypingDatetimeT)(nil)
+	_ TypingT = (*TypingDurationT)(nil)
+	_ TypingT = (*TypingSetT)(nil)
+	_ TypingT = (*TypingIterT)(nil)
 	_ TypingT = (*TypingAnyT)(nil)
 	_ TypingT = (*TypingDynamicT)(nil)
 	_ TypingT = (*TypingErrorTypeT)(nil)
@@ -274,6 +290,81 @@ func (t *TypingErrorT) IsCompatibleWith(val TypingCompatVal) bool {
 	return false
 }
 
+// TypingDatetimeT and TypingDurationT have no annotation spelling; they're
+// only ever inferred, from the datetime() / duration() family of builtins and
+// the arithmetic on their results.
+type TypingDatetimeT struct{}
+
+func NewDatetimeType() *TypingDatetimeT {
+	return &TypingDatetimeT{}
+}
+
+func (t *TypingDatetimeT) Name() string {
+	return T_DATETIME
+}
+
+func (t *TypingDatetimeT) IsCompatibleWith(val TypingCompatVal) bool {
+	if val.Type != nil {
+		return *val.Type == RadDatetimeT
+	}
+	return false
+}
+
+type TypingDurationT struct{}
+
+func NewDurationType() *TypingDurationT {
+	return &TypingDurationT{}
+}
+
+func (t *TypingDurationT) Name() string {
+	return T_DURATION
+}
+
+func (t *TypingDurationT) IsCompatibleWith(val TypingCompatVal) bool {
+	if val.Type != nil {
+		return *val.Type == RadDurationT
+	}
+	return false
+}
+
+// TypingSetT is likewise inferred only, from set() and the operators on
+// sets. Element types aren't tracked; iterating a set yields dynamic.
+type TypingSetT struct{}
+
+func NewSetType() *TypingSetT {
+	return &TypingSetT{}
+}
+
+func (t *TypingSetT) Name() string {
+	return T_SET
+}
+
+func (t *TypingSetT) IsCompatibleWith(val TypingCompatVal) bool {
+	if val.Type != nil {
+		return *val.Type == RadSetT
+	}
+	return false
+}
+
+// TypingIterT is the lazy sequence a generator function or read_lines()
+// returns. Inferred only, and element types aren't tracked.
+type TypingIterT struct{}
+
+func NewIterType() *TypingIterT {
+	return &TypingIterT{}
+}
+
+func (t *TypingIterT) Name() string {
+	return T_ITERATOR
+}
+
+func (t *TypingIterT) IsCompatibleWith(val TypingCompatVal) bool {
+	if val.Type != nil {
+		return *val.Type == RadIterT
+	}
+	return false
+}
+
 type TypingAnyT struct{} // var: any
 
 func NewAnyType() *TypingAnyT {
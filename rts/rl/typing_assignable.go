@@ -86,6 +86,18 @@ func typesEqual(a, b TypingT) bool {
 	case *TypingErrorT:
 		_, ok := b.(*TypingErrorT)
 		return ok
+	case *TypingDatetimeT:
+		_, ok := b.(*TypingDatetimeT)
+		return ok
+	case *TypingDurationT:
+		_, ok := b.(*TypingDurationT)
+		return ok
+	case *TypingSetT:
+		_, ok := b.(*TypingSetT)
+		return ok
+	case *TypingIterT:
+		_, ok := b.(*TypingIterT)
+		return ok
 	case *TypingAnyT:
 		_, ok := b.(*TypingAnyT)
 		return ok
@@ -267,6 +279,38 @@ func (t *TypingErrorT) IsAssignableFrom(other TypingT) bool {
 	return ok
 }
 
+func (t *TypingDatetimeT) IsAssignableFrom(other TypingT) bool {
+	if isAnyLike(other) {
+		return true
+	}
+	_, ok := other.(*TypingDatetimeT)
+	return ok
+}
+
+func (t *TypingDurationT) IsAssignableFrom(other TypingT) bool {
+	if isAnyLike(other) {
+		return true
+	}
+	_, ok := other.(*TypingDurationT)
+	return ok
+}
+
+func (t *TypingSetT) IsAssignableFrom(other TypingT) bool {
+	if isAnyLike(other) {
+		return true
+	}
+	_, ok := other.(*TypingSetT)
+	return ok
+}
+
+func (t *TypingIterT) IsAssignableFrom(other TypingT) bool {
+	if isAnyLike(other) {
+		return true
+	}
+	_, ok := other.(*TypingIterT)
+	return ok
+}
+
 // Null is its own type and not assignable from anything except itself
 // (or an any-like wildcard). Slots that admit null - Optional<T>, unions
 // containing null - accept it through their own IsAssignableFrom; this
@@ -1,6 +1,9 @@
 package rl
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // first check Val, then Type
 type TypingCompatVal struct {
@@ -29,10 +32,16 @@ func NewSubject(val interface{}) TypingCompatVal {
 		s := NewMapSubject()
 		s.Val = coerced
 		return s
+	case time.Time:
+		return NewDatetimeSubject()
+	case time.Duration:
+		return NewDurationSubject()
 	case FnGoValue:
 		// Function values inside collections come through as this sentinel so
 		// the rl package doesn't have to import core.RadFn.
 		return NewFnSubject()
+	case IterGoValue:
+		return NewIterSubject()
 	default:
 		panic(fmt.Sprintf("Unhandled type for TypingCompatVal: %T", coerced))
 	}
@@ -44,6 +53,9 @@ func NewSubject(val interface{}) TypingCompatVal {
 // package cannot import core where RadFn is defined.
 type FnGoValue struct{}
 
+// IterGoValue is the same kind of sentinel, for an iterator.
+type IterGoValue struct{}
+
 func NewIntSubject(val int64) TypingCompatVal {
 	t := RadIntT
 	return TypingCompatVal{
@@ -111,6 +123,34 @@ func NewErrorSubject() TypingCompatVal {
 	}
 }
 
+func NewDatetimeSubject() TypingCompatVal {
+	t := RadDatetimeT
+	return TypingCompatVal{
+		Type: &t,
+	}
+}
+
+func NewDurationSubject() TypingCompatVal {
+	t := RadDurationT
+	return TypingCompatVal{
+		Type: &t,
+	}
+}
+
+func NewSetSubject() TypingCompatVal {
+	t := RadSetT
+	return TypingCompatVal{
+		Type: &t,
+	}
+}
+
+func NewIterSubject() TypingCompatVal {
+	t := RadIterT
+	return TypingCompatVal{
+		Type: &t,
+	}
+}
+
 func NewVoidSubject() TypingCompatVal {
 	return TypingCompatVal{}
 }
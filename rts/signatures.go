@@ -76,6 +76,8 @@ var internalSignatures = []FnSignature{
 	newInternalFnSignature(`_rad_docs_full() -> str`),
 	newInternalFnSignature(`_rad_docs_url(_topic: str) -> str?`),
 	newInternalFnSignature(`_rad_render(_md: str, _mode: str) -> str`),
+	newInternalFnSignature(`_rad_gen_ref(_script: str, _format: str, _name: str?) -> str`),
+	newInternalFnSignature(`_rad_run_tests(_paths: str[], _run: str?) -> map[]`),
 }
 
 func init() {
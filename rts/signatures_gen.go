@@ -11,7 +11,13 @@ package rts
 // FnSignaturesByName.
 var publicSignatures = []string{
 	`abs(_num: int|float) -> int|float`,
+	`assert(_cond: any, _msg: str?) -> void`,
+	`assert_eq(_actual: any, _expected: any, _msg: str?) -> void`,
+	`assert_ne(_actual: any, _expected: any, _msg: str?) -> void`,
 	`base_name(_path: str) -> str`,
+	`between(_min: int|float, _max: int|float) -> any`,
+	`bind(_name: str, _pattern: any?) -> any`,
+	`breakpoint() -> void`,
 	`black(_item: any) -> str`,
 	`blue(_item: any) -> str`,
 	`bold(_item: any) -> str`,
@@ -23,18 +29,23 @@ var publicSignatures = []string{
 	`convert_duration(_value: int|float, _unit: ["nanos", "micros", "millis", "seconds", "minutes", "hours", "days"]) -> error|{ "nanos": int, "micros": float, "millis": float, "seconds": float, "minutes": float, "hours": float, "days": float }`,
 	`count(_str: str, _substr: str) -> int`,
 	`cyan(_item: any) -> str`,
+	`datetime(_val: (str|int|float|map)?, *, format: str?, tz: str = "local", unit: ["auto", "seconds", "millis", "micros", "nanos"] = "auto") -> error|any`,
 	`debug(*_items: any, *, sep: str = " ", end: str = "\n") -> void`,
 	`decode_base16(_content: str) -> error|str`,
 	`decode_base64(_content: str, *, url_safe: bool = false, padding: bool = true) -> error|str`,
 	`delete_path(_path: str) -> bool`,
 	`dim(_item: any) -> str`,
 	`dir_name(_path: str) -> str`,
+	`duration(_val: str|int|float|map, *, unit: ["nanos", "micros", "millis", "seconds", "minutes", "hours", "days"] = "seconds") -> error|any`,
+	`emit(_val: any?) -> void`,
 	`encode_base16(_content: str) -> str`,
 	`encode_base64(_content: str, *, url_safe: bool = false, padding: bool = true) -> str`,
 	`ends_with(_val: str, _end: str) -> bool`,
 	`error(_msg: str) -> error`,
 	`exit(_code: int|bool = 0) -> void`,
-	`filter(_coll: map|list, _fn: fn(any) -> bool | fn(any, any) -> bool) -> map|list`,
+	`filter(_coll: any, _fn: fn(any) -> bool | fn(any, any) -> bool) -> any`,
+	`find(_str: str, _pattern: str) -> error|map?`,
+	`find_all(_str: str, _pattern: str) -> error|map[]`,
 	`find_paths(_path: str, *, depth: int = -1, relative: ["target", "cwd", "absolute"] = "target") -> error|str[]`,
 	`flat_map(_coll: map|list, _fn: any?) -> list`,
 	`float(_var: any) -> float|error`,
@@ -61,34 +72,46 @@ var publicSignatures = []string{
 	`http_put(url: str, *, body: any?, json: any?, headers: map?, insecure: bool = false) -> { "success": bool, "status_code"?: int, "headers": map, "body"?: any, "error"?: str, "duration_seconds": float }`,
 	`http_trace(url: str, *, body: any?, json: any?, headers: map?, insecure: bool = false) -> { "success": bool, "status_code"?: int, "headers": map, "body"?: any, "error"?: str, "duration_seconds": float }`,
 	`hyperlink(_val: any, _link: str) -> str`,
+	`import(_path: str, *, with_args: map?) -> error|map`,
+	`in_tz(_dt: any, _tz: str) -> error|any`,
 	`index_of(_subject: str|list, _target: any, *, n: int = 0, start: int = 0) -> int?`,
 	`input(prompt: str = "> ", *, hint: str = "", default: str = "", secret: bool = false) -> error|str`,
 	`int(_var: any) -> int|error`,
+	`intersect(_first: any, *_others: any) -> any`,
 	`is_defined(_var: str) -> bool`,
 	`italic(_item: any) -> str`,
 	`join(_list: list, sep: str = "", prefix: str = "", suffix: str = "") -> str`,
 	`join_paths(*_parts: str) -> str`,
 	`keys(_map: map) -> any[]`,
-	`len(_val: str|list|map) -> int`,
+	`len(_val: any) -> int`,
 	`load(_map: map, _key: any, _loader: fn() -> any, *, reload: bool = false, override: any?) -> error|any`,
 	`load_stash_file(_path: str, _default: str = "") -> error|{ "full_path": str, "created": bool, "content"?: str }`,
 	`load_state() -> error|map`,
 	`lower(_val: str) -> str`,
 	`magenta(_item: any) -> str`,
-	`map(_coll: map|list, _fn: fn(any) -> any | fn(any, any) -> any) -> map|list`,
+	`map(_coll: any, _fn: fn(any) -> any | fn(any, any) -> any) -> any`,
 	`matches(_str: str, _pattern: str, *, partial: bool = false) -> bool|error`,
 	`max(*_nums: float|float[]) -> int|float|error`,
 	`min(*_nums: float|float[]) -> int|float|error`,
 	`mkdir(_path: str) -> error|{ "path": str, "created": bool }`,
+	`mock_calls() -> str[]`,
+	`mock_http(_url: str, _body: any = "", *, method: str?, status: int = 200, headers: map?) -> void`,
+	`mock_now(_time: any) -> void`,
+	`mock_shell(_pattern: str, _stdout: str = "", *, stderr: str = "", code: int = 0) -> void`,
 	`multipick(_options: str[], *, prompt: str?, min: int = 0, max: int?) -> error|str[]`,
 	`now(*, tz: str = "local") -> error|{ "date": str, "year": int, "month": int, "day": int, "weekday": int, "hour": int, "minute": int, "second": int, "time": str, "epoch": { "seconds": int, "millis": int, "nanos": int } }`,
+	`of_type(_type: str) -> any`,
 	`orange(_item: any) -> str`,
+	`parse_csv(_str: str, *, header: bool = true, delimiter: str = ",", lazy_quotes: bool = false, comment: str?) -> error|list`,
 	`parse_date(_date: str, *, format: str?, tz: str = "local") -> error|{ "date": str, "year": int, "month": int, "day": int, "weekday": int, "hour": int, "minute": int, "second": int, "time": str, "epoch": { "seconds": int, "millis": int, "nanos": int } }`,
 	`parse_duration(_duration: str) -> error|{ "nanos": int, "micros": float, "millis": float, "seconds": float, "minutes": float, "hours": float, "days": float }`,
 	`parse_epoch(_epoch: int|float, *, tz: str = "local", unit: ["auto", "seconds", "millis", "micros", "nanos", "milliseconds", "microseconds", "nanoseconds"] = "auto") -> error|{ "date": str, "year": int, "month": int, "day": int, "weekday": int, "hour": int, "minute": int, "second": int, "time": str, "epoch": { "seconds": int, "millis": int, "nanos": int } }`,
 	`parse_float(_str: str) -> float|error`,
 	`parse_int(_str: str) -> int|error`,
-	`parse_json(_str: str) -> any|error`,
+	`parse_json(_str: str, *, into: any?) -> any|error`,
+	`parse_ndjson(_str: str, *, into: any?) -> error|list`,
+	`parse_toml(_str: str, *, into: any?) -> map|error`,
+	`parse_yaml(_str: str, *, all: bool = false, into: any?) -> any|error`,
 	`pick(_options: str[], _filter: (str|str[])?, *, prompt: str = "Pick an option", prefer_exact: bool = false) -> error|str`,
 	`pick_from_resource(path: str, _filter: str?, *, prompt: str = "Pick an option", prefer_exact: bool = true) -> any`,
 	`pick_kv(keys: str[], values: any[], _filter: (str|str[])?, *, prompt: str = "Pick an option", prefer_exact: bool = false) -> any`,
@@ -102,37 +125,52 @@ var publicSignatures = []string{
 	`rand_int(_arg1: int = 9223372036854775807, _arg2: int?) -> int`,
 	`range(_arg1: float|int, _arg2: (float|int)?, _step: float|int = 1) -> float[]|int[]`,
 	`read_file(_path: str, *, mode: ["text", "bytes"] = "text") -> error|{ "size_bytes": int, "content": str|int[] }`,
+	`read_lines(_path: str?) -> error|any`,
 	`read_stdin() -> str?|error`,
+	`record(_name: str, _fields: { str: str }) -> any`,
 	`red(_item: any) -> str`,
-	`replace(_original: str, _find: str, _replace: str, *, regex: bool = false) -> str`,
+	`regex(_pattern: str, *, partial: bool = false) -> any`,
+	`render(_template: str, _data: map?) -> error|str`,
+	`render_dir(_src: str, _dest: str, _data: map?, *, overwrite: bool = false) -> error|str[]`,
+	`render_file(_path: str, _data: map?) -> error|str`,
+	`replace(_original: str, _find: str, _replace: str|fn(map) -> any, *, regex: bool = false) -> str`,
+	`rest(_name: str?) -> any`,
 	`reverse(_val: str|list) -> str|list`,
 	`round(_num: float, _decimals: int = 0) -> error|int|float`,
 	`save_state(_state: map) -> error?`,
 	`seed_random(_seed: int) -> void`,
+	`set(_items: list?) -> any`,
 	`signal_ignore(_signal: ["sigint", "sigterm", "sighup", "sigusr1", "sigusr2", "sigpipe", "sigwinch"] | ["sigint", "sigterm", "sighup", "sigusr1", "sigusr2", "sigpipe", "sigwinch"][]) -> void`,
 	`signal_trap(_signal: ["sigint", "sigterm", "sighup", "sigusr1", "sigusr2", "sigpipe", "sigwinch"] | ["sigint", "sigterm", "sighup", "sigusr1", "sigusr2", "sigpipe", "sigwinch"][], _handler: fn(any) -> any) -> void`,
 	`sleep(_duration: int|float|str, *, title: str?) -> void`,
 	`sort(_primary: list|str, *_others: list|str, *, reverse: bool = false) -> list|str`,
 	`split(_val: str, _sep: str, *, limit: int?, regex: bool = false) -> str[]`,
 	`split_lines(_val: str) -> str[]`,
+	`start_of(_dt: any, _unit: ["year", "month", "day", "hour", "minute", "second"]) -> any`,
 	`starts_with(_val: str, _start: str) -> bool`,
 	`str(_var: any) -> str`,
 	`strikethrough(_item: any) -> str`,
 	`sum(_nums: float[]) -> error|int|float`,
+	`take(_items: any, _n: int) -> list`,
+	`to_csv(_rows: list, *, header: bool = true, delimiter: str = ",", columns: str[]?) -> error|str`,
 	`to_json(_val: any, *, indent: int = 0) -> str`,
+	`to_ndjson(_vals: list) -> str`,
+	`to_toml(_val: map) -> error|str`,
+	`to_yaml(_val: any, *, indent: int = 2, all: bool = false) -> error|str`,
 	`trim(_subject: str, _chars: str = " \t\n") -> str`,
 	`trim_left(_subject: str, _chars: str = " \t\n") -> str`,
 	`trim_prefix(_subject: str, _prefix: str) -> str`,
 	`trim_right(_subject: str, _chars: str = " \t\n") -> str`,
 	`trim_suffix(_subject: str, _suffix: str) -> str`,
 	`truncate(_str: str, _len: int) -> error|str`,
-	`type_of(_var: any) -> ["int", "str", "list", "map", "float", "bool", "null", "error", "function"]`,
+	`type_of(_var: any) -> ["int", "str", "list", "map", "float", "bool", "null", "error", "function", "datetime", "duration", "set", "iterator"]`,
 	`underline(_item: any) -> str`,
 	`unique(_list: any[]) -> any[]`,
 	`upper(_val: str) -> str`,
 	`uuid_v4() -> str`,
 	`uuid_v7() -> str`,
 	`values(_map: map) -> any[]`,
+	`where(_pattern: any, _guard: fn() -> bool) -> any`,
 	`white(_item: any) -> str`,
 	`write_file(_path: str, _content: str, *, append: bool = false) -> error|{ "bytes_written": int, "path": str }`,
 	`write_stash_file(_path: str, _content: str) -> error?`,
@@ -22,6 +22,11 @@ function findRadls(): string | null {
     }
 }
 
+function findRad(): string {
+    // RAD_PATH mirrors RAD_LSP_PATH, for debugging with a development build
+    return process.env.RAD_PATH || "rad";
+}
+
 function showInstallError(): void {
     vscode.window.showErrorMessage(
         `Rad Language Server (radls) not found. ${INSTALL_HINT}`,
@@ -34,6 +39,13 @@ function showInstallError(): void {
 }
 
 export function activate(context: vscode.ExtensionContext) {
+    // The debugger is rad itself, so it's available even without radls.
+    context.subscriptions.push(
+        vscode.debug.registerDebugAdapterDescriptorFactory("rad", {
+            createDebugAdapterDescriptor: () => new vscode.DebugAdapterExecutable(findRad(), ["debug", "--dap"])
+        })
+    );
+
     const radlsCommand = findRadls();
 
     if (!radlsCommand) {
@@ -47,7 +59,12 @@ export function activate(context: vscode.ExtensionContext) {
     };
 
     const clientOptions: LanguageClientOptions = {
-        documentSelector: [{ scheme: "file", language: "rad" }]
+        documentSelector: [{ scheme: "file", language: "rad" }],
+        // Scripts are often extensionless (a rad shebang), so watch every
+        // file and let radls pick out the scripts.
+        synchronize: {
+            fileEvents: vscode.workspace.createFileSystemWatcher("**/*")
+        }
     };
 
     const client = new LanguageClient("radls", "Rad Language Server", serverOptions, clientOptions);